@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -60,10 +61,11 @@ type RuntimeTypes struct {
 }
 
 type rawTypeInfo struct {
-	name      string
-	kind      string
-	fields    []FieldDef
-	aliasType string
+	name       string
+	kind       string
+	fields     []FieldDef
+	aliasType  string
+	enumValues []string // TypeScript literals of typed constants, e.g. "\"auto\""
 }
 
 type extensionRef struct {
@@ -108,6 +110,7 @@ func parseExtensionDirs(dirs []string) (RuntimeTypes, error) {
 	methodsByType := make(map[string][]MethodDef)
 	namespaceByServiceBase := make(map[string]string)
 	knownTypes := make(map[string]rawTypeInfo)
+	enumValues := make(map[string][]string)
 	var registeredExtensions []extensionRef
 
 	for _, dir := range dirs {
@@ -136,6 +139,13 @@ func parseExtensionDirs(dirs []string) (RuntimeTypes, error) {
 						if !ok {
 							continue
 						}
+						if typeIdent, ok := valueSpec.Type.(*ast.Ident); ok {
+							for _, value := range valueSpec.Values {
+								if literal := enumLiteral(value); literal != "" {
+									enumValues[typeIdent.Name] = append(enumValues[typeIdent.Name], literal)
+								}
+							}
+						}
 						for i, name := range valueSpec.Names {
 							if !strings.HasSuffix(name.Name, "Namespace") || i >= len(valueSpec.Values) {
 								continue
@@ -228,6 +238,24 @@ func parseExtensionDirs(dirs []string) (RuntimeTypes, error) {
 		}
 	}
 
+	// Named types with typed constants (e.g. type Mode string) become unions of
+	// their constant values
+	for name, values := range enumValues {
+		if typeInfo, ok := knownTypes[name]; ok && typeInfo.kind == "alias" {
+			typeInfo.enumValues = values
+			knownTypes[name] = typeInfo
+		}
+	}
+
+	// Methods are extracted while walking, so types declared after a method (or
+	// in a later file) are only known now
+	for typeName, methods := range methodsByType {
+		for i := range methods {
+			resolveMethodTypes(&methods[i], knownTypes)
+		}
+		methodsByType[typeName] = methods
+	}
+
 	runtimeTypes := RuntimeTypes{
 		Extensions: make(map[string]map[string]RuntimeExtensionDef),
 		Structs:    make(map[string]StructDef),
@@ -583,6 +611,36 @@ func extractMethod(funcDecl *ast.FuncDecl, knownTypes map[string]rawTypeInfo) Me
 	}
 }
 
+// resolveMethodTypes recomputes a method's TypeScript types from its Go types
+func resolveMethodTypes(method *MethodDef, knownTypes map[string]rawTypeInfo) {
+	for i := range method.Params {
+		method.Params[i].TSType = goTypeToTS(method.Params[i].GoType, knownTypes, true)
+	}
+	for i := range method.ReturnTypes {
+		method.ReturnTypes[i].TSType = goTypeToTS(method.ReturnTypes[i].GoType, knownTypes, true)
+	}
+}
+
+// enumLiteral returns the TypeScript literal for a string or integer constant
+// value, or "" if the value is not a basic literal.
+func enumLiteral(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok {
+		return ""
+	}
+	switch lit.Kind {
+	case token.STRING:
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return ""
+		}
+		return strconv.Quote(value)
+	case token.INT:
+		return lit.Value
+	}
+	return ""
+}
+
 func outputJSON(runtimeTypes RuntimeTypes) {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
@@ -772,7 +830,7 @@ func goTypeToTS(goType string, knownTypes map[string]rawTypeInfo, qualifyKnownTy
 		name := parts[len(parts)-1]
 		if typeInfo, ok := knownTypes[name]; ok {
 			if typeInfo.kind == "alias" {
-				return goTypeToTS(typeInfo.aliasType, knownTypes, qualifyKnownTypes)
+				return aliasToTS(typeInfo, knownTypes, qualifyKnownTypes)
 			}
 			if qualifyKnownTypes {
 				return "StruxRuntime." + name
@@ -784,7 +842,7 @@ func goTypeToTS(goType string, knownTypes map[string]rawTypeInfo, qualifyKnownTy
 
 	if typeInfo, ok := knownTypes[goType]; ok {
 		if typeInfo.kind == "alias" {
-			return goTypeToTS(typeInfo.aliasType, knownTypes, qualifyKnownTypes)
+			return aliasToTS(typeInfo, knownTypes, qualifyKnownTypes)
 		}
 		if qualifyKnownTypes {
 			return "StruxRuntime." + goType
@@ -795,6 +853,15 @@ func goTypeToTS(goType string, knownTypes map[string]rawTypeInfo, qualifyKnownTy
 	return "unknown"
 }
 
+// aliasToTS resolves a named non-struct type: a union of its constant values
+// if it has any, otherwise its underlying type.
+func aliasToTS(typeInfo rawTypeInfo, knownTypes map[string]rawTypeInfo, qualifyKnownTypes bool) string {
+	if len(typeInfo.enumValues) > 0 {
+		return strings.Join(typeInfo.enumValues, " | ")
+	}
+	return goTypeToTS(typeInfo.aliasType, knownTypes, qualifyKnownTypes)
+}
+
 func parseMapType(goType string) (string, string, bool) {
 	if !strings.HasPrefix(goType, "map[") {
 		return "", "", false
@@ -201,3 +201,44 @@ func TestGenerateTypeScriptNamespacesRuntimeSupportTypes(t *testing.T) {
 		t.Fatal("did not expect DevConfig to be emitted as a top-level interface")
 	}
 }
+
+func TestParseExtensionsEmitsEnumUnions(t *testing.T) {
+	tempDir := t.TempDir()
+	source := `package extension
+
+type LedExtension struct{}
+
+func (l *LedExtension) Namespace() string    { return "strux" }
+func (l *LedExtension) SubNamespace() string { return "led" }
+
+type LedMethods struct{}
+
+func (l *LedMethods) SetMode(mode Mode) (Mode, error) { return mode, nil }
+
+type Mode string
+
+const (
+	ModeOff   Mode = "off"
+	ModeBlink Mode = "blink"
+)
+`
+
+	path := filepath.Join(tempDir, "led.go")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	runtimeTypes, err := parseExtensions(tempDir)
+	if err != nil {
+		t.Fatalf("parseExtensions failed: %v", err)
+	}
+
+	method := runtimeTypes.Extensions["strux"]["led"].Methods[0]
+	want := `"off" | "blink"`
+	if got := method.Params[0].TSType; got != want {
+		t.Fatalf("expected param type %s, got %q", want, got)
+	}
+	if got := method.ReturnTypes[0].TSType; got != want {
+		t.Fatalf("expected return type %s, got %q", want, got)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// runDiff loads two introspection outputs and prints what changed between them.
+func runDiff(oldPath string, newPath string, w io.Writer) error {
+	oldOutput, err := readIntrospectionOutput(oldPath)
+	if err != nil {
+		return err
+	}
+	newOutput, err := readIntrospectionOutput(newPath)
+	if err != nil {
+		return err
+	}
+
+	changes := diffIntrospection(oldOutput, newOutput)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Fprintln(w, change)
+	}
+	return nil
+}
+
+func readIntrospectionOutput(path string) (IntrospectionOutput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return IntrospectionOutput{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var output IntrospectionOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return IntrospectionOutput{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return output, nil
+}
+
+// diffIntrospection lists added (+), removed (-) and changed (~) methods,
+// fields and structs. Lines are grouped by scope and sorted within each scope.
+func diffIntrospection(oldOutput IntrospectionOutput, newOutput IntrospectionOutput) []string {
+	var changes []string
+
+	appScope := newOutput.App.Name
+	if oldOutput.App.Name != newOutput.App.Name {
+		changes = append(changes, fmt.Sprintf("~ app %s -> %s", oldOutput.App.Name, newOutput.App.Name))
+	}
+	changes = append(changes, diffFields(appScope, oldOutput.App.Fields, newOutput.App.Fields)...)
+	changes = append(changes, diffMethods(appScope, oldOutput.App.Methods, newOutput.App.Methods)...)
+
+	names := make(map[string]bool)
+	for name := range oldOutput.Structs {
+		names[name] = true
+	}
+	for name := range newOutput.Structs {
+		names[name] = true
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	for _, name := range sortedNames {
+		oldStruct, inOld := oldOutput.Structs[name]
+		newStruct, inNew := newOutput.Structs[name]
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("+ struct %s", name))
+		case !inNew:
+			changes = append(changes, fmt.Sprintf("- struct %s", name))
+		default:
+			changes = append(changes, diffFields(name, oldStruct.Fields, newStruct.Fields)...)
+			changes = append(changes, diffMethods(name, oldStruct.Methods, newStruct.Methods)...)
+		}
+	}
+
+	return changes
+}
+
+func diffFields(scope string, oldFields []FieldDef, newFields []FieldDef) []string {
+	oldByName := make(map[string]FieldDef, len(oldFields))
+	for _, field := range oldFields {
+		oldByName[field.Name] = field
+	}
+	newByName := make(map[string]FieldDef, len(newFields))
+	for _, field := range newFields {
+		newByName[field.Name] = field
+	}
+
+	var changes []string
+	for name, oldField := range oldByName {
+		newField, ok := newByName[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("- field %s.%s %s", scope, name, oldField.GoType))
+			continue
+		}
+		if oldField.GoType != newField.GoType {
+			changes = append(changes, fmt.Sprintf("~ field %s.%s %s -> %s", scope, name, oldField.GoType, newField.GoType))
+		}
+	}
+	for name, newField := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			changes = append(changes, fmt.Sprintf("+ field %s.%s %s", scope, name, newField.GoType))
+		}
+	}
+	sortChanges(changes)
+	return changes
+}
+
+func diffMethods(scope string, oldMethods []MethodDef, newMethods []MethodDef) []string {
+	oldByName := make(map[string]MethodDef, len(oldMethods))
+	for _, method := range oldMethods {
+		oldByName[method.Name] = method
+	}
+	newByName := make(map[string]MethodDef, len(newMethods))
+	for _, method := range newMethods {
+		newByName[method.Name] = method
+	}
+
+	var changes []string
+	for name, oldMethod := range oldByName {
+		newMethod, ok := newByName[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("- method %s.%s", scope, methodSignature(oldMethod)))
+			continue
+		}
+		if oldSig, newSig := methodSignature(oldMethod), methodSignature(newMethod); oldSig != newSig {
+			changes = append(changes, fmt.Sprintf("~ method %s.%s -> %s", scope, oldSig, newSig))
+		}
+	}
+	for name, newMethod := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			changes = append(changes, fmt.Sprintf("+ method %s.%s", scope, methodSignature(newMethod)))
+		}
+	}
+	sortChanges(changes)
+	return changes
+}
+
+// methodSignature formats a method as Go source, e.g. "Greet(name string) (string, error)"
+func methodSignature(method MethodDef) string {
+	params := make([]string, 0, len(method.Params))
+	for _, param := range method.Params {
+		p := param.GoType
+		if param.Name != "" {
+			p = param.Name + " " + p
+		}
+		if param.Optional {
+			p += " (optional)"
+		}
+		params = append(params, p)
+	}
+
+	results := make([]string, 0, len(method.ReturnTypes)+1)
+	for _, returnType := range method.ReturnTypes {
+		results = append(results, returnType.GoType)
+	}
+	if method.HasError {
+		results = append(results, "error")
+	}
+
+	signature := fmt.Sprintf("%s(%s)", method.Name, strings.Join(params, ", "))
+	switch len(results) {
+	case 0:
+		return signature
+	case 1:
+		return signature + " " + results[0]
+	default:
+		return signature + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// sortChanges orders changes by the member they describe, ignoring the +/-/~ marker
+func sortChanges(changes []string) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i][2:] < changes[j][2:]
+	})
+}
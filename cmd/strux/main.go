@@ -6,19 +6,30 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 )
 
 // IntrospectionOutput is the top-level JSON structure
 type IntrospectionOutput struct {
-	App        AppInfo              `json:"app"`
-	Structs    map[string]StructDef `json:"structs"`
-	Extensions map[string]any       `json:"extensions,omitempty"`
+	App        AppInfo                 `json:"app"`
+	Structs    map[string]StructDef    `json:"structs"`
+	Interfaces map[string]InterfaceDef `json:"interfaces,omitempty"`
+	Extensions map[string]any          `json:"extensions,omitempty"`
+	Events     []EventDef              `json:"events,omitempty"`
+	Constants  map[string]ConstantDef  `json:"constants,omitempty"`
+
+	// typePackages maps each named type to the package declaring it, for
+	// --qualify. Structs resolved from other packages keep their bare names
+	// in Structs, so this can't be recovered from the output itself.
+	typePackages map[string]string
 }
 
 // AppInfo describes the main application struct
@@ -27,6 +38,25 @@ type AppInfo struct {
 	PackageName string      `json:"packageName"`
 	Fields      []FieldDef  `json:"fields"`
 	Methods     []MethodDef `json:"methods"`
+	// Version and BuildMeta come from --app-version and --build-meta, for
+	// values set at link time that the parser can't see
+	Version   string            `json:"version,omitempty"`
+	BuildMeta map[string]string `json:"buildMeta,omitempty"`
+}
+
+// EventDef describes an event the app registers with rt.RegisterEvent
+type EventDef struct {
+	Name   string `json:"name"`
+	GoType string `json:"goType,omitempty"` // Empty for events without a payload
+	TSType string `json:"tsType"`
+}
+
+// ConstantDef describes an exported package-level constant with a literal
+// value, which the app can serve to the frontend with rt.RegisterConstants
+type ConstantDef struct {
+	GoType string      `json:"goType"`
+	TSType string      `json:"tsType"`
+	Value  interface{} `json:"value"`
 }
 
 // StructDef describes a struct definition
@@ -35,11 +65,22 @@ type StructDef struct {
 	Methods []MethodDef `json:"methods,omitempty"`
 }
 
+// InterfaceDef describes a named interface declared in the app package. Fields of
+// interface type stay "any" in TypeScript; this lists what the Go side provides.
+type InterfaceDef struct {
+	Embeds  []string    `json:"embeds,omitempty"` // Embedded interfaces, e.g. "io.Reader"
+	Methods []MethodDef `json:"methods"`
+}
+
 // FieldDef describes a struct field
 type FieldDef struct {
-	Name   string `json:"name"`
-	GoType string `json:"goType"`
-	TSType string `json:"tsType"`
+	Name     string `json:"name"`
+	JSONName string `json:"jsonName,omitempty"` // Key the field is marshaled under, from its json tag or Name
+	Optional bool   `json:"optional,omitempty"` // Set by a json omitempty option
+	GoType   string `json:"goType"`
+	TSType   string `json:"tsType"`
+	Group    string `json:"group,omitempty"`   // From a `strux:"group=..."` tag
+	Default  string `json:"default,omitempty"` // From a `strux:"default=..."` tag, applied when decoding params
 }
 
 // MethodDef describes a method
@@ -48,17 +89,26 @@ type MethodDef struct {
 	Params      []ParamDef `json:"params"`
 	ReturnTypes []TypeDef  `json:"returnTypes"`
 	HasError    bool       `json:"hasError"`
+	// FireAndForget is set for methods listed in the app's FireAndForget(); they resolve without a result
+	FireAndForget bool `json:"fireAndForget,omitempty"`
+	// NamedResults is set for methods listed in the app's ReturnNames(); they resolve to an
+	// object keyed by the return type names
+	NamedResults bool `json:"namedResults,omitempty"`
+	// injected lists the leading params the runtime supplies, which are left out of Params
+	injected []string
 }
 
 // ParamDef describes a method parameter
 type ParamDef struct {
-	Name   string `json:"name,omitempty"`
-	GoType string `json:"goType"`
-	TSType string `json:"tsType"`
+	Name     string `json:"name,omitempty"`
+	GoType   string `json:"goType"`
+	TSType   string `json:"tsType"`
+	Optional bool   `json:"optional,omitempty"` // Trailing param declared optional via OptionalParams()
 }
 
 // TypeDef describes a type
 type TypeDef struct {
+	Name   string `json:"name,omitempty"` // Result name, from the signature or the app's ReturnNames()
 	GoType string `json:"goType"`
 	TSType string `json:"tsType"`
 }
@@ -80,20 +130,58 @@ type runtimeExtensionRef struct {
 	methodsTypeName string
 }
 
+// Output formats selected with --emit
+const (
+	emitJSON = "json"
+	emitDTS  = "dts"
+)
+
+// defaultDTSFilename is where --emit=dts writes, next to the input
+const defaultDTSFilename = "strux.d.ts"
+
 type introspectOptions struct {
 	filePath        string
+	appStruct       string // Explicit app struct name, overriding runtime.Start() detection
 	runtimeDTS      bool
 	runtimeDTSDirs  string
 	runtimeJSONPath string
+	summary         bool              // Print counts and warnings to stderr instead of the JSON output
+	appVersion      string            // Recorded in the App section, e.g. the version set via ldflags
+	buildMeta       map[string]string // Recorded in the App section, from repeated --build-meta key=value
+	emit            string            // "json" (the default) or "dts"
+	outPath         string            // Output file; stdout for JSON when empty, strux.d.ts next to the input for dts
+	qualify         bool              // Qualify every named type in GoType with its package, e.g. main.Settings
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if len(os.Args) != 4 {
+			fmt.Fprintf(os.Stderr, "Usage: strux-introspect diff <old.json> <new.json>\n")
+			os.Exit(1)
+		}
+		if err := runDiff(os.Args[2], os.Args[3], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	opts, err := parseArgs(os.Args[1:])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	if opts.emit == emitDTS {
+		path, err := emitDTSFile(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+		return
+	}
+
 	if opts.runtimeDTS {
 		output, err := generateDTS(opts)
 		if err != nil {
@@ -104,16 +192,35 @@ func main() {
 		return
 	}
 
-	if err := introspect(opts.filePath); err != nil {
+	if opts.summary {
+		warnings, err := runSummary(opts.filePath, opts.appStruct, os.Stderr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if warnings > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := introspect(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
 func parseArgs(args []string) (introspectOptions, error) {
-	opts := introspectOptions{filePath: "main.go"}
+	opts := introspectOptions{filePath: "main.go", emit: emitJSON}
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
+		if emit, ok := strings.CutPrefix(arg, "--emit="); ok {
+			if emit != emitJSON && emit != emitDTS {
+				return opts, fmt.Errorf("--emit must be %s or %s, got %q", emitJSON, emitDTS, emit)
+			}
+			opts.emit = emit
+			continue
+		}
 		switch arg {
 		case "--runtime-dts":
 			opts.runtimeDTS = true
@@ -121,12 +228,47 @@ func parseArgs(args []string) (introspectOptions, error) {
 				i++
 				opts.runtimeDTSDirs = args[i]
 			}
+		case "--app-struct":
+			i++
+			if i >= len(args) {
+				return opts, fmt.Errorf("--app-struct requires a struct name")
+			}
+			opts.appStruct = args[i]
+		case "--summary":
+			opts.summary = true
+		case "--qualify":
+			opts.qualify = true
 		case "--runtime-json":
 			i++
 			if i >= len(args) {
 				return opts, fmt.Errorf("--runtime-json requires a file path")
 			}
 			opts.runtimeJSONPath = args[i]
+		case "--out":
+			i++
+			if i >= len(args) {
+				return opts, fmt.Errorf("--out requires a file path")
+			}
+			opts.outPath = args[i]
+		case "--app-version":
+			i++
+			if i >= len(args) {
+				return opts, fmt.Errorf("--app-version requires a version")
+			}
+			opts.appVersion = args[i]
+		case "--build-meta":
+			i++
+			if i >= len(args) {
+				return opts, fmt.Errorf("--build-meta requires a key=value pair")
+			}
+			key, value, ok := strings.Cut(args[i], "=")
+			if !ok || key == "" {
+				return opts, fmt.Errorf("--build-meta expects key=value, got %q", args[i])
+			}
+			if opts.buildMeta == nil {
+				opts.buildMeta = make(map[string]string)
+			}
+			opts.buildMeta[key] = value
 		default:
 			if strings.HasPrefix(arg, "--") {
 				return opts, fmt.Errorf("unknown option %s", arg)
@@ -137,26 +279,93 @@ func parseArgs(args []string) (introspectOptions, error) {
 	return opts, nil
 }
 
-func introspect(filePath string) error {
-	output, err := introspectData(filePath)
+func introspect(opts introspectOptions) error {
+	output, err := introspectApp(opts.filePath, opts.appStruct)
 	if err != nil {
 		return err
 	}
-	encoder := json.NewEncoder(os.Stdout)
+	applyBuildInfo(&output, opts)
+	if opts.qualify {
+		qualifyGoTypes(&output)
+	}
+
+	out := os.Stdout
+	if opts.outPath != "" {
+		file, err := os.Create(opts.outPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", opts.outPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	encoder := json.NewEncoder(out)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(output)
 }
 
+// emitDTSFile writes the TypeScript definitions to opts.outPath, defaulting to
+// strux.d.ts next to the input, and returns the path written
+func emitDTSFile(opts introspectOptions) (string, error) {
+	dts, err := generateDTS(opts)
+	if err != nil {
+		return "", err
+	}
+
+	path := opts.outPath
+	if path == "" {
+		dir := filepath.Dir(opts.filePath)
+		if info, err := os.Stat(opts.filePath); err == nil && info.IsDir() {
+			dir = opts.filePath
+		}
+		path = filepath.Join(dir, defaultDTSFilename)
+	}
+	if err := os.WriteFile(path, []byte(dts), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// isPackageSourceFile excludes test files, whose helpers are not part of the
+// app's API
+func isPackageSourceFile(info fs.FileInfo) bool {
+	return !strings.HasSuffix(info.Name(), "_test.go")
+}
+
+// applyBuildInfo records the version and build metadata passed on the
+// command line in the App section
+func applyBuildInfo(output *IntrospectionOutput, opts introspectOptions) {
+	output.App.Version = opts.appVersion
+	output.App.BuildMeta = opts.buildMeta
+}
+
 func introspectData(filePath string) (IntrospectionOutput, error) {
+	return introspectApp(filePath, "")
+}
+
+// introspectApp introspects the package containing filePath. appStruct names
+// the app struct explicitly; if empty it is detected (see detectAppStruct).
+func introspectApp(filePath string, appStruct string) (IntrospectionOutput, error) {
+	// "dir/..." names the package in dir, as with the go tool
+	if trimmed := strings.TrimSuffix(filePath, "..."); trimmed != filePath {
+		filePath = filepath.Clean(trimmed)
+	}
+
 	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
 		return IntrospectionOutput{}, fmt.Errorf("%s not found", filePath)
 	}
 
-	// Parse all Go files in the same directory to capture methods defined in other files
+	// Parse all Go files in the same directory to capture methods defined in other files.
+	// A directory is introspected as a whole, with no file singled out as main.
 	dir := filepath.Dir(filePath)
+	absFilePath, _ := filepath.Abs(filePath)
+	if err == nil && info.IsDir() {
+		dir = filePath
+		absFilePath = ""
+	}
 	fset := token.NewFileSet()
-	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	pkgs, err := parser.ParseDir(fset, dir, isPackageSourceFile, parser.ParseComments)
 	if err != nil {
 		return IntrospectionOutput{}, fmt.Errorf("failed to parse directory %s: %w", dir, err)
 	}
@@ -164,7 +373,6 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 	// Find the package that contains the specified file
 	var files []*ast.File
 	var packageName string
-	absFilePath, _ := filepath.Abs(filePath)
 
 	for pkgName, pkg := range pkgs {
 		for fpath, file := range pkg.Files {
@@ -184,14 +392,23 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 		}
 	}
 
-	// Fallback: if we couldn't match by path, use the first package
+	// Fallback: if we couldn't match by path (or were given a directory), use
+	// the main package, or else the first package by name
 	if packageName == "" {
-		for pkgName, pkg := range pkgs {
-			packageName = pkgName
+		pkgNames := make([]string, 0, len(pkgs))
+		for pkgName := range pkgs {
+			pkgNames = append(pkgNames, pkgName)
+		}
+		sort.Strings(pkgNames)
+		for _, pkgName := range pkgNames {
+			if pkgName == "main" || packageName == "" {
+				packageName = pkgName
+			}
+		}
+		if pkg, ok := pkgs[packageName]; ok {
 			for _, f := range pkg.Files {
 				files = append(files, f)
 			}
-			break
 		}
 	}
 
@@ -199,10 +416,16 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 		return IntrospectionOutput{}, fmt.Errorf("no Go files found in %s", dir)
 	}
 
+	// Walk files in name order so the output doesn't depend on map iteration
+	sort.Slice(files, func(i, j int) bool {
+		return fset.Position(files[i].Pos()).Filename < fset.Position(files[j].Pos()).Filename
+	})
+
 	// Collect all structs and their fields
 	structFields := make(map[string][]FieldDef)
 	knownStructs := make(map[string]bool)
 	typeAliases := make(map[string]string) // named type -> underlying type (e.g., "AudioOutput" -> "string")
+	localInterfaces := make(map[string]*ast.InterfaceType)
 
 	// First pass: discover all struct types and type aliases across all files
 	for _, file := range files {
@@ -211,6 +434,9 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 				if _, ok := typeSpec.Type.(*ast.StructType); ok {
 					knownStructs[typeSpec.Name.Name] = true
 				} else {
+					if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+						localInterfaces[typeSpec.Name.Name] = iface
+					}
 					// Track non-struct type aliases (e.g., type AudioOutput string)
 					underlying := exprToString(typeSpec.Type)
 					typeAliases[typeSpec.Name.Name] = underlying
@@ -221,16 +447,25 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 		})
 	}
 
-	// Determine the app struct by finding what's passed to runtime.Start()
-	appStructName := findRuntimeStartStruct(files)
+	typePackages := make(map[string]string)
+	for name := range knownStructs {
+		typePackages[name] = packageName
+	}
+	for name := range typeAliases {
+		typePackages[name] = packageName
+	}
 
-	// Default to "App" if runtime.Start() detection failed
+	appStructName := appStruct
 	if appStructName == "" {
-		appStructName = "App"
+		appStructName = detectAppStruct(files, fset, absFilePath, knownStructs)
 	}
 
 	// Second pass: extract struct fields and methods across all files
 	structMethods := make(map[string][]MethodDef)
+	structEmbeds := make(map[string][]embeddedStruct)
+	var optionalParamsDecl *ast.FuncDecl
+	var fireAndForgetDecl *ast.FuncDecl
+	var returnNamesDecl *ast.FuncDecl
 
 	for _, file := range files {
 		ast.Inspect(file, func(n ast.Node) bool {
@@ -242,16 +477,28 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 
 					// Extract fields
 					for _, field := range structType.Fields.List {
-						if len(field.Names) > 0 {
-							fieldName := field.Names[0].Name
-							// Only process exported fields
-							if isExported(fieldName) {
-								goType := exprToString(field.Type)
-								fields = append(fields, FieldDef{
-									Name:   fieldName,
-									GoType: goType,
-									TSType: goTypeToTS(goType, knownStructs),
-								})
+						if len(field.Names) == 0 {
+							// Embedded local structs are flattened in once all fields are known
+							if embedded := embeddedStructName(field.Type); knownStructs[embedded] {
+								structEmbeds[structName] = append(structEmbeds[structName], embeddedStruct{index: len(fields), typeName: embedded})
+							}
+							continue
+						}
+						fieldName := field.Names[0].Name
+						// Only process exported fields
+						if isExported(fieldName) {
+							goType := exprToString(field.Type)
+							def := FieldDef{
+								Name:    fieldName,
+								GoType:  goType,
+								TSType:  goTypeToTS(goType, knownStructs),
+								Group:   struxFieldGroup(field),
+								Default: struxTagOption(field, "default"),
+							}
+							// App fields are bound by their Go name, so json tags only
+							// apply to the structs that travel as values
+							if structName == appStructName || applyJSONTag(&def, field) {
+								fields = append(fields, def)
 							}
 						}
 					}
@@ -262,16 +509,17 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 			// Collect methods on all known structs
 			if funcDecl, ok := n.(*ast.FuncDecl); ok {
 				if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
-					recvType := funcDecl.Recv.List[0].Type
+					// Pointer and value receivers group under the same type name
+					recvTypeName := receiverTypeName(funcDecl)
 
-					var recvTypeName string
-					switch t := recvType.(type) {
-					case *ast.StarExpr:
-						if ident, ok := t.X.(*ast.Ident); ok {
-							recvTypeName = ident.Name
-						}
-					case *ast.Ident:
-						recvTypeName = t.Name
+					if recvTypeName == appStructName && funcDecl.Name.Name == "OptionalParams" {
+						optionalParamsDecl = funcDecl
+					}
+					if recvTypeName == appStructName && funcDecl.Name.Name == "FireAndForget" {
+						fireAndForgetDecl = funcDecl
+					}
+					if recvTypeName == appStructName && funcDecl.Name.Name == "ReturnNames" {
+						returnNamesDecl = funcDecl
 					}
 
 					if recvTypeName != "" && knownStructs[recvTypeName] {
@@ -288,6 +536,8 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 		})
 	}
 
+	flattenEmbeddedFields(structFields, structEmbeds)
+
 	// Extract app methods for convenience
 	methods := structMethods[appStructName]
 
@@ -347,6 +597,7 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 				structFields[name] = fields
 				knownStructs[name] = true
 				qualifiedToTS[pkgAlias+"."+name] = name
+				typePackages[name] = pkgAlias
 				newlyResolved = append(newlyResolved, fields...)
 			}
 			for name, methods := range extMethods {
@@ -400,6 +651,46 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 		}
 	}
 
+	// Methods returning io.Reader resolve with a stream handle, not the reader,
+	// and a sole struct parameter is a partial options object
+	for _, smethods := range structMethods {
+		markStreamReturns(smethods)
+		markOptionsParams(smethods, knownStructs)
+	}
+
+	// Drop runtime lifecycle hooks, which are not callable from the frontend
+	var appMethods []MethodDef
+	for _, m := range methods {
+		if !isAppLifecycleMethod(m) {
+			appMethods = append(appMethods, m)
+		}
+	}
+	methods = appMethods
+
+	// Mark trailing params the app declared optional, matching the runtime's zero-fill
+	applyOptionalParams(optionalParamsDecl, appStructName, methods, structFields, structMethods)
+	applyFireAndForget(fireAndForgetDecl, appStructName, methods, structFields, structMethods)
+	applyReturnNames(returnNamesDecl, appStructName, methods, structFields, structMethods)
+
+	// Field groups become an interface plus Get<Group>/Set<Group> accessors,
+	// mirroring the methods the runtime binds for them
+	groupStructs, groupMethods := buildFieldGroups(structFields[appStructName], methods, knownStructs)
+	methods = append(methods, groupMethods...)
+
+	// Events registered with rt.RegisterEvent type the frontend's ipc.on
+	events := findRegisteredEvents(files)
+	for i, event := range events {
+		switch event.GoType {
+		case "":
+			events[i].TSType = "any"
+		case "nil":
+			events[i].GoType = ""
+			events[i].TSType = "void"
+		default:
+			events[i].TSType = goTypeToTSWithQualified(event.GoType, knownStructs, qualifiedToTS)
+		}
+	}
+
 	// Build the output
 	output := IntrospectionOutput{
 		App: AppInfo{
@@ -410,6 +701,10 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 		},
 		Structs:    make(map[string]StructDef),
 		Extensions: make(map[string]any),
+		Events:     events,
+		Constants:  findConstants(files, knownStructs),
+
+		typePackages: typePackages,
 	}
 
 	// Add all structs except the app struct, including their methods
@@ -421,15 +716,189 @@ func introspectData(filePath string) (IntrospectionOutput, error) {
 			}
 		}
 	}
+	for name, structDef := range groupStructs {
+		output.Structs[name] = structDef
+	}
+
+	// Named interfaces keep their method set so the frontend can see what the
+	// value provides, even though it crosses IPC as plain JSON
+	for name, iface := range localInterfaces {
+		if def, ok := extractInterface(iface, knownStructs); ok {
+			if output.Interfaces == nil {
+				output.Interfaces = make(map[string]InterfaceDef)
+			}
+			output.Interfaces[name] = def
+		}
+	}
 
+	sortIntrospection(&output)
 	return output, nil
 }
 
+// builtinGoIdents are the identifiers in a Go type expression that --qualify
+// leaves alone
+var builtinGoIdents = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "error": true, "any": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"map": true, "chan": true, "func": true, "interface": true, "struct": true,
+}
+
+var goIdentPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?`)
+
+// qualifyGoTypes rewrites every GoType in the output so named types carry
+// their package, e.g. "[]Track" becomes "[]main.Track". Types that are already
+// qualified (time.Time) and TSType are left as they are.
+func qualifyGoTypes(output *IntrospectionOutput) {
+	appPackage := output.App.PackageName
+	qualify := func(goType, owner string) string {
+		if goType == "" {
+			return ""
+		}
+		return goIdentPattern.ReplaceAllStringFunc(goType, func(ident string) string {
+			if strings.Contains(ident, ".") || builtinGoIdents[ident] {
+				return ident
+			}
+			if pkg, ok := output.typePackages[ident]; ok {
+				return pkg + "." + ident
+			}
+			return owner + "." + ident
+		})
+	}
+	qualifyMethods := func(methods []MethodDef, owner string) {
+		for i := range methods {
+			for j := range methods[i].Params {
+				methods[i].Params[j].GoType = qualify(methods[i].Params[j].GoType, owner)
+			}
+			for j := range methods[i].ReturnTypes {
+				methods[i].ReturnTypes[j].GoType = qualify(methods[i].ReturnTypes[j].GoType, owner)
+			}
+		}
+	}
+
+	for i := range output.App.Fields {
+		output.App.Fields[i].GoType = qualify(output.App.Fields[i].GoType, appPackage)
+	}
+	qualifyMethods(output.App.Methods, appPackage)
+	for name, structDef := range output.Structs {
+		owner := appPackage
+		if pkg, ok := output.typePackages[name]; ok {
+			owner = pkg
+		}
+		for i := range structDef.Fields {
+			structDef.Fields[i].GoType = qualify(structDef.Fields[i].GoType, owner)
+		}
+		qualifyMethods(structDef.Methods, owner)
+	}
+	for _, ifaceDef := range output.Interfaces {
+		qualifyMethods(ifaceDef.Methods, appPackage)
+	}
+	for i := range output.Events {
+		output.Events[i].GoType = qualify(output.Events[i].GoType, appPackage)
+	}
+	for name, constant := range output.Constants {
+		constant.GoType = qualify(constant.GoType, appPackage)
+		output.Constants[name] = constant
+	}
+}
+
+// embeddedStruct records a struct embedded in another, and where its fields
+// go in the embedding struct's field list
+type embeddedStruct struct {
+	index    int
+	typeName string
+}
+
+// embeddedStructName returns the type name of an embedded field, for both
+// T and *T embeds
+func embeddedStructName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// flattenEmbeddedFields promotes the fields of embedded structs into the
+// structs embedding them, the way encoding/json and reflect see them. A field
+// declared on the embedding struct shadows a promoted field of the same name.
+func flattenEmbeddedFields(structFields map[string][]FieldDef, structEmbeds map[string][]embeddedStruct) {
+	done := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var flatten func(name string)
+	flatten = func(name string) {
+		if done[name] || visiting[name] {
+			return
+		}
+		visiting[name] = true
+		defer func() {
+			visiting[name] = false
+			done[name] = true
+		}()
+
+		own := structFields[name]
+		seen := make(map[string]bool)
+		for _, f := range own {
+			seen[f.Name] = true
+		}
+
+		var fields []FieldDef
+		next := 0
+		for _, embed := range structEmbeds[name] {
+			fields = append(fields, own[next:embed.index]...)
+			next = embed.index
+
+			flatten(embed.typeName)
+			for _, f := range structFields[embed.typeName] {
+				if !seen[f.Name] {
+					seen[f.Name] = true
+					fields = append(fields, f)
+				}
+			}
+		}
+		structFields[name] = append(fields, own[next:]...)
+	}
+
+	for name := range structEmbeds {
+		flatten(name)
+	}
+}
+
+// sortIntrospection orders methods by name so the output is reproducible.
+// Fields keep their declaration order, and structs are keyed by name (encoding/json
+// writes map keys sorted).
+func sortIntrospection(output *IntrospectionOutput) {
+	sortMethods(output.App.Methods)
+	for name, structDef := range output.Structs {
+		sortMethods(structDef.Methods)
+		output.Structs[name] = structDef
+	}
+	for name, ifaceDef := range output.Interfaces {
+		sortMethods(ifaceDef.Methods)
+		sort.Strings(ifaceDef.Embeds)
+		output.Interfaces[name] = ifaceDef
+	}
+	sort.Slice(output.Events, func(i, j int) bool {
+		return output.Events[i].Name < output.Events[j].Name
+	})
+}
+
+func sortMethods(methods []MethodDef) {
+	sort.SliceStable(methods, func(i, j int) bool {
+		return methods[i].Name < methods[j].Name
+	})
+}
+
 func generateDTS(opts introspectOptions) (string, error) {
-	app, err := introspectData(opts.filePath)
+	app, err := introspectApp(opts.filePath, opts.appStruct)
 	if err != nil {
 		return "", err
 	}
+	applyBuildInfo(&app, opts)
 
 	runtimeTypes := emptyRuntimeTypes()
 	if opts.runtimeJSONPath != "" {
@@ -642,19 +1111,30 @@ func addRuntimeExtension(runtimeTypes RuntimeTypes, namespace string, subNamespa
 	runtimeTypes.Extensions[namespace][subNamespace] = RuntimeExtensionDef{Methods: methods}
 }
 
+// receiverTypeName returns the base type name of a method receiver, normalizing
+// value (App), pointer (*App), parenthesized (*(App)) and generic (*App[T])
+// receivers to the same name so all methods of a type are grouped together.
 func receiverTypeName(funcDecl *ast.FuncDecl) string {
 	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
 		return ""
 	}
-	switch t := funcDecl.Recv.List[0].Type.(type) {
-	case *ast.StarExpr:
-		if ident, ok := t.X.(*ast.Ident); ok {
-			return ident.Name
+	expr := funcDecl.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
 		}
-	case *ast.Ident:
-		return t.Name
 	}
-	return ""
 }
 
 func extractRuntimeStructFields(structType *ast.StructType, knownStructs map[string]bool, typeAliases map[string]string) []FieldDef {
@@ -666,7 +1146,7 @@ func extractRuntimeStructFields(structType *ast.StructType, knownStructs map[str
 				continue
 			}
 			fieldName := name.Name
-			if taggedName, ok := jsonFieldName(field); ok {
+			if taggedName, _, ok := jsonFieldName(field); ok {
 				fieldName = taggedName
 			}
 			if fieldName == "-" {
@@ -682,22 +1162,57 @@ func extractRuntimeStructFields(structType *ast.StructType, knownStructs map[str
 	return fields
 }
 
-func jsonFieldName(field *ast.Field) (string, bool) {
+// jsonTagOptions are the options of a json struct tag that change how a
+// field is marshaled
+type jsonTagOptions struct {
+	OmitEmpty bool // ",omitempty": the key is left out for zero values
+	String    bool // ",string": a scalar is encoded inside a JSON string
+}
+
+// jsonFieldName returns the key from a field's json tag, "-" for a field that
+// is never marshaled, along with the tag's options. ok is false when there is
+// no tag or it names no key, in which case the Go name is used.
+func jsonFieldName(field *ast.Field) (string, jsonTagOptions, bool) {
+	var opts jsonTagOptions
 	if field.Tag == nil {
-		return "", false
+		return "", opts, false
 	}
 
 	tagValue := strings.Trim(field.Tag.Value, "`")
 	jsonTag := reflect.StructTag(tagValue).Get("json")
 	if jsonTag == "" {
-		return "", false
+		return "", opts, false
+	}
+
+	parts := strings.Split(jsonTag, ",")
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty":
+			opts.OmitEmpty = true
+		case "string":
+			opts.String = true
+		}
 	}
+	return parts[0], opts, parts[0] != ""
+}
 
-	name := strings.Split(jsonTag, ",")[0]
-	if name == "" {
-		return "", false
+// applyJSONTag sets a field's JSONName, Optional and TSType from its json
+// tag, falling back to the Go name. Returns false if the tag is "-", i.e. the
+// field never appears in the JSON.
+func applyJSONTag(def *FieldDef, field *ast.Field) bool {
+	def.JSONName = def.Name
+	name, opts, ok := jsonFieldName(field)
+	if name == "-" {
+		return false
+	}
+	if ok {
+		def.JSONName = name
+	}
+	def.Optional = opts.OmitEmpty
+	if opts.String && (def.TSType == "number" || def.TSType == "boolean") {
+		def.TSType = "string"
 	}
-	return name, true
+	return true
 }
 
 func extractRuntimeMethod(funcDecl *ast.FuncDecl, knownStructs map[string]bool, typeAliases map[string]string) MethodDef {
@@ -743,13 +1258,7 @@ func extractRuntimeMethod(funcDecl *ast.FuncDecl, knownStructs map[string]bool,
 				continue
 			}
 			tsType := runtimeGoTypeToTS(goType, knownStructs, typeAliases, true)
-			if len(result.Names) > 1 {
-				for range result.Names {
-					returnTypes = append(returnTypes, TypeDef{GoType: goType, TSType: tsType})
-				}
-				continue
-			}
-			returnTypes = append(returnTypes, TypeDef{GoType: goType, TSType: tsType})
+			returnTypes = append(returnTypes, returnTypeDefs(result, goType, tsType)...)
 		}
 	}
 
@@ -762,6 +1271,9 @@ func extractRuntimeMethod(funcDecl *ast.FuncDecl, knownStructs map[string]bool,
 }
 
 func runtimeGoTypeToTS(goType string, knownStructs map[string]bool, typeAliases map[string]string, qualifyKnownStructs bool) string {
+	if tsType, ok := stdlibTSTypes[goType]; ok {
+		return tsType
+	}
 	if underlying, ok := typeAliases[goType]; ok {
 		return runtimeGoTypeToTS(underlying, knownStructs, typeAliases, qualifyKnownStructs)
 	}
@@ -853,6 +1365,152 @@ func extractStringLiteral(expr ast.Expr) string {
 	return strings.Trim(lit.Value, `"`)
 }
 
+// findConstants collects exported package-level constants whose values are
+// literals (optionally negated) or true/false. Untyped constants get the
+// default type of their literal. Constants computed from expressions or iota
+// are skipped, since their values are only known to the compiler.
+func findConstants(files []*ast.File, knownStructs map[string]bool) map[string]ConstantDef {
+	constants := make(map[string]ConstantDef)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				if len(valueSpec.Values) != len(valueSpec.Names) {
+					continue
+				}
+				for i, name := range valueSpec.Names {
+					if !isExported(name.Name) {
+						continue
+					}
+					value, goType, ok := constantLiteral(valueSpec.Values[i])
+					if !ok {
+						continue
+					}
+					if valueSpec.Type != nil {
+						goType = exprToString(valueSpec.Type)
+					}
+					constants[name.Name] = ConstantDef{
+						GoType: goType,
+						TSType: goTypeToTS(goType, knownStructs),
+						Value:  value,
+					}
+				}
+			}
+		}
+	}
+	if len(constants) == 0 {
+		return nil
+	}
+	return constants
+}
+
+// constantLiteral returns the value of a constant expression and the default
+// type of an untyped constant with that value
+func constantLiteral(expr ast.Expr) (interface{}, string, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return constantLiteral(e.X)
+	case *ast.UnaryExpr:
+		if e.Op != token.SUB {
+			return nil, "", false
+		}
+		value, goType, ok := constantLiteral(e.X)
+		switch v := value.(type) {
+		case int64:
+			return -v, goType, ok
+		case float64:
+			return -v, goType, ok
+		}
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			value, err := strconv.Unquote(e.Value)
+			return value, "string", err == nil
+		case token.INT:
+			value, err := strconv.ParseInt(e.Value, 0, 64)
+			return value, "int", err == nil
+		case token.FLOAT:
+			value, err := strconv.ParseFloat(strings.ReplaceAll(e.Value, "_", ""), 64)
+			return value, "float64", err == nil
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true, "bool", true
+		case "false":
+			return false, "bool", true
+		}
+	}
+	return nil, "", false
+}
+
+// findRegisteredEvents collects rt.RegisterEvent("name", payload) calls.
+// The payload's Go type is read from a composite literal (optionally
+// addressed), a typed nil such as (*Progress)(nil), or a basic literal. A nil
+// payload is recorded as "nil" and anything else with an empty type.
+func findRegisteredEvents(files []*ast.File) []EventDef {
+	seen := make(map[string]bool)
+	var events []EventDef
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "RegisterEvent" {
+				return true
+			}
+			name := extractStringLiteral(call.Args[0])
+			if name == "" || seen[name] {
+				return true
+			}
+			seen[name] = true
+			events = append(events, EventDef{Name: name, GoType: eventPayloadType(call.Args[1])})
+			return true
+		})
+	}
+	return events
+}
+
+// eventPayloadType returns the Go type of an example payload expression
+func eventPayloadType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return eventPayloadType(e.X)
+		}
+	case *ast.CompositeLit:
+		return exprToString(e.Type)
+	case *ast.CallExpr:
+		if paren, ok := e.Fun.(*ast.ParenExpr); ok && len(e.Args) == 1 {
+			if star, ok := paren.X.(*ast.StarExpr); ok {
+				return exprToString(star.X)
+			}
+		}
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return "string"
+		case token.INT:
+			return "int"
+		case token.FLOAT:
+			return "float64"
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "nil":
+			return "nil"
+		case "true", "false":
+			return "bool"
+		}
+	}
+	return ""
+}
+
 func extractRuntimeInstanceType(expr ast.Expr) string {
 	switch t := expr.(type) {
 	case *ast.UnaryExpr:
@@ -918,9 +1576,9 @@ func generateTypeScriptDefinitions(introspection IntrospectionOutput, runtimeTyp
 		"// Auto-generated Strux type definitions",
 		"// Run 'strux types' to regenerate from Go code",
 		"// This file is automatically generated. DO NOT EDIT",
-		"",
-		"declare global {",
 	}
+	lines = append(lines, buildInfoComments(introspection.App)...)
+	lines = append(lines, "", "declare global {")
 
 	globalLines := generateRuntimeGlobalLines(runtimeTypes)
 	if len(globalLines) > 0 {
@@ -938,6 +1596,24 @@ func generateTypeScriptDefinitions(introspection IntrospectionOutput, runtimeTyp
 	return strings.Join(lines, "\n")
 }
 
+// buildInfoComments records the app version and build metadata in the
+// header, so the definitions show which build they were generated from
+func buildInfoComments(app AppInfo) []string {
+	var lines []string
+	if app.Version != "" {
+		lines = append(lines, "// App version: "+app.Version)
+	}
+	keys := make([]string, 0, len(app.BuildMeta))
+	for key := range app.BuildMeta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("// Build %s: %s", key, app.BuildMeta[key]))
+	}
+	return lines
+}
+
 func generateRuntimeGlobalLines(runtimeTypes RuntimeTypes) []string {
 	lines := []string{}
 
@@ -990,8 +1666,11 @@ func generateRuntimeGlobalLines(runtimeTypes RuntimeTypes) []string {
 		}
 
 		if namespace == "strux" {
+			lines = append(lines, "  readStream(handle: "+streamHandleTSType+", type?: string): Promise<Blob>;")
 			lines = append(lines, "  ipc: {")
+			lines = append(lines, "    on<K extends keyof StruxEvents>(event: K, callback: (data: StruxEvents[K]) => void): () => void;")
 			lines = append(lines, "    on(event: string, callback: (data: any) => void): () => void;")
+			lines = append(lines, "    off<K extends keyof StruxEvents>(event: K, callback: (data: StruxEvents[K]) => void): void;")
 			lines = append(lines, "    off(event: string, callback: (data: any) => void): void;")
 			lines = append(lines, "    send(event: string, data?: any): void;")
 			lines = append(lines, "  };")
@@ -1008,7 +1687,7 @@ func generateAppGlobalLines(introspection IntrospectionOutput) []string {
 	app := introspection.App
 	structs := introspection.Structs
 
-	for _, structName := range findUsedStructs(app, structs) {
+	for _, structName := range findUsedStructs(app, structs, introspection.Events) {
 		structDef, ok := structs[structName]
 		if !ok {
 			continue
@@ -1018,7 +1697,7 @@ func generateAppGlobalLines(introspection IntrospectionOutput) []string {
 		}
 		lines = append(lines, fmt.Sprintf("interface %s {", structName))
 		for _, field := range structDef.Fields {
-			lines = append(lines, fmt.Sprintf("  %s: %s;", field.Name, field.TSType))
+			lines = append(lines, formatDTSField(field))
 		}
 		if len(structDef.Fields) > 0 && len(structDef.Methods) > 0 {
 			lines = append(lines, "")
@@ -1033,6 +1712,17 @@ func generateAppGlobalLines(introspection IntrospectionOutput) []string {
 		lines = append(lines, "")
 	}
 
+	// Payload types of registered events, used by strux.ipc.on
+	if len(introspection.Events) == 0 {
+		lines = append(lines, "interface StruxEvents {}", "")
+	} else {
+		lines = append(lines, "interface StruxEvents {")
+		for _, event := range introspection.Events {
+			lines = append(lines, fmt.Sprintf("  %q: %s;", event.Name, event.TSType))
+		}
+		lines = append(lines, "}", "")
+	}
+
 	lines = append(lines, fmt.Sprintf("interface %s {", app.Name))
 	for _, field := range app.Fields {
 		lines = append(lines, fmt.Sprintf("  %s: %s;", field.Name, field.TSType))
@@ -1067,6 +1757,23 @@ func indentLine(line string) string {
 	return "  " + line
 }
 
+// formatDTSField formats a field of a value struct, named as it appears in
+// the JSON the frontend receives
+func formatDTSField(field FieldDef) string {
+	name := field.Name
+	if field.JSONName != "" {
+		name = field.JSONName
+	}
+	if field.Optional {
+		name += "?"
+	}
+	line := fmt.Sprintf("  %s: %s;", name, field.TSType)
+	if field.Default != "" {
+		line += " // default: " + field.Default
+	}
+	return line
+}
+
 func formatDTSParams(params []ParamDef) string {
 	parts := make([]string, 0, len(params))
 	for index, param := range params {
@@ -1074,14 +1781,26 @@ func formatDTSParams(params []ParamDef) string {
 		if name == "" {
 			name = fmt.Sprintf("arg%d", index)
 		}
+		if param.Optional {
+			name += "?"
+		}
 		parts = append(parts, fmt.Sprintf("%s: %s", name, param.TSType))
 	}
 	return strings.Join(parts, ", ")
 }
 
 func formatDTSReturnType(method MethodDef) string {
+	if method.FireAndForget {
+		return "Promise<void>"
+	}
 	baseType := "void"
-	if len(method.ReturnTypes) == 1 {
+	if method.NamedResults {
+		parts := make([]string, 0, len(method.ReturnTypes))
+		for _, returnType := range method.ReturnTypes {
+			parts = append(parts, returnType.Name+": "+returnType.TSType)
+		}
+		baseType = "{ " + strings.Join(parts, "; ") + " }"
+	} else if len(method.ReturnTypes) == 1 {
 		baseType = method.ReturnTypes[0].TSType
 	} else if len(method.ReturnTypes) > 1 {
 		parts := make([]string, 0, len(method.ReturnTypes))
@@ -1090,33 +1809,27 @@ func formatDTSReturnType(method MethodDef) string {
 		}
 		baseType = "[" + strings.Join(parts, ", ") + "]"
 	}
-	if method.HasError && len(method.ReturnTypes) > 0 {
+	if method.HasError && len(method.ReturnTypes) > 0 && baseType != channelResultTSType {
 		baseType += " | null"
 	}
 	return fmt.Sprintf("Promise<%s>", baseType)
 }
 
-func findUsedStructs(app AppInfo, structs map[string]StructDef) []string {
+func findUsedStructs(app AppInfo, structs map[string]StructDef, events []EventDef) []string {
 	used := make(map[string]bool)
-	knownStructs := make(map[string]bool)
-	for name := range structs {
-		knownStructs[name] = true
-	}
 
+	for _, event := range events {
+		addUsedStructs(event.TSType, structs, used)
+	}
 	for _, field := range app.Fields {
-		addUsedStructs(field.TSType, knownStructs, used)
+		addUsedStructs(field.TSType, structs, used)
 	}
 	for _, method := range app.Methods {
-		for _, param := range method.Params {
-			addUsedStructs(param.TSType, knownStructs, used)
-		}
-		for _, returnType := range method.ReturnTypes {
-			addUsedStructs(returnType.TSType, knownStructs, used)
-		}
+		addUsedMethodStructs(method, structs, used)
 	}
 	for name, structDef := range structs {
 		if len(structDef.Methods) > 0 {
-			used[name] = true
+			addUsedStructs(name, structs, used)
 		}
 	}
 
@@ -1128,10 +1841,34 @@ func findUsedStructs(app AppInfo, structs map[string]StructDef) []string {
 	return names
 }
 
-func addUsedStructs(tsType string, knownStructs map[string]bool, used map[string]bool) {
-	tsType = strings.TrimSuffix(tsType, "[]")
-	if knownStructs[tsType] {
-		used[tsType] = true
+// tsIdentifierPattern matches type names inside a TS type such as
+// "Record<string, User>" or "User[]"
+var tsIdentifierPattern = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// addUsedStructs marks every struct referenced by tsType as used, along with
+// the structs those reference through their fields and methods.
+func addUsedStructs(tsType string, structs map[string]StructDef, used map[string]bool) {
+	for _, name := range tsIdentifierPattern.FindAllString(tsType, -1) {
+		structDef, ok := structs[name]
+		if !ok || used[name] {
+			continue
+		}
+		used[name] = true
+		for _, field := range structDef.Fields {
+			addUsedStructs(field.TSType, structs, used)
+		}
+		for _, method := range structDef.Methods {
+			addUsedMethodStructs(method, structs, used)
+		}
+	}
+}
+
+func addUsedMethodStructs(method MethodDef, structs map[string]StructDef, used map[string]bool) {
+	for _, param := range method.Params {
+		addUsedStructs(param.TSType, structs, used)
+	}
+	for _, returnType := range method.ReturnTypes {
+		addUsedStructs(returnType.TSType, structs, used)
 	}
 }
 
@@ -1142,6 +1879,362 @@ const runtimeImportPath = "github.com/strux-dev/strux/pkg/runtime"
 // Populated during AST parsing and used by goTypeToTS to resolve non-struct named types.
 var globalTypeAliases = make(map[string]string)
 
+// appLifecycleMethods are app methods the runtime calls itself (see pkg/runtime/lifecycle.go).
+// They are not bound to the frontend, so they are left out of the generated types.
+var appLifecycleMethods = map[string]bool{
+	"OnReady":        true,
+	"OnShutdown":     true,
+	"OptionalParams": true,
+	"FireAndForget":  true,
+	"MaxConcurrent":  true,
+	"ReturnNames":    true,
+}
+
+// isAppLifecycleMethod reports whether an app method is a runtime hook.
+// Startup(ctx context.Context) error and Shutdown() error are only hooks with
+// exactly those signatures, as in the runtime, so a Startup() error without
+// the context stays bound.
+func isAppLifecycleMethod(m MethodDef) bool {
+	returnsOnlyError := m.HasError && len(m.Params) == 0 && len(m.ReturnTypes) == 0
+	switch m.Name {
+	case "Startup":
+		return returnsOnlyError && len(m.injected) == 1 && m.injected[0] == "context.Context"
+	case "Shutdown":
+		return returnsOnlyError && len(m.injected) == 0
+	}
+	return appLifecycleMethods[m.Name]
+}
+
+// optionalParamsEntries reads the literal map returned by an app's OptionalParams()
+// method, e.g. `return map[string]int{"Greet": 1}`. Non-literal entries are skipped.
+func optionalParamsEntries(funcDecl *ast.FuncDecl) map[string]int {
+	entries := make(map[string]int)
+	if funcDecl == nil || funcDecl.Body == nil {
+		return entries
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return true
+		}
+		lit, ok := ret.Results[0].(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, keyOK := kv.Key.(*ast.BasicLit)
+			value, valueOK := kv.Value.(*ast.BasicLit)
+			if !keyOK || !valueOK || key.Kind != token.STRING || value.Kind != token.INT {
+				continue
+			}
+			path, err := strconv.Unquote(key.Value)
+			if err != nil {
+				continue
+			}
+			required, err := strconv.Atoi(value.Value)
+			if err != nil {
+				continue
+			}
+			entries[path] = required
+		}
+		return false
+	})
+
+	return entries
+}
+
+// applyOptionalParams marks params past each method's required count as optional.
+// Dotted paths ("Settings.Save") are resolved through the app's struct fields.
+func applyOptionalParams(funcDecl *ast.FuncDecl, appStructName string, appMethods []MethodDef, structFields map[string][]FieldDef, structMethods map[string][]MethodDef) {
+	for path, required := range optionalParamsEntries(funcDecl) {
+		method := methodAtPath(path, appStructName, appMethods, structFields, structMethods)
+		if method == nil {
+			continue
+		}
+		for i := range method.Params {
+			if i >= required {
+				method.Params[i].Optional = true
+			}
+		}
+	}
+}
+
+// streamHandleTSType is the TypeScript type of a method result the runtime
+// streams (a single io.Reader return); pass it to strux.readStream()
+const streamHandleTSType = "{ stream: string }"
+
+// channelResultTSType is the TypeScript type of a method result the runtime
+// drains (a single receive channel return). Its values arrive as __callStream
+// events and the call resolves with nothing once the channel is closed.
+const channelResultTSType = "void"
+
+// markStreamReturns types single io.Reader returns as stream handles and
+// single receive channel returns as void
+func markStreamReturns(methods []MethodDef) {
+	for i := range methods {
+		if len(methods[i].ReturnTypes) != 1 {
+			continue
+		}
+		goType := methods[i].ReturnTypes[0].GoType
+		switch {
+		case goType == "io.Reader":
+			methods[i].ReturnTypes[0].TSType = streamHandleTSType
+		case strings.HasPrefix(goType, "<-chan ") || strings.HasPrefix(goType, "chan "):
+			methods[i].ReturnTypes[0].TSType = channelResultTSType
+		}
+	}
+}
+
+// markOptionsParams types a method's sole struct parameter as an optional
+// Partial<T>: the runtime fills missing fields from their defaults and accepts
+// the argument being left out.
+func markOptionsParams(methods []MethodDef, knownStructs map[string]bool) {
+	for i := range methods {
+		if len(methods[i].Params) != 1 {
+			continue
+		}
+		param := &methods[i].Params[0]
+		if knownStructs[param.GoType] {
+			param.Optional = true
+			param.TSType = "Partial<" + param.TSType + ">"
+		}
+	}
+}
+
+// fireAndForgetEntries reads the literal slice returned by an app's FireAndForget()
+// method, e.g. `return []string{"Log"}`. Non-literal entries are skipped.
+func fireAndForgetEntries(funcDecl *ast.FuncDecl) []string {
+	var entries []string
+	if funcDecl == nil || funcDecl.Body == nil {
+		return entries
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return true
+		}
+		lit, ok := ret.Results[0].(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			value, ok := elt.(*ast.BasicLit)
+			if !ok || value.Kind != token.STRING {
+				continue
+			}
+			if path, err := strconv.Unquote(value.Value); err == nil {
+				entries = append(entries, path)
+			}
+		}
+		return false
+	})
+
+	return entries
+}
+
+// applyFireAndForget flags the methods the app declared fire-and-forget, matching
+// the runtime, which sends no response for them.
+func applyFireAndForget(funcDecl *ast.FuncDecl, appStructName string, appMethods []MethodDef, structFields map[string][]FieldDef, structMethods map[string][]MethodDef) {
+	for _, path := range fireAndForgetEntries(funcDecl) {
+		if method := methodAtPath(path, appStructName, appMethods, structFields, structMethods); method != nil {
+			method.FireAndForget = true
+		}
+	}
+}
+
+// returnNamesEntries reads the literal map returned by an app's ReturnNames()
+// method, e.g. `return map[string][]string{"Stats": {"count", "total"}}`.
+// Entries with non-literal names are skipped.
+func returnNamesEntries(funcDecl *ast.FuncDecl) map[string][]string {
+	entries := make(map[string][]string)
+	if funcDecl == nil || funcDecl.Body == nil {
+		return entries
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return true
+		}
+		lit, ok := ret.Results[0].(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, keyOK := kv.Key.(*ast.BasicLit)
+			value, valueOK := kv.Value.(*ast.CompositeLit)
+			if !keyOK || !valueOK || key.Kind != token.STRING {
+				continue
+			}
+			path, err := strconv.Unquote(key.Value)
+			if err != nil {
+				continue
+			}
+			names := make([]string, 0, len(value.Elts))
+			for _, nameElt := range value.Elts {
+				nameLit, ok := nameElt.(*ast.BasicLit)
+				if !ok || nameLit.Kind != token.STRING {
+					break
+				}
+				name, err := strconv.Unquote(nameLit.Value)
+				if err != nil {
+					break
+				}
+				names = append(names, name)
+			}
+			if len(names) == len(value.Elts) {
+				entries[path] = names
+			}
+		}
+		return false
+	})
+
+	return entries
+}
+
+// applyReturnNames names the results of the methods listed in the app's
+// ReturnNames(), which the runtime returns as an object keyed by those names.
+// Entries the runtime would ignore (a name per result is required, and
+// streamed results cannot be named) are skipped the same way.
+func applyReturnNames(funcDecl *ast.FuncDecl, appStructName string, appMethods []MethodDef, structFields map[string][]FieldDef, structMethods map[string][]MethodDef) {
+	for path, names := range returnNamesEntries(funcDecl) {
+		method := methodAtPath(path, appStructName, appMethods, structFields, structMethods)
+		if method == nil || len(names) != len(method.ReturnTypes) || !distinctNames(names) {
+			continue
+		}
+		if len(names) == 1 && method.ReturnTypes[0].GoType == "io.Reader" {
+			continue
+		}
+		for i, name := range names {
+			method.ReturnTypes[i].Name = name
+		}
+		method.NamedResults = true
+	}
+}
+
+// distinctNames reports whether names are all non-empty and distinct
+func distinctNames(names []string) bool {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" || seen[name] {
+			return false
+		}
+		seen[name] = true
+	}
+	return true
+}
+
+// methodAtPath finds the method a frontend path ("Greet", "Settings.Save") refers
+// to. Dotted paths are resolved through the app's struct fields.
+func methodAtPath(path string, appStructName string, appMethods []MethodDef, structFields map[string][]FieldDef, structMethods map[string][]MethodDef) *MethodDef {
+	segments := strings.Split(path, ".")
+	methodName := segments[len(segments)-1]
+
+	target := appMethods
+	if len(segments) > 1 {
+		structName := appStructName
+		for _, fieldName := range segments[:len(segments)-1] {
+			next := ""
+			for _, field := range structFields[structName] {
+				if field.Name == fieldName {
+					next = strings.TrimPrefix(field.GoType, "*")
+					break
+				}
+			}
+			structName = next
+		}
+		target = structMethods[structName]
+	}
+
+	for i := range target {
+		if target[i].Name == methodName {
+			return &target[i]
+		}
+	}
+	return nil
+}
+
+// struxFieldGroup returns the group name from a field's `strux:"group=..."` tag
+func struxFieldGroup(field *ast.Field) string {
+	return struxTagOption(field, "group")
+}
+
+// struxTagOption returns the value of a key=value option in a field's strux tag
+func struxTagOption(field *ast.Field, name string) string {
+	if field.Tag == nil {
+		return ""
+	}
+	tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+	for _, option := range strings.Split(tag.Get("strux"), ",") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(option), "="); ok && strings.TrimSpace(key) == name {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// buildFieldGroups returns an interface per app field group (named after the
+// group, e.g. "settings" -> Settings) and the Get/Set accessor methods the
+// runtime binds for it. Accessors that clash with app methods are skipped.
+func buildFieldGroups(appFields []FieldDef, appMethods []MethodDef, knownStructs map[string]bool) (map[string]StructDef, []MethodDef) {
+	groupFields := make(map[string][]FieldDef)
+	var groupNames []string
+	for _, field := range appFields {
+		if field.Group == "" {
+			continue
+		}
+		if _, exists := groupFields[field.Group]; !exists {
+			groupNames = append(groupNames, field.Group)
+		}
+		groupFields[field.Group] = append(groupFields[field.Group], field)
+	}
+	sort.Strings(groupNames)
+
+	existingMethods := make(map[string]bool, len(appMethods))
+	for _, m := range appMethods {
+		existingMethods[m.Name] = true
+	}
+
+	structs := make(map[string]StructDef)
+	var methods []MethodDef
+	for _, group := range groupNames {
+		suffix := strings.ToUpper(group[:1]) + group[1:]
+		interfaceName := suffix
+		if knownStructs[interfaceName] {
+			interfaceName += "Group"
+		}
+		structs[interfaceName] = StructDef{Fields: groupFields[group]}
+
+		if !existingMethods["Get"+suffix] {
+			methods = append(methods, MethodDef{
+				Name:        "Get" + suffix,
+				Params:      []ParamDef{},
+				ReturnTypes: []TypeDef{{GoType: "map[string]interface{}", TSType: interfaceName}},
+				HasError:    true,
+			})
+		}
+		if !existingMethods["Set"+suffix] {
+			methods = append(methods, MethodDef{
+				Name:        "Set" + suffix,
+				Params:      []ParamDef{{Name: "values", GoType: "map[string]interface{}", TSType: "Partial<" + interfaceName + ">"}},
+				ReturnTypes: []TypeDef{},
+				HasError:    true,
+			})
+		}
+	}
+	return structs, methods
+}
+
 // findRuntimeStartStruct finds the struct type passed to runtime.Start() by:
 // 1. Finding the import alias for the strux runtime package
 // 2. Finding the call to <alias>.Start(arg)
@@ -1217,7 +2310,7 @@ func findRuntimeStartStruct(files []*ast.File) string {
 					return true
 				}
 
-				if ident.Name == runtimeAlias && (selExpr.Sel.Name == "Start" || selExpr.Sel.Name == "Init") && len(callExpr.Args) >= 1 {
+				if ident.Name == runtimeAlias && runtimeAppConstructors[selExpr.Sel.Name] && len(callExpr.Args) >= 1 {
 					arg := callExpr.Args[0]
 
 					// Case 1: runtime.Start(&App{...}) - direct composite literal
@@ -1249,6 +2342,103 @@ func findRuntimeStartStruct(files []*ast.File) string {
 	return ""
 }
 
+// appStructMarker designates the app struct when placed in the doc comment
+// of its type declaration
+const appStructMarker = "//strux:app"
+
+// runtimeAppConstructors are the runtime functions whose first argument is the app
+var runtimeAppConstructors = map[string]bool{
+	"Start":            true,
+	"StartWithOptions": true,
+	"Init":             true,
+	"InitWithOptions":  true,
+	"New":              true,
+	"NewWithOptions":   true,
+}
+
+// findMarkedAppStruct returns the struct whose declaration carries the
+// //strux:app marker, if any
+func findMarkedAppStruct(files []*ast.File) string {
+	hasMarker := func(doc *ast.CommentGroup) bool {
+		if doc == nil {
+			return false
+		}
+		for _, comment := range doc.List {
+			if strings.TrimSpace(comment.Text) == appStructMarker {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				if _, ok := typeSpec.Type.(*ast.StructType); !ok {
+					continue
+				}
+				// A lone type declaration keeps its comment on the GenDecl
+				if hasMarker(typeSpec.Doc) || (len(genDecl.Specs) == 1 && hasMarker(genDecl.Doc)) {
+					return typeSpec.Name.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// detectAppStruct determines the app struct: the struct marked //strux:app,
+// else the struct passed to runtime.Start() (or Init/New), else a struct
+// literally named App, else the only exported
+// struct declared in the main file. Defaults to "App". None of these depend on
+// the struct having methods, so field-only apps are detected too.
+func detectAppStruct(files []*ast.File, fset *token.FileSet, mainFilePath string, knownStructs map[string]bool) string {
+	if name := findMarkedAppStruct(files); name != "" {
+		return name
+	}
+	if name := findRuntimeStartStruct(files); name != "" {
+		return name
+	}
+	if knownStructs["App"] {
+		return "App"
+	}
+
+	for _, file := range files {
+		if path, _ := filepath.Abs(fset.Position(file.Pos()).Filename); path != mainFilePath {
+			continue
+		}
+		var candidates []string
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok || !isExported(typeSpec.Name.Name) {
+					continue
+				}
+				if _, ok := typeSpec.Type.(*ast.StructType); ok {
+					candidates = append(candidates, typeSpec.Name.Name)
+				}
+			}
+		}
+		if len(candidates) == 1 {
+			return candidates[0]
+		}
+	}
+
+	return "App"
+}
+
 // resolveStructType extracts the struct type name from an expression.
 // Handles: &App{}, App{}, &App, new(App)
 func resolveStructType(expr ast.Expr) string {
@@ -1314,9 +2504,13 @@ func stripTypeWrappers(goType string) string {
 	return s
 }
 
-// extractQualifiedType returns the qualified type from a Go type string, or empty string if not qualified
+// extractQualifiedType returns the qualified type from a Go type string, or empty string if not qualified.
+// Standard library types with a fixed TypeScript mapping are not resolved as structs.
 func extractQualifiedType(goType string) string {
 	stripped := stripTypeWrappers(goType)
+	if _, ok := stdlibTSTypes[stripped]; ok {
+		return ""
+	}
 	if strings.Contains(stripped, ".") && !strings.HasPrefix(stripped, "map[") {
 		return stripped
 	}
@@ -1422,11 +2616,14 @@ func resolveExternalPackage(projectDir string, importPath string, typeNames []st
 								fieldName := field.Names[0].Name
 								if isExported(fieldName) {
 									goType := exprToString(field.Type)
-									fields = append(fields, FieldDef{
+									def := FieldDef{
 										Name:   fieldName,
 										GoType: goType,
 										TSType: goTypeToTS(goType, extKnownStructs),
-									})
+									}
+									if applyJSONTag(&def, field) {
+										fields = append(fields, def)
+									}
 								}
 							}
 						}
@@ -1441,16 +2638,7 @@ func resolveExternalPackage(projectDir string, importPath string, typeNames []st
 				// Collect methods on structs
 				if funcDecl, ok := n.(*ast.FuncDecl); ok {
 					if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
-						recvType := funcDecl.Recv.List[0].Type
-						var recvTypeName string
-						switch t := recvType.(type) {
-						case *ast.StarExpr:
-							if ident, ok := t.X.(*ast.Ident); ok {
-								recvTypeName = ident.Name
-							}
-						case *ast.Ident:
-							recvTypeName = t.Name
-						}
+						recvTypeName := receiverTypeName(funcDecl)
 						if recvTypeName != "" && isExported(funcDecl.Name.Name) {
 							method := extractMethod(funcDecl, extKnownStructs)
 							allStructMethods[recvTypeName] = append(allStructMethods[recvTypeName], method)
@@ -1510,6 +2698,9 @@ func goTypeToTSWithQualified(goType string, knownStructs map[string]bool, qualif
 	if tsName, ok := qualifiedToTS[goType]; ok {
 		return tsName
 	}
+	if tsType, ok := stdlibTSTypes[goType]; ok {
+		return tsType
+	}
 
 	// Handle wrappers
 	if strings.HasPrefix(goType, "[]") {
@@ -1534,15 +2725,32 @@ func goTypeToTSWithQualified(goType string, knownStructs map[string]bool, qualif
 	return goTypeToTS(goType, knownStructs)
 }
 
+// injectedGoTypes are the leading method parameters the runtime injects (a
+// call context and the connection session). They are not part of the
+// frontend API.
+var injectedGoTypes = map[string]bool{
+	"context.Context":  true,
+	"*runtime.Session": true,
+}
+
 func extractMethod(funcDecl *ast.FuncDecl, knownStructs map[string]bool) MethodDef {
 	methodName := funcDecl.Name.Name
 
 	// Extract parameters - initialize as empty slice, not nil
 	params := []ParamDef{}
+	var injected []string
 	if funcDecl.Type.Params != nil {
 		paramIndex := 0
+		leading := true
 		for _, field := range funcDecl.Type.Params.List {
 			goType := exprToString(field.Type)
+			// A leading context.Context and *runtime.Session are supplied by
+			// the runtime, not the frontend
+			if leading && len(field.Names) <= 1 && injectedGoTypes[goType] {
+				injected = append(injected, goType)
+				continue
+			}
+			leading = false
 			tsType := goTypeToTS(goType, knownStructs)
 
 			if len(field.Names) == 0 {
@@ -1587,20 +2795,7 @@ func extractMethod(funcDecl *ast.FuncDecl, knownStructs map[string]bool) MethodD
 				continue // Skip error types
 			}
 
-			// Handle multiple names on same type (e.g., "x, y int")
-			if len(result.Names) > 1 {
-				for range result.Names {
-					returnTypes = append(returnTypes, TypeDef{
-						GoType: goType,
-						TSType: goTypeToTS(goType, knownStructs),
-					})
-				}
-			} else {
-				returnTypes = append(returnTypes, TypeDef{
-					GoType: goType,
-					TSType: goTypeToTS(goType, knownStructs),
-				})
-			}
+			returnTypes = append(returnTypes, returnTypeDefs(result, goType, goTypeToTS(goType, knownStructs))...)
 		}
 	}
 
@@ -1609,7 +2804,50 @@ func extractMethod(funcDecl *ast.FuncDecl, knownStructs map[string]bool) MethodD
 		Params:      params,
 		ReturnTypes: returnTypes,
 		HasError:    hasError,
+		injected:    injected,
+	}
+}
+
+// returnTypeDefs expands one result of a signature into a TypeDef per value,
+// keeping its names: "(count int)" gives one named TypeDef, "(x, y int)" two,
+// and an unnamed result one without a name. Blank (_) names are dropped.
+func returnTypeDefs(result *ast.Field, goType, tsType string) []TypeDef {
+	if len(result.Names) == 0 {
+		return []TypeDef{{GoType: goType, TSType: tsType}}
 	}
+	defs := make([]TypeDef, 0, len(result.Names))
+	for _, name := range result.Names {
+		def := TypeDef{GoType: goType, TSType: tsType}
+		if name.Name != "_" {
+			def.Name = name.Name
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// extractInterface lists the methods and embedded interfaces of a named
+// interface. Empty interfaces (type Any interface{}) report false, since they
+// are the same as interface{}.
+func extractInterface(iface *ast.InterfaceType, knownStructs map[string]bool) (InterfaceDef, bool) {
+	def := InterfaceDef{Methods: []MethodDef{}}
+	if iface.Methods == nil {
+		return def, false
+	}
+	for _, field := range iface.Methods.List {
+		funcType, ok := field.Type.(*ast.FuncType)
+		if !ok {
+			// Embedded interface (or a type constraint term)
+			def.Embeds = append(def.Embeds, exprToString(field.Type))
+			continue
+		}
+		for _, name := range field.Names {
+			if isExported(name.Name) {
+				def.Methods = append(def.Methods, extractMethod(&ast.FuncDecl{Name: name, Type: funcType}, knownStructs))
+			}
+		}
+	}
+	return def, len(def.Methods) > 0 || len(def.Embeds) > 0
 }
 
 func exprToString(expr ast.Expr) string {
@@ -1628,18 +2866,40 @@ func exprToString(expr ast.Expr) string {
 		return "interface{}"
 	case *ast.Ellipsis:
 		return "..." + exprToString(t.Elt)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.RECV:
+			return "<-chan " + exprToString(t.Value)
+		case ast.SEND:
+			return "chan<- " + exprToString(t.Value)
+		}
+		return "chan " + exprToString(t.Value)
 	default:
 		return "unknown"
 	}
 }
 
+// stdlibTSTypes maps standard library types to the TypeScript type of their
+// JSON encoding. Qualified names are matched as exprToString writes them.
+var stdlibTSTypes = map[string]string{
+	"time.Time":     "string", // RFC 3339 timestamp
+	"time.Duration": "number", // Nanoseconds
+	"[]byte":        "string", // Base64
+	"[]uint8":       "string", // Base64
+	// Embedded verbatim, so it can be any JSON value
+	"json.RawMessage": "any",
+}
+
 func goTypeToTS(goType string, knownStructs map[string]bool) string {
+	if tsType, ok := stdlibTSTypes[goType]; ok {
+		return tsType
+	}
 	switch goType {
 	case "string":
 		return "string"
 	case "int", "int8", "int16", "int32", "int64",
 		"uint", "uint8", "uint16", "uint32", "uint64",
-		"float32", "float64":
+		"float32", "float64", "byte", "rune":
 		return "number"
 	case "bool":
 		return "boolean"
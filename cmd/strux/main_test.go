@@ -0,0 +1,1402 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func writeFixture(t *testing.T, dir, name, source string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	return path
+}
+
+func methodNames(methods []MethodDef) []string {
+	names := make([]string, 0, len(methods))
+	for _, m := range methods {
+		names = append(names, m.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestIntrospectGroupsPointerAndValueReceivers(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type App struct {
+	Title string
+}
+
+func (a App) Greet(name string) string { return "Hello " + name }
+
+func (a *App) SetTitle(title string) { a.Title = title }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+	writeFixture(t, tempDir, "more.go", `package main
+
+func (a *App) Count() int { return 0 }
+
+func (App) Version() string { return "1.0.0" }
+
+func (a *(App)) Reset() error { return nil }
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	if output.App.Name != "App" {
+		t.Fatalf("expected app struct App, got %q", output.App.Name)
+	}
+
+	got := methodNames(output.App.Methods)
+	want := []string{"Count", "Greet", "Reset", "SetTitle", "Version"}
+	if len(got) != len(want) {
+		t.Fatalf("expected methods %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected methods %v, got %v", want, got)
+		}
+	}
+}
+
+func TestDiffIntrospectionReportsSignatureChanges(t *testing.T) {
+	oldOutput := IntrospectionOutput{
+		App: AppInfo{
+			Name:   "App",
+			Fields: []FieldDef{{Name: "Title", GoType: "string"}, {Name: "Count", GoType: "int"}},
+			Methods: []MethodDef{
+				{Name: "Greet", Params: []ParamDef{{Name: "name", GoType: "string"}}, ReturnTypes: []TypeDef{{GoType: "string"}}},
+				{Name: "Reset", HasError: true},
+			},
+		},
+		Structs: map[string]StructDef{"Old": {}},
+	}
+	newOutput := IntrospectionOutput{
+		App: AppInfo{
+			Name:   "App",
+			Fields: []FieldDef{{Name: "Title", GoType: "string"}, {Name: "Count", GoType: "int64"}},
+			Methods: []MethodDef{
+				{Name: "Greet", Params: []ParamDef{{Name: "name", GoType: "string"}, {Name: "loud", GoType: "bool"}}, ReturnTypes: []TypeDef{{GoType: "string"}}},
+				{Name: "Version", ReturnTypes: []TypeDef{{GoType: "string"}}},
+			},
+		},
+		Structs: map[string]StructDef{"New": {}},
+	}
+
+	got := diffIntrospection(oldOutput, newOutput)
+	want := []string{
+		"~ field App.Count int -> int64",
+		"~ method App.Greet(name string) string -> Greet(name string, loud bool) string",
+		"- method App.Reset() error",
+		"+ method App.Version() string",
+		"+ struct New",
+		"- struct Old",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected changes %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected changes %q, got %q", want, got)
+		}
+	}
+}
+
+func TestIntrospectOutputIsStable(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Settings struct {
+	Volume int
+	Muted  bool
+}
+
+func (s *Settings) Reset() {}
+
+func (s *Settings) Apply() error { return nil }
+
+type App struct {
+	Title    string
+	Settings Settings
+	Count    int
+}
+
+func (a *App) Zoom(level int) {}
+
+func (a *App) Greet(name string) string { return "Hello " + name }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+	writeFixture(t, tempDir, "a.go", `package main
+
+func (a *App) Beep() {}
+
+type Extra struct {
+	Label string
+}
+`)
+	writeFixture(t, tempDir, "z.go", `package main
+
+func (a *App) Add(x int) int { return x }
+`)
+
+	encode := func() string {
+		output, err := introspectData(mainPath)
+		if err != nil {
+			t.Fatalf("introspectData failed: %v", err)
+		}
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		return string(data)
+	}
+
+	first := encode()
+	for i := 0; i < 5; i++ {
+		if got := encode(); got != first {
+			t.Fatalf("introspection output changed between runs:\n%s\n---\n%s", first, got)
+		}
+	}
+
+	output, _ := introspectData(mainPath)
+	gotMethods := make([]string, 0, len(output.App.Methods))
+	for _, m := range output.App.Methods {
+		gotMethods = append(gotMethods, m.Name)
+	}
+	if want := []string{"Add", "Beep", "Greet", "Zoom"}; strings.Join(gotMethods, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected methods sorted as %v, got %v", want, gotMethods)
+	}
+
+	gotFields := make([]string, 0, len(output.App.Fields))
+	for _, f := range output.App.Fields {
+		gotFields = append(gotFields, f.Name)
+	}
+	if want := []string{"Title", "Settings", "Count"}; strings.Join(gotFields, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected fields in declaration order %v, got %v", want, gotFields)
+	}
+
+	if methods := output.Structs["Settings"].Methods; len(methods) != 2 || methods[0].Name != "Apply" {
+		t.Fatalf("expected Settings methods sorted by name, got %v", methodNames(methods))
+	}
+}
+
+func TestIntrospectMethodlessApp(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Kiosk struct {
+	Title string
+	Count int
+}
+
+func newKiosk() *Kiosk { return &Kiosk{Title: "Lobby"} }
+
+func main() {
+	runtime.Start(newKiosk())
+}
+`)
+	writeFixture(t, tempDir, "types.go", `package main
+
+type Banner struct {
+	Text string
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+	if output.App.Name != "Kiosk" {
+		t.Fatalf("expected app struct Kiosk, got %q", output.App.Name)
+	}
+	if len(output.App.Fields) != 2 || len(output.App.Methods) != 0 {
+		t.Fatalf("expected 2 fields and no methods, got %d fields and %d methods", len(output.App.Fields), len(output.App.Methods))
+	}
+	if _, ok := output.Structs["Kiosk"]; ok {
+		t.Fatal("did not expect the app struct to be listed in Structs")
+	}
+
+	output, err = introspectApp(mainPath, "Banner")
+	if err != nil {
+		t.Fatalf("introspectApp failed: %v", err)
+	}
+	if output.App.Name != "Banner" || len(output.App.Fields) != 1 {
+		t.Fatalf("expected --app-struct to select Banner, got %q with %d fields", output.App.Name, len(output.App.Fields))
+	}
+}
+
+func TestIntrospectStructCollectionTypes(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Address struct {
+	City string
+}
+
+type User struct {
+	Name string
+	Home Address
+}
+
+type Team struct {
+	Members []User
+}
+
+type App struct{}
+
+func (a *App) Save(users []User, byID map[string]User, owner *User, refs []*User) error { return nil }
+
+func (a *App) Load() ([]User, map[string]*User, *User) { return nil, nil, nil }
+
+func (a *App) Teams() map[string][]Team { return nil }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	methods := make(map[string]MethodDef)
+	for _, m := range output.App.Methods {
+		methods[m.Name] = m
+	}
+
+	var gotParams []string
+	for _, p := range methods["Save"].Params {
+		gotParams = append(gotParams, p.TSType)
+	}
+	if want := []string{"User[]", "Record<string, User>", "User", "User[]"}; strings.Join(gotParams, ";") != strings.Join(want, ";") {
+		t.Fatalf("expected Save params %q, got %q", want, gotParams)
+	}
+
+	var gotReturns []string
+	for _, r := range methods["Load"].ReturnTypes {
+		gotReturns = append(gotReturns, r.TSType)
+	}
+	if want := []string{"User[]", "Record<string, User>", "User"}; strings.Join(gotReturns, ";") != strings.Join(want, ";") {
+		t.Fatalf("expected Load returns %q, got %q", want, gotReturns)
+	}
+
+	if got := methods["Teams"].ReturnTypes[0].TSType; got != "Record<string, Team[]>" {
+		t.Fatalf("expected Teams return Record<string, Team[]>, got %q", got)
+	}
+
+	// Structs reached only through maps, slices and nested fields still get interfaces
+	used := findUsedStructs(output.App, output.Structs, output.Events)
+	if want := []string{"Address", "Team", "User"}; strings.Join(used, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected used structs %v, got %v", want, used)
+	}
+}
+
+func TestSummaryCountsAndWarnings(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Item struct {
+	Name  string
+	Extra interface{}
+}
+
+type App struct {
+	Title   string
+	Updates chan int
+}
+
+func (a *App) Items() []Item { return nil }
+
+func (a *App) Watch(done chan bool, tags map[string]string) error { return nil }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	summary := summarizeIntrospection(output)
+	if summary.Structs != 1 || summary.Methods != 2 || summary.Fields != 4 {
+		t.Fatalf("unexpected counts: %+v", summary)
+	}
+	want := []string{
+		"field App.Updates has unbindable type chan int",
+		"method App.Watch parameter done has unbindable type chan bool",
+	}
+	if strings.Join(summary.Warnings, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected warnings %q, got %q", want, summary.Warnings)
+	}
+}
+
+func TestSummaryAcceptsStreamReturns(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+type App struct {
+	Raw json.RawMessage
+}
+
+func (a *App) Export(ctx context.Context) (io.Reader, error) { return nil, nil }
+
+func (a *App) Lookup(keys []any) map[string]interface{} { return nil }
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	summary := summarizeIntrospection(output)
+	if len(summary.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %q", summary.Warnings)
+	}
+}
+
+func TestIntrospectChannelReturns(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "context"
+
+type Progress struct {
+	Percent int
+}
+
+type App struct{}
+
+func (a *App) Watch(ctx context.Context) (<-chan Progress, error) { return nil, nil }
+
+func (a *App) Ticks() chan int { return nil }
+
+func (a *App) Feed(ch chan<- string) {}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	methods := make(map[string]MethodDef)
+	for _, m := range output.App.Methods {
+		methods[m.Name] = m
+	}
+	if got := methods["Watch"].ReturnTypes[0].GoType; got != "<-chan Progress" {
+		t.Fatalf("expected Watch to return <-chan Progress, got %s", got)
+	}
+	if got := methods["Feed"].Params[0].GoType; got != "chan<- string" {
+		t.Fatalf("expected Feed to take chan<- string, got %s", got)
+	}
+	for _, name := range []string{"Watch", "Ticks"} {
+		if got := formatDTSReturnType(methods[name]); got != "Promise<void>" {
+			t.Fatalf("expected %s to return Promise<void>, got %s", name, got)
+		}
+	}
+
+	summary := summarizeIntrospection(output)
+	want := []string{"method App.Feed parameter ch has unbindable type chan<- string"}
+	if strings.Join(summary.Warnings, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected warnings %q, got %q", want, summary.Warnings)
+	}
+}
+
+func TestIntrospectNamedInterfaceFields(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import (
+	"io"
+
+	"github.com/strux-dev/strux/pkg/runtime"
+)
+
+type Storer interface {
+	io.Closer
+	Get(key string) (string, error)
+	Put(key string, value string) error
+	flush()
+}
+
+type Empty interface{}
+
+type App struct {
+	Store  Storer
+	Source io.Reader
+	Extra  interface{}
+	Blank  Empty
+}
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range output.App.Fields {
+		got = append(got, f.Name+":"+f.GoType+":"+f.TSType)
+	}
+	want := []string{"Store:Storer:any", "Source:io.Reader:any", "Extra:interface{}:any", "Blank:Empty:any"}
+	if strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Fatalf("expected fields %q, got %q", want, got)
+	}
+
+	storer, ok := output.Interfaces["Storer"]
+	if !ok {
+		t.Fatalf("expected Storer in interfaces, got %v", output.Interfaces)
+	}
+	if names := methodNames(storer.Methods); strings.Join(names, ",") != "Get,Put" {
+		t.Fatalf("expected Storer methods Get,Put, got %v", names)
+	}
+	if len(storer.Embeds) != 1 || storer.Embeds[0] != "io.Closer" {
+		t.Fatalf("expected Storer to embed io.Closer, got %v", storer.Embeds)
+	}
+	if _, ok := output.Interfaces["Empty"]; ok {
+		t.Fatal("did not expect the empty interface to be listed")
+	}
+}
+
+func TestIntrospectFireAndForgetMethods(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Metrics struct{}
+
+func (m *Metrics) Track(name string) error { return nil }
+
+type App struct {
+	Metrics Metrics
+}
+
+func (a *App) Log(line string) bool { return true }
+
+func (a *App) Ping() string { return "pong" }
+
+func (a *App) FireAndForget() []string {
+	return []string{"Log", "Metrics.Track"}
+}
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+	if got := methodNames(output.App.Methods); strings.Join(got, ",") != "Log,Ping" {
+		t.Fatalf("expected methods Log,Ping, got %v", got)
+	}
+	for _, m := range output.App.Methods {
+		if m.FireAndForget != (m.Name == "Log") {
+			t.Fatalf("unexpected fireAndForget on %s: %v", m.Name, m.FireAndForget)
+		}
+	}
+	track := output.Structs["Metrics"].Methods[0]
+	if !track.FireAndForget {
+		t.Fatal("expected Metrics.Track to be fire-and-forget")
+	}
+	if got := formatDTSReturnType(track); got != "Promise<void>" {
+		t.Fatalf("expected Promise<void>, got %s", got)
+	}
+}
+
+func TestIntrospectFieldGroups(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Settings struct {
+	Theme string
+}
+
+type App struct {
+	Volume   int      `+"`strux:\"group=settings\"`"+`
+	Muted    bool     `+"`strux:\"group=settings\"`"+`
+	Input    string   `+"`strux:\"group=audio\"`"+`
+	Status   string
+	Defaults Settings
+}
+
+func (a *App) GetAudio() string { return a.Input }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	// The app's own GetAudio wins over the generated accessor
+	if got := methodNames(output.App.Methods); strings.Join(got, ",") != "GetAudio,GetSettings,SetAudio,SetSettings" {
+		t.Fatalf("expected methods GetAudio,GetSettings,SetAudio,SetSettings, got %v", got)
+	}
+
+	// Settings is taken by an app struct, so the group interface is renamed
+	group, ok := output.Structs["SettingsGroup"]
+	if !ok {
+		t.Fatalf("expected a SettingsGroup interface, got structs %v", reflect.ValueOf(output.Structs).MapKeys())
+	}
+	var fields []string
+	for _, f := range group.Fields {
+		fields = append(fields, f.Name)
+	}
+	if strings.Join(fields, ",") != "Volume,Muted" {
+		t.Fatalf("expected SettingsGroup fields Volume,Muted, got %v", fields)
+	}
+	if _, ok := output.Structs["Audio"]; !ok {
+		t.Fatal("expected an Audio interface")
+	}
+
+	for _, m := range output.App.Methods {
+		switch m.Name {
+		case "GetSettings":
+			if len(m.ReturnTypes) != 1 || m.ReturnTypes[0].TSType != "SettingsGroup" || !m.HasError {
+				t.Fatalf("expected GetSettings to return SettingsGroup and an error, got %+v", m)
+			}
+		case "SetSettings":
+			if len(m.Params) != 1 || m.Params[0].TSType != "Partial<SettingsGroup>" {
+				t.Fatalf("expected SetSettings to take Partial<SettingsGroup>, got %+v", m.Params)
+			}
+			if got := formatDTSReturnType(m); got != "Promise<void>" {
+				t.Fatalf("expected SetSettings to return Promise<void>, got %s", got)
+			}
+		case "GetAudio":
+			if got := formatDTSReturnType(m); got != "Promise<string>" {
+				t.Fatalf("expected the app's GetAudio to return Promise<string>, got %s", got)
+			}
+		}
+	}
+}
+
+func TestIntrospectNamedReturns(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+type App struct{}
+
+func (a *App) Count() (count int) { return 0 }
+
+func (a *App) Point() (x, y, _ int, err error) { return 0, 0, 0, nil }
+
+func (a *App) Stats() (int, int, error) { return 0, 0, nil }
+
+func (a *App) Pair() (string, string) { return "", "" }
+
+func (a *App) ReturnNames() map[string][]string {
+	return map[string][]string{
+		"Stats": {"count", "total"},
+		"Pair":  {"first"},
+	}
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+	if got := methodNames(output.App.Methods); strings.Join(got, ",") != "Count,Pair,Point,Stats" {
+		t.Fatalf("expected methods Count,Pair,Point,Stats, got %v", got)
+	}
+
+	methods := make(map[string]MethodDef)
+	for _, m := range output.App.Methods {
+		methods[m.Name] = m
+	}
+	resultNames := func(m MethodDef) []string {
+		names := make([]string, 0, len(m.ReturnTypes))
+		for _, returnType := range m.ReturnTypes {
+			names = append(names, returnType.Name)
+		}
+		return names
+	}
+
+	if got := resultNames(methods["Count"]); !reflect.DeepEqual(got, []string{"count"}) {
+		t.Fatalf("unexpected Count result names %v", got)
+	}
+	if got := resultNames(methods["Point"]); !reflect.DeepEqual(got, []string{"x", "y", ""}) {
+		t.Fatalf("unexpected Point result names %v", got)
+	}
+	// Signature names alone do not change the shape the runtime returns
+	if got := formatDTSReturnType(methods["Point"]); got != "Promise<[number, number, number] | null>" {
+		t.Fatalf("unexpected Point return type %s", got)
+	}
+
+	stats := methods["Stats"]
+	if !stats.NamedResults || !reflect.DeepEqual(resultNames(stats), []string{"count", "total"}) {
+		t.Fatalf("expected Stats to have named results, got %+v", stats)
+	}
+	if got := formatDTSReturnType(stats); got != "Promise<{ count: number; total: number } | null>" {
+		t.Fatalf("unexpected Stats return type %s", got)
+	}
+	if methods["Pair"].NamedResults {
+		t.Fatal("expected ReturnNames with the wrong count to be ignored")
+	}
+}
+
+func TestIntrospectSkipsStartupAndShutdownHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "context"
+
+type Power struct{}
+
+func (p *Power) Shutdown() string { return "off" }
+
+type App struct {
+	Power Power
+}
+
+func (a *App) Startup(ctx context.Context) error { return nil }
+
+func (a *App) Shutdown() error { return nil }
+
+func (a *App) Ping() string { return "pong" }
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+	if got := methodNames(output.App.Methods); strings.Join(got, ",") != "Ping" {
+		t.Fatalf("expected methods Ping, got %v", got)
+	}
+	if got := methodNames(output.Structs["Power"].Methods); strings.Join(got, ",") != "Shutdown" {
+		t.Fatalf("expected nested Shutdown to stay bound, got %v", got)
+	}
+
+	// Without the hook signatures the runtime binds them as ordinary methods
+	otherDir := t.TempDir()
+	mainPath = writeFixture(t, otherDir, "main.go", `package main
+
+import "context"
+
+type App struct{}
+
+func (a *App) Startup() error { return nil }
+
+func (a *App) Shutdown(ctx context.Context) error { return nil }
+`)
+
+	output, err = introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+	if got := methodNames(output.App.Methods); strings.Join(got, ",") != "Shutdown,Startup" {
+		t.Fatalf("expected Startup() and Shutdown(ctx) to stay bound, got %v", got)
+	}
+}
+
+func TestIntrospectOptionsStructParam(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type SearchOptions struct {
+	Query string
+	Limit int `+"`strux:\"default=20\"`"+`
+}
+
+type App struct{}
+
+func (a *App) Search(opts SearchOptions) []string { return nil }
+
+func (a *App) Save(opts SearchOptions, force bool) error { return nil }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	methods := make(map[string]MethodDef)
+	for _, m := range output.App.Methods {
+		methods[m.Name] = m
+	}
+	if got := formatDTSParams(methods["Search"].Params); got != "opts?: Partial<SearchOptions>" {
+		t.Fatalf("expected an optional partial options param, got %q", got)
+	}
+	if got := formatDTSParams(methods["Save"].Params); got != "opts: SearchOptions, force: boolean" {
+		t.Fatalf("expected Save params to be unchanged, got %q", got)
+	}
+
+	fields := output.Structs["SearchOptions"].Fields
+	if len(fields) != 2 || fields[1].Default != "20" {
+		t.Fatalf("expected Limit default 20, got %+v", fields)
+	}
+	if got := formatDTSField(fields[1]); got != "  Limit: number; // default: 20" {
+		t.Fatalf("unexpected field line %q", got)
+	}
+}
+
+func TestIntrospectSkipsInjectedParams(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import (
+	"context"
+
+	"github.com/strux-dev/strux/pkg/runtime"
+)
+
+type App struct{}
+
+func (a *App) Login(s *runtime.Session, user string) error { return nil }
+
+func (a *App) Report(ctx context.Context, s *runtime.Session, id string) error { return nil }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	methods := make(map[string]MethodDef)
+	for _, m := range output.App.Methods {
+		methods[m.Name] = m
+	}
+	if got := formatDTSParams(methods["Login"].Params); got != "user: string" {
+		t.Fatalf("expected the session param to be skipped, got %q", got)
+	}
+	if got := formatDTSParams(methods["Report"].Params); got != "id: string" {
+		t.Fatalf("expected the context and session params to be skipped, got %q", got)
+	}
+}
+
+func TestIntrospectFlattensEmbeddedStructs(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type BaseModel struct {
+	ID      string
+	Name    string
+	created int64
+}
+
+type Audit struct {
+	UpdatedBy string
+}
+
+type Device struct {
+	*BaseModel
+	Audit
+	Serial string
+}
+
+type App struct {
+	BaseModel
+	Name    string
+	Devices []Device
+}
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	fieldNames := func(fields []FieldDef) []string {
+		names := make([]string, 0, len(fields))
+		for _, f := range fields {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	if got := strings.Join(fieldNames(output.App.Fields), ","); got != "ID,Name,Devices" {
+		t.Fatalf("expected embedded fields promoted with Name shadowed, got %s", got)
+	}
+	for _, f := range output.App.Fields {
+		if f.Name == "Name" && f.GoType != "string" {
+			t.Fatalf("expected the App's own Name field, got %+v", f)
+		}
+	}
+	if got := strings.Join(fieldNames(output.Structs["Device"].Fields), ","); got != "ID,Name,UpdatedBy,Serial" {
+		t.Fatalf("expected pointer and value embeds promoted, got %s", got)
+	}
+}
+
+func TestIntrospectUsesJSONTags(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type User struct {
+	UserName string `+"`json:\"user_name\"`"+`
+	Email    string `+"`json:\"email,omitempty\"`"+`
+	Nickname string `+"`json:\",omitempty\"`"+`
+	Password string `+"`json:\"-\"`"+`
+	Age      int
+	Visits   int64 `+"`json:\"visits,string\"`"+`
+}
+
+type App struct {
+	Secret string `+"`json:\"-\"`"+`
+}
+
+func (a *App) CurrentUser() User { return User{} }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	var lines []string
+	for _, field := range output.Structs["User"].Fields {
+		lines = append(lines, formatDTSField(field))
+	}
+	expected := []string{
+		"  user_name: string;",
+		"  email?: string;",
+		"  Nickname?: string;",
+		"  Age: number;",
+		"  visits: string;",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected fields named by their json tags, got:\n%s", strings.Join(lines, "\n"))
+	}
+	if field := output.Structs["User"].Fields[0]; field.Name != "UserName" || field.JSONName != "user_name" {
+		t.Fatalf("expected both the Go and JSON names, got %+v", field)
+	}
+
+	if len(output.App.Fields) != 1 || output.App.Fields[0].Name != "Secret" {
+		t.Fatalf("expected app fields to keep their Go binding, got %+v", output.App.Fields)
+	}
+}
+
+func TestBuildInfoFlags(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+var version = "dev"
+
+type App struct{}
+
+func (a *App) Ping() string { return "pong" }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	opts, err := parseArgs([]string{"--runtime-dts", "--app-version", "1.4.0", "--build-meta", "commit=abc123", "--build-meta", "date=2026-01-02", mainPath})
+	if err != nil {
+		t.Fatalf("parseArgs failed: %v", err)
+	}
+	if opts.appVersion != "1.4.0" || opts.buildMeta["commit"] != "abc123" || opts.buildMeta["date"] != "2026-01-02" {
+		t.Fatalf("unexpected build info options: %+v", opts)
+	}
+
+	dts, err := generateDTS(opts)
+	if err != nil {
+		t.Fatalf("generateDTS failed: %v", err)
+	}
+	expected := "// App version: 1.4.0\n// Build commit: abc123\n// Build date: 2026-01-02\n"
+	if !strings.Contains(dts, expected) {
+		t.Fatalf("expected %q in the definitions header, got:\n%s", expected, dts)
+	}
+
+	if _, err := parseArgs([]string{"--build-meta", "commit"}); err == nil {
+		t.Fatalf("expected an error for --build-meta without a value")
+	}
+}
+
+func TestQualifyFlag(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "time"
+
+type Mode string
+
+type Track struct {
+	Title   string
+	Mode    Mode
+	Started time.Time
+}
+
+type App struct {
+	Tracks map[string][]*Track
+}
+
+func (a *App) Play(track Track) (*Track, error) { return &track, nil }
+`)
+
+	opts, err := parseArgs([]string{"--qualify", mainPath})
+	if err != nil {
+		t.Fatalf("parseArgs failed: %v", err)
+	}
+	if !opts.qualify {
+		t.Fatalf("expected --qualify to be set: %+v", opts)
+	}
+
+	output, err := introspectApp(mainPath, "")
+	if err != nil {
+		t.Fatalf("introspectApp failed: %v", err)
+	}
+	qualifyGoTypes(&output)
+
+	field := output.App.Fields[0]
+	if field.GoType != "map[string][]*main.Track" || field.TSType != "Record<string, Track[]>" {
+		t.Fatalf("unexpected field types %+v", field)
+	}
+	method := output.App.Methods[0]
+	if method.Params[0].GoType != "main.Track" || method.ReturnTypes[0].GoType != "*main.Track" {
+		t.Fatalf("unexpected method types %+v", method)
+	}
+	var got []string
+	for _, f := range output.Structs["Track"].Fields {
+		got = append(got, f.GoType+"="+f.TSType)
+	}
+	expected := "string=string main.Mode=string time.Time=string"
+	if strings.Join(got, " ") != expected {
+		t.Fatalf("expected Track fields %q, got %q", expected, strings.Join(got, " "))
+	}
+}
+
+func TestIntrospectPackageDirectory(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFixture(t, tempDir, "app.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type App struct {
+	Title string
+}
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+	writeFixture(t, tempDir, "handlers.go", `package main
+
+func (a *App) Greet(name string) string { return "Hello " + name }
+`)
+	writeFixture(t, tempDir, "types.go", `package main
+
+type Greeting struct {
+	Text string
+}
+
+func (a *App) Latest() Greeting { return Greeting{} }
+`)
+	writeFixture(t, tempDir, "app_test.go", `package main
+
+type testFixture struct{}
+
+func (a *App) TestOnly() {}
+`)
+
+	for _, target := range []string{tempDir, filepath.Join(tempDir, "...")} {
+		output, err := introspectData(target)
+		if err != nil {
+			t.Fatalf("introspectData(%s) failed: %v", target, err)
+		}
+		if output.App.Name != "App" || output.App.PackageName != "main" {
+			t.Fatalf("expected the main package's App, got %s in %s", output.App.Name, output.App.PackageName)
+		}
+		if got := strings.Join(methodNames(output.App.Methods), ","); got != "Greet,Latest" {
+			t.Fatalf("expected methods from every non-test file, got %s", got)
+		}
+		if _, ok := output.Structs["Greeting"]; !ok {
+			t.Fatalf("expected the Greeting struct from types.go, got %v", output.Structs)
+		}
+		if _, ok := output.Structs["testFixture"]; ok {
+			t.Fatalf("expected test files to be skipped")
+		}
+	}
+}
+
+func TestIntrospectAppStructMarker(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Helper struct{}
+
+func (h *Helper) Assist() {}
+
+type App struct{}
+
+func (a *App) Ping() string { return "pong" }
+
+// Kiosk is the real app.
+//
+//strux:app
+type Kiosk struct{}
+
+func (k *Kiosk) Show(page string) {}
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+	if output.App.Name != "Kiosk" {
+		t.Fatalf("expected the marked struct to be the app, got %s", output.App.Name)
+	}
+
+	writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Helper struct{}
+
+func (h *Helper) Assist() {}
+
+type Kiosk struct{}
+
+func (k *Kiosk) Show(page string) {}
+
+func main() {
+	rt := runtime.New(&Kiosk{})
+	_ = rt
+}
+`)
+	output, err = introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+	if output.App.Name != "Kiosk" {
+		t.Fatalf("expected the struct passed to runtime.New to be the app, got %s", output.App.Name)
+	}
+}
+
+func TestIntrospectCrossFileReturnTypes(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type App struct{}
+
+func (a *App) Latest() (*Report, error) { return nil, nil }
+
+func (a *App) All() Reports { return nil }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+	writeFixture(t, tempDir, "report.go", `package main
+
+type Report struct {
+	Title string
+	Lines []Line
+}
+
+type Reports []*Report
+`)
+	writeFixture(t, tempDir, "line.go", `package main
+
+type Line struct {
+	Text string
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	returns := make(map[string]string)
+	for _, m := range output.App.Methods {
+		returns[m.Name] = formatDTSReturnType(m)
+	}
+	if returns["Latest"] != "Promise<Report | null>" || returns["All"] != "Promise<Report[]>" {
+		t.Fatalf("expected return types resolved across files, got %v", returns)
+	}
+
+	dts := generateTypeScriptDefinitions(output, emptyRuntimeTypes())
+	for _, name := range []string{"interface Report {", "interface Line {"} {
+		if !strings.Contains(dts, name) {
+			t.Fatalf("expected %q in the definitions, got:\n%s", name, dts)
+		}
+	}
+}
+
+func TestIntrospectStdlibTypes(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/strux-dev/strux/pkg/runtime"
+)
+
+type Sample struct {
+	At      time.Time
+	Took    time.Duration
+	Payload []byte
+	Extra   json.RawMessage
+}
+
+type App struct{}
+
+func (a *App) Latest(since time.Time) (*Sample, error) { return nil, nil }
+
+func (a *App) History() []time.Time { return nil }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	var fields []string
+	for _, field := range output.Structs["Sample"].Fields {
+		fields = append(fields, formatDTSField(field))
+	}
+	expected := []string{"  At: string;", "  Took: number;", "  Payload: string;", "  Extra: any;"}
+	if strings.Join(fields, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected stdlib types mapped to their JSON encoding, got:\n%s", strings.Join(fields, "\n"))
+	}
+
+	methods := make(map[string]MethodDef)
+	for _, m := range output.App.Methods {
+		methods[m.Name] = m
+	}
+	if got := formatDTSParams(methods["Latest"].Params); got != "since: string" {
+		t.Fatalf("expected a string timestamp param, got %q", got)
+	}
+	if got := formatDTSReturnType(methods["History"]); got != "Promise<string[]>" {
+		t.Fatalf("expected a timestamp array, got %q", got)
+	}
+}
+
+func TestIntrospectRegisteredEvents(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Progress struct {
+	Percent int    `+"`json:\"percent\"`"+`
+	File    string `+"`json:\"file,omitempty\"`"+`
+}
+
+type App struct{}
+
+func (a *App) Ping() string { return "pong" }
+
+func main() {
+	rt, _ := runtime.Init(&App{})
+	rt.RegisterEvent("download:progress", Progress{})
+	rt.RegisterEvent("download:done", nil)
+	rt.RegisterEvent("status", "")
+	rt.RegisterEvent("raw", makePayload())
+	select {}
+}
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	var events []string
+	for _, event := range output.Events {
+		events = append(events, event.Name+"="+event.TSType)
+	}
+	expected := "download:done=void download:progress=Progress raw=any status=string"
+	if strings.Join(events, " ") != expected {
+		t.Fatalf("expected events %q, got %q", expected, strings.Join(events, " "))
+	}
+
+	dts := generateTypeScriptDefinitions(output, emptyRuntimeTypes())
+	for _, want := range []string{
+		`    "download:progress": Progress;`,
+		`  interface Progress {`,
+		`    file?: string;`,
+	} {
+		if !strings.Contains(dts, want) {
+			t.Fatalf("expected %q in definitions:\n%s", want, dts)
+		}
+	}
+}
+
+func TestIntrospectConstants(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+type Channel string
+
+const (
+	MaxVolume          = 100
+	MinOffset          = -0.5
+	Release    Channel = "stable"
+	Debug              = false
+	ReleaseURL         = `+"`https://example.com/releases`"+`
+	Timeout            = MaxVolume * 2
+	internalLimit      = 10
+)
+
+const (
+	ModeA = iota
+	ModeB
+)
+
+type App struct{}
+
+func (a *App) Ping() string { return "pong" }
+`)
+
+	output, err := introspectData(mainPath)
+	if err != nil {
+		t.Fatalf("introspectData failed: %v", err)
+	}
+
+	expected := map[string]ConstantDef{
+		"MaxVolume":  {GoType: "int", TSType: "number", Value: int64(100)},
+		"MinOffset":  {GoType: "float64", TSType: "number", Value: -0.5},
+		"Release":    {GoType: "Channel", TSType: "string", Value: "stable"},
+		"Debug":      {GoType: "bool", TSType: "boolean", Value: false},
+		"ReleaseURL": {GoType: "string", TSType: "string", Value: "https://example.com/releases"},
+	}
+	if !reflect.DeepEqual(output.Constants, expected) {
+		t.Fatalf("expected constants %+v, got %+v", expected, output.Constants)
+	}
+}
+
+func TestEmitDTSWritesNextToInput(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := writeFixture(t, tempDir, "main.go", `package main
+
+import "github.com/strux-dev/strux/pkg/runtime"
+
+type Status struct {
+	Online bool
+}
+
+type App struct {
+	Title string
+}
+
+func (a *App) Status() (Status, error) { return Status{}, nil }
+
+func main() {
+	runtime.Start(&App{})
+}
+`)
+
+	opts, err := parseArgs([]string{"--emit=dts", mainPath})
+	if err != nil {
+		t.Fatalf("parseArgs failed: %v", err)
+	}
+	path, err := emitDTSFile(opts)
+	if err != nil {
+		t.Fatalf("emitDTSFile failed: %v", err)
+	}
+	if path != filepath.Join(tempDir, "strux.d.ts") {
+		t.Fatalf("expected strux.d.ts next to the input, got %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for _, expected := range []string{"interface Status {\n    Online: boolean;\n  }", "    Title: string;", "    Status(): Promise<Status | null>;"} {
+		if !strings.Contains(string(data), expected) {
+			t.Fatalf("expected %q in the definitions, got:\n%s", expected, data)
+		}
+	}
+
+	outPath := filepath.Join(tempDir, "types", "app.d.ts")
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	opts, err = parseArgs([]string{"--emit=dts", "--out", outPath, mainPath})
+	if err != nil {
+		t.Fatalf("parseArgs failed: %v", err)
+	}
+	if path, err := emitDTSFile(opts); err != nil || path != outPath {
+		t.Fatalf("expected the definitions written to %s, got %s (%v)", outPath, path, err)
+	}
+
+	if _, err := parseArgs([]string{"--emit=yaml"}); err == nil {
+		t.Fatalf("expected an error for an unknown --emit format")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// introspectionSummary counts what the introspector found and lists the types
+// it could not map to TypeScript.
+type introspectionSummary struct {
+	Structs  int
+	Methods  int
+	Fields   int
+	Warnings []string
+}
+
+// runSummary introspects the app and prints counts and warnings instead of the
+// JSON output. It returns the number of warnings so the caller can fail CI.
+func runSummary(filePath string, appStruct string, w io.Writer) (int, error) {
+	output, err := introspectApp(filePath, appStruct)
+	if err != nil {
+		return 0, err
+	}
+
+	summary := summarizeIntrospection(output)
+	fmt.Fprintf(w, "%d structs, %d methods, %d fields, %d warnings\n",
+		summary.Structs, summary.Methods, summary.Fields, len(summary.Warnings))
+	for _, warning := range summary.Warnings {
+		fmt.Fprintf(w, "warning: %s\n", warning)
+	}
+	return len(summary.Warnings), nil
+}
+
+// summarizeIntrospection counts structs, methods and fields (app included) and
+// warns about every field, parameter and return type that is unbindable.
+// Warnings are ordered app first, then structs by name.
+func summarizeIntrospection(output IntrospectionOutput) introspectionSummary {
+	summary := introspectionSummary{Structs: len(output.Structs)}
+	addScope := func(scope string, fields []FieldDef, methods []MethodDef) {
+		summary.Fields += len(fields)
+		summary.Methods += len(methods)
+		for _, field := range fields {
+			if unbindableType(field.GoType, field.TSType) {
+				summary.Warnings = append(summary.Warnings, fmt.Sprintf("field %s.%s has unbindable type %s", scope, field.Name, field.GoType))
+			}
+		}
+		for _, method := range methods {
+			for _, param := range method.Params {
+				if unbindableType(param.GoType, param.TSType) {
+					summary.Warnings = append(summary.Warnings, fmt.Sprintf("method %s.%s parameter %s has unbindable type %s", scope, method.Name, param.Name, param.GoType))
+				}
+			}
+			for i, ret := range method.ReturnTypes {
+				if unbindableType(ret.GoType, ret.TSType) {
+					summary.Warnings = append(summary.Warnings, fmt.Sprintf("method %s.%s return %d has unbindable type %s", scope, method.Name, i, ret.GoType))
+				}
+			}
+		}
+	}
+
+	addScope(output.App.Name, output.App.Fields, output.App.Methods)
+
+	names := make([]string, 0, len(output.Structs))
+	for name := range output.Structs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		addScope(name, output.Structs[name].Fields, output.Structs[name].Methods)
+	}
+	return summary
+}
+
+// anyPattern matches the any type in Go and TypeScript type expressions
+var anyPattern = regexp.MustCompile(`\bany\b`)
+
+// unbindableType reports whether the resolved TypeScript type of a field,
+// parameter or result falls back to any anywhere the Go type doesn't ask for
+// it (interface{}, any or json.RawMessage), e.g. for funcs and types from
+// other packages. Types the introspector maps specially, such as streamed
+// io.Reader results, are judged by what they resolved to.
+func unbindableType(goType string, tsType string) bool {
+	declared := strings.Count(goType, "interface{}") + strings.Count(goType, "json.RawMessage") + len(anyPattern.FindAllString(goType, -1))
+	return len(anyPattern.FindAllString(tsType, -1)) > declared
+}
@@ -1,6 +1,8 @@
 package runtime
 
 import (
+	"fmt"
+
 	"github.com/strux-dev/strux/pkg/runtime/api"
 )
 
@@ -75,18 +77,24 @@ func RegisterWiFiProvider(provider WiFiProvider) {
 func (rt *Runtime) registerBuiltinExtensions() {
 
 	// Define New Extensions Here ------------------------------------------------
-	rt.registerStruxAPI(api.BootNamespace, rt.Boot())
-	rt.registerStruxAPI(api.DevNamespace, rt.Dev())
-	rt.registerStruxAPI(api.DisplayNamespace, rt.Display())
-	rt.registerStruxAPI(api.NetworkNamespace, rt.Network())
-	rt.registerStruxAPI(api.ProjectNamespace, rt.Project())
-	rt.registerStruxAPI(api.SystemNamespace, rt.System())
-	rt.registerStruxAPI(api.UpdateNamespace, rt.Update())
-	rt.registerStruxAPI(api.WiFiNamespace, rt.WiFi())
-	rt.registerStruxAPI(api.CapabilitiesNamespace, rt.Capabilities())
+	builtins := []ExtensionEntry{
+		struxAPI(api.BootNamespace, rt.Boot()),
+		struxAPI(api.DevNamespace, rt.Dev()),
+		struxAPI(api.DisplayNamespace, rt.Display()),
+		struxAPI(api.NetworkNamespace, rt.Network()),
+		struxAPI(api.ProjectNamespace, rt.Project()),
+		struxAPI(api.SystemNamespace, rt.System()),
+		struxAPI(api.UpdateNamespace, rt.Update()),
+		struxAPI(api.WiFiNamespace, rt.WiFi()),
+		struxAPI(api.CapabilitiesNamespace, rt.Capabilities()),
+	}
 
 	// ----------------------------------------------------------------------------
 
+	if err := rt.extensions.RegisterAll(builtins); err != nil {
+		rt.recordRegistrationError(fmt.Errorf("built-in extensions: %w", err))
+	}
+
 	// DO NOT REMOVE ------------------------------------------------------------
 	// Replay custom BSP extension registrations captured from package init() hooks.
 	rt.registerProcessExtensions()
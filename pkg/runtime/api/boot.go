@@ -3,53 +3,49 @@ package api
 import (
 	"errors"
 	"fmt"
-	"net"
-	"os"
 	"os/exec"
 	"strings"
 	"syscall"
-	"time"
 )
 
 const BootNamespace = "boot"
 
 // BootService provides boot and system management methods.
-type BootService struct{}
+type BootService struct {
+	// appDataDir, devConfigDir and reboot override FactoryReset's targets (used in tests).
+	appDataDir   string
+	devConfigDir string
+	reboot       func() error
 
-// HideSplash communicates with Cage to hide the splash screen.
+	// cage overrides the Cage control socket client (used in tests).
+	cage *CageControl
+}
+
+// HideSplash communicates with Cage to hide the splash screen. It returns once
+// Cage confirms the splash is hidden, so the frontend can start its transition.
 func (b *BootService) HideSplash() error {
-	socketPath := "/tmp/strux-cage-control.sock"
+	cage := b.cageControl()
 
-	fmt.Printf("Strux Boot: HideSplash() called, connecting to %s\n", socketPath)
+	fmt.Printf("Strux Boot: HideSplash() called, connecting to %s\n", cage.SocketPath)
 
-	conn, err := net.Dial("unix", socketPath)
-	if err != nil {
-		fmt.Printf("Strux Boot: Failed to connect: %v\n", err)
-		if os.IsNotExist(err) || isConnectionRefused(err) {
+	if err := cage.Send("HIDE_SPLASH"); err != nil {
+		if errors.Is(err, ErrCageControlUnavailable) {
 			fmt.Printf("Strux Boot: Socket not found or refused, returning nil (dev mode?)\n")
 			return nil
 		}
-		return fmt.Errorf("failed to connect to Cage control socket: %w", err)
+		fmt.Printf("Strux Boot: Failed to hide splash: %v\n", err)
+		return err
 	}
-	defer conn.Close()
-
-	if uc, ok := conn.(*net.UnixConn); ok {
-		_ = uc.SetDeadline(time.Now().Add(2 * time.Second))
-	}
-
-	fmt.Printf("Strux Boot: Connected, sending HIDE_SPLASH command\n")
 
-	if _, err = conn.Write([]byte("HIDE_SPLASH")); err != nil {
-		fmt.Printf("Strux Boot: Failed to send: %v\n", err)
-		return fmt.Errorf("failed to send hide splash command: %w", err)
-	}
+	fmt.Printf("Strux Boot: Splash hidden\n")
+	return nil
+}
 
-	if uc, ok := conn.(*net.UnixConn); ok {
-		_ = uc.CloseWrite()
+func (b *BootService) cageControl() *CageControl {
+	if b.cage != nil {
+		return b.cage
 	}
-
-	fmt.Printf("Strux Boot: HIDE_SPLASH command sent successfully\n")
-	return nil
+	return NewCageControl()
 }
 
 func isConnectionRefused(err error) bool {
@@ -65,14 +61,31 @@ func isConnectionRefused(err error) bool {
 		strings.Contains(errStr, "no such file or directory")
 }
 
-// Reboot reboots the system.
+// Reboot reboots the system, falling back to the reboot command if systemctl
+// fails.
 func (b *BootService) Reboot() error {
-	cmd := exec.Command("reboot")
-	return cmd.Run()
+	if err := runWithFallback([]string{"systemctl", "reboot"}, []string{"reboot"}); err != nil {
+		return fmt.Errorf("failed to reboot: %w", err)
+	}
+	return nil
 }
 
-// Shutdown shuts down the system.
+// Shutdown shuts down the system, falling back to the poweroff command if
+// systemctl fails.
 func (b *BootService) Shutdown() error {
-	cmd := exec.Command("poweroff")
-	return cmd.Run()
+	if err := runWithFallback([]string{"systemctl", "poweroff"}, []string{"poweroff"}); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+// runWithFallback runs primary and, if it fails, fallback. The fallback's
+// error is returned if both fail.
+func runWithFallback(primary, fallback []string) error {
+	err := exec.Command(primary[0], primary[1:]...).Run()
+	if err == nil {
+		return nil
+	}
+	fmt.Printf("Strux Boot: %s failed (%v), trying %s\n", strings.Join(primary, " "), err, strings.Join(fallback, " "))
+	return exec.Command(fallback[0], fallback[1:]...).Run()
 }
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// AppDataDir is the writable directory apps keep persistent state in. It
+	// is created at boot and is the only app data FactoryReset erases.
+	AppDataDir = "/strux-data/app"
+
+	// factoryResetTokenTTL is how long a token from RequestFactoryReset stays valid.
+	factoryResetTokenTTL = 30 * time.Second
+)
+
+// ErrInvalidFactoryResetToken is returned when FactoryReset is called without a
+// valid, unexpired token from RequestFactoryReset.
+var ErrInvalidFactoryResetToken = errors.New("invalid or expired factory reset token")
+
+// factoryResetToken is shared by every BootService value, since the runtime hands
+// out a fresh service per call.
+var factoryResetToken struct {
+	mu      sync.Mutex
+	value   string
+	expires time.Time
+}
+
+// RequestFactoryReset returns a one-time confirmation token that must be passed
+// to FactoryReset within 30 seconds. Requesting a new token invalidates the
+// previous one.
+func (b *BootService) RequestFactoryReset() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate factory reset token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	factoryResetToken.mu.Lock()
+	factoryResetToken.value = token
+	factoryResetToken.expires = time.Now().Add(factoryResetTokenTTL)
+	factoryResetToken.mu.Unlock()
+
+	return token, nil
+}
+
+// consumeFactoryResetToken checks token against the pending one and clears it
+// so it cannot be reused.
+func consumeFactoryResetToken(token string) bool {
+	factoryResetToken.mu.Lock()
+	defer factoryResetToken.mu.Unlock()
+
+	pending := factoryResetToken.value
+	valid := pending != "" &&
+		time.Now().Before(factoryResetToken.expires) &&
+		subtle.ConstantTimeCompare([]byte(pending), []byte(token)) == 1
+	if valid {
+		factoryResetToken.value = ""
+	}
+	return valid
+}
+
+// FactoryReset wipes device state and reboots. token must come from
+// RequestFactoryReset.
+//
+// It erases everything inside the app data directory (/strux-data/app) and,
+// when includeDevConfig is true, the stored dev-mode config
+// (/strux/.dev-env.json and its disabled copy).
+//
+// It does not touch the OS image or rootfs slots, the app binary or frontend
+// under /strux, or the A/B update and boot state under /strux-data/strux.
+func (b *BootService) FactoryReset(token string, includeDevConfig bool) error {
+	if !consumeFactoryResetToken(token) {
+		return ErrInvalidFactoryResetToken
+	}
+
+	fmt.Printf("Strux Boot: Factory reset requested (includeDevConfig=%v)\n", includeDevConfig)
+
+	if err := clearDirectory(b.dataDir()); err != nil {
+		return fmt.Errorf("failed to erase app data: %w", err)
+	}
+
+	if includeDevConfig {
+		for _, path := range []string{defaultDevConfigPath, defaultDisabledConfigPath} {
+			if b.devConfigDir != "" {
+				path = filepath.Join(b.devConfigDir, filepath.Base(path))
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove dev config: %w", err)
+			}
+		}
+	}
+
+	fmt.Printf("Strux Boot: Factory reset complete, rebooting\n")
+
+	if b.reboot != nil {
+		return b.reboot()
+	}
+	return b.Reboot()
+}
+
+// clearDirectory removes everything inside dir but keeps dir itself, so mount
+// points stay intact. A missing directory is treated as already empty.
+func clearDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *BootService) dataDir() string {
+	if b.appDataDir != "" {
+		return b.appDataDir
+	}
+	return AppDataDir
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFactoryResetRequiresTokenAndClearsAppData(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "app")
+	if err := os.MkdirAll(filepath.Join(dataDir, "cache"), 0755); err != nil {
+		t.Fatalf("failed to create data dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "settings.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write app data: %v", err)
+	}
+	devConfig := filepath.Join(tempDir, ".dev-env.json")
+	if err := os.WriteFile(devConfig, []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write dev config: %v", err)
+	}
+
+	rebooted := 0
+	boot := &BootService{
+		appDataDir:   dataDir,
+		devConfigDir: tempDir,
+		reboot:       func() error { rebooted++; return nil },
+	}
+
+	if err := boot.FactoryReset("not-a-token", true); !errors.Is(err, ErrInvalidFactoryResetToken) {
+		t.Fatalf("expected ErrInvalidFactoryResetToken, got %v", err)
+	}
+	if rebooted != 0 || !fileExists(filepath.Join(dataDir, "settings.json")) {
+		t.Fatal("factory reset ran without a valid token")
+	}
+
+	token, err := boot.RequestFactoryReset()
+	if err != nil {
+		t.Fatalf("RequestFactoryReset failed: %v", err)
+	}
+	if err := boot.FactoryReset(token, true); err != nil {
+		t.Fatalf("FactoryReset failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		t.Fatalf("expected data dir to remain: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected data dir to be empty, found %d entries", len(entries))
+	}
+	if fileExists(devConfig) {
+		t.Fatal("expected dev config to be removed")
+	}
+	if rebooted != 1 {
+		t.Fatalf("expected one reboot, got %d", rebooted)
+	}
+
+	if err := boot.FactoryReset(token, false); !errors.Is(err, ErrInvalidFactoryResetToken) {
+		t.Fatalf("expected token reuse to fail, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunWithFallbackWhenPrimaryIsMissing(t *testing.T) {
+	// Only the fallback is on PATH, as on images without systemd
+	binDir := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "rebooted")
+	script := "#!/bin/sh\necho \"$@\" > " + marker + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "reboot"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fallback stub: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	if err := runWithFallback([]string{"systemctl", "reboot"}, []string{"reboot", "-f"}); err != nil {
+		t.Fatalf("runWithFallback failed: %v", err)
+	}
+	args, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("expected the fallback to run: %v", err)
+	}
+	if string(args) != "-f\n" {
+		t.Fatalf("expected the fallback to get its arguments, got %q", args)
+	}
+
+	// With neither command available the fallback's error is returned
+	if err := runWithFallback([]string{"systemctl", "poweroff"}, []string{"poweroff"}); err == nil {
+		t.Fatal("expected an error when both commands are missing")
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCageControlSocket is the control socket created by the Strux Cage build
+	DefaultCageControlSocket = "/tmp/strux-cage-control.sock"
+
+	// DefaultCageControlTimeout bounds a whole control exchange (connect, write, acknowledgement)
+	DefaultCageControlTimeout = 2 * time.Second
+)
+
+var (
+	// ErrCageControlUnavailable is returned when the control socket doesn't exist
+	// or refuses connections, e.g. in dev mode where Cage isn't running.
+	ErrCageControlUnavailable = errors.New("cage control socket unavailable")
+
+	// ErrCageControlTimeout is returned when the compositor doesn't complete an
+	// exchange within the timeout.
+	ErrCageControlTimeout = errors.New("cage control socket timed out")
+)
+
+// CageControl is a client for the Cage compositor's control socket. Each
+// command is sent on its own connection.
+type CageControl struct {
+	SocketPath string
+	Timeout    time.Duration
+}
+
+// NewCageControl returns a client for the default control socket and timeout.
+func NewCageControl() *CageControl {
+	return &CageControl{
+		SocketPath: DefaultCageControlSocket,
+		Timeout:    DefaultCageControlTimeout,
+	}
+}
+
+// Send writes a command to the control socket and waits for the compositor to
+// acknowledge it. Compositors that predate acknowledgements close the
+// connection without replying, which is treated as success.
+func (c *CageControl) Send(command string) error {
+	reply, err := c.Request(command)
+	if err != nil {
+		return err
+	}
+	if reply != "" && reply != "OK" {
+		return fmt.Errorf("cage control %s rejected: %s", command, strings.TrimPrefix(reply, "ERR "))
+	}
+	return nil
+}
+
+// maxCageTitleLength keeps SET_TITLE within the compositor's command buffer
+const maxCageTitleLength = 200
+
+// SetTitle sets the compositor's window title, replacing the title of the
+// focused view. Newlines are replaced and long titles are truncated so the
+// title fits in a single control command.
+func (c *CageControl) SetTitle(title string) error {
+	title = strings.NewReplacer("\r", " ", "\n", " ").Replace(title)
+	if len(title) > maxCageTitleLength {
+		title = strings.ToValidUTF8(title[:maxCageTitleLength], "")
+	}
+	return c.Send("SET_TITLE " + title)
+}
+
+// Request writes a command to the control socket and returns the first line
+// of the reply, or "" if the compositor closed the connection without one.
+func (c *CageControl) Request(command string) (string, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return "", c.wrapError(command, err)
+	}
+	if uc, ok := conn.(*net.UnixConn); ok {
+		_ = uc.CloseWrite()
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && reply == "") {
+		return "", c.wrapError(command, err)
+	}
+	return strings.TrimSpace(reply), nil
+}
+
+// dial connects to the control socket and applies the deadline.
+func (c *CageControl) dial() (net.Conn, error) {
+	timeout := c.timeout()
+
+	conn, err := net.DialTimeout("unix", c.socketPath(), timeout)
+	if err != nil {
+		if os.IsNotExist(err) || isConnectionRefused(err) {
+			return nil, fmt.Errorf("%w: %v", ErrCageControlUnavailable, err)
+		}
+		if isTimeout(err) {
+			return nil, fmt.Errorf("%w: connecting to %s", ErrCageControlTimeout, c.socketPath())
+		}
+		return nil, fmt.Errorf("failed to connect to Cage control socket: %w", err)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	return conn, nil
+}
+
+// wrapError distinguishes deadline expiry from other socket errors.
+func (c *CageControl) wrapError(command string, err error) error {
+	if isTimeout(err) {
+		return fmt.Errorf("%w: %s after %s", ErrCageControlTimeout, command, c.timeout())
+	}
+	return fmt.Errorf("cage control %s failed: %w", command, err)
+}
+
+func (c *CageControl) socketPath() string {
+	if c.SocketPath == "" {
+		return DefaultCageControlSocket
+	}
+	return c.SocketPath
+}
+
+func (c *CageControl) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultCageControlTimeout
+	}
+	return c.Timeout
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
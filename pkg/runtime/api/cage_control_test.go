@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCageControlUnavailable(t *testing.T) {
+	cage := &CageControl{SocketPath: filepath.Join(t.TempDir(), "missing.sock")}
+
+	err := cage.Send("HIDE_SPLASH")
+	if !errors.Is(err, ErrCageControlUnavailable) {
+		t.Fatalf("expected ErrCageControlUnavailable, got %v", err)
+	}
+	if errors.Is(err, ErrCageControlTimeout) {
+		t.Fatalf("did not expect a timeout error: %v", err)
+	}
+}
+
+// serveCageControl accepts one connection, records the command and replies
+// with reply (no reply if empty). hold keeps the connection open without
+// replying until the test ends.
+func serveCageControl(t *testing.T, reply string, hold bool) (string, <-chan string) {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "control.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		listener.Close()
+	})
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- string(data)
+		if hold {
+			<-done
+			return
+		}
+		if reply != "" {
+			conn.Write([]byte(reply))
+		}
+	}()
+	return socketPath, received
+}
+
+func TestCageControlSendWaitsForAck(t *testing.T) {
+	socketPath, received := serveCageControl(t, "OK\n", false)
+
+	cage := &CageControl{SocketPath: socketPath, Timeout: time.Second}
+	if err := cage.Send("HIDE_SPLASH"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got := <-received; got != "HIDE_SPLASH" {
+		t.Fatalf("expected HIDE_SPLASH, got %q", got)
+	}
+}
+
+func TestCageControlSendWithoutAck(t *testing.T) {
+	socketPath, _ := serveCageControl(t, "", false)
+
+	cage := &CageControl{SocketPath: socketPath, Timeout: time.Second}
+	if err := cage.Send("HIDE_SPLASH"); err != nil {
+		t.Fatalf("expected a compositor without acknowledgements to be accepted, got %v", err)
+	}
+}
+
+func TestCageControlSendRejected(t *testing.T) {
+	socketPath, _ := serveCageControl(t, "ERR unknown command\n", false)
+
+	cage := &CageControl{SocketPath: socketPath, Timeout: time.Second}
+	if err := cage.Send("NAVIGATE"); err == nil {
+		t.Fatal("expected rejected command to return an error")
+	}
+}
+
+func TestCageControlSendTimeout(t *testing.T) {
+	socketPath, _ := serveCageControl(t, "", true)
+
+	cage := &CageControl{SocketPath: socketPath, Timeout: 100 * time.Millisecond}
+	err := cage.Send("HIDE_SPLASH")
+	if !errors.Is(err, ErrCageControlTimeout) {
+		t.Fatalf("expected ErrCageControlTimeout, got %v", err)
+	}
+}
+
+func TestCageControlSetTitle(t *testing.T) {
+	socketPath, received := serveCageControl(t, "OK\n", false)
+	cage := &CageControl{SocketPath: socketPath}
+
+	if err := cage.SetTitle("Kiosk\nLobby"); err != nil {
+		t.Fatalf("SetTitle failed: %v", err)
+	}
+	if got := <-received; got != "SET_TITLE Kiosk Lobby" {
+		t.Fatalf("unexpected command %q", got)
+	}
+}
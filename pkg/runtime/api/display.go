@@ -56,6 +56,14 @@ type DisplayOutput struct {
 	Scale            float64         `json:"scale"`
 }
 
+// OutputInfo is a compact summary of one display for resolution pickers. Modes
+// use the "WIDTHxHEIGHT@REFRESHHz" form accepted by SetMode.
+type OutputInfo struct {
+	Name        string   `json:"name"`
+	CurrentMode string   `json:"currentMode"`
+	Modes       []string `json:"modes"`
+}
+
 // ListedModeSelection picks a timing from the advertised list (width, height, optional refresh).
 // RefreshMilliHz 0 matches the first entry for that size regardless of refresh.
 type ListedModeSelection struct {
@@ -109,6 +117,8 @@ var Display = DefineCapability[DisplayProvider](CapabilitySpec{
 		{Name: "List", Description: "Returns every connected logical display with advertised timings, active timing, layout, scale, and transform."},
 		{Name: "Get", Description: "Returns the same snapshot as List for a single display name."},
 		{Name: "Apply", Description: "Applies several display updates in one step so multi-head kiosks stay consistent."},
+		{Name: "Outputs", Description: "Returns each display's name, current mode, and available modes as mode strings for resolution pickers."},
+		{Name: "SetMode", Description: "Switches a display to a mode string returned by Outputs (e.g. 1920x1080@60Hz)."},
 		{Name: "SetListedMode", Description: "Switches a display to an advertised width, height, and optional refresh rate."},
 		{Name: "SetCustomMode", Description: "Drives a display with a timing that may not be in the advertised list."},
 		{Name: "SetPreferredMode", Description: "Selects the display's preferred timing when the driver exposes one."},
@@ -116,6 +126,8 @@ var Display = DefineCapability[DisplayProvider](CapabilitySpec{
 		{Name: "SetLayout", Description: "Sets the display's position in the global compositor layout."},
 		{Name: "SetScale", Description: "Sets fractional UI scaling for that display."},
 		{Name: "SetTransform", Description: "Sets rotation or mirroring for that display."},
+		{Name: "GetBrightness", Description: "Returns the sysfs backlight level as a percentage of max_brightness, without requiring a BSP provider."},
+		{Name: "SetBrightness", Description: "Sets the sysfs backlight level as a percentage (0-100) of max_brightness."},
 		{Name: "GetBacklight", Description: "Returns the current backlight level for that display (typically 0-100)."},
 		{Name: "SetBacklight", Description: "Sets the backlight level for that display (typically 0-100)."},
 	},
@@ -126,7 +138,10 @@ func RegisterDisplayProvider(provider DisplayProvider) {
 }
 
 // DisplayService exposes Strux-standard display tooling to kiosk apps through the IPC bridge.
-type DisplayService struct{}
+type DisplayService struct {
+	// backlightDir overrides the sysfs backlight class directory (used in tests).
+	backlightDir string
+}
 
 // List returns all logical displays and their current configuration.
 func (DisplayService) List() ([]DisplayOutput, error) {
@@ -158,6 +173,41 @@ func (DisplayService) Apply(changes []DisplayOutputChange, opts DisplayApplyOpti
 	return execWlrRandrApply(contextFromEnv(), changes, opts)
 }
 
+// Outputs returns a compact summary of every display with its modes formatted as
+// strings that can be passed back to SetMode.
+func (DisplayService) Outputs() ([]OutputInfo, error) {
+	list, err := displayList()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]OutputInfo, 0, len(list))
+	for _, out := range list {
+		info := OutputInfo{
+			Name:  out.Name,
+			Modes: make([]string, 0, len(out.Modes)),
+		}
+		if out.Current != nil {
+			info.CurrentMode = formatDisplayMode(*out.Current)
+		}
+		for _, mode := range out.Modes {
+			info.Modes = append(info.Modes, formatDisplayMode(mode))
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// SetMode switches one display to a mode string such as "1920x1080" or
+// "1920x1080@60Hz", as returned by Outputs.
+func (DisplayService) SetMode(output string, mode string) error {
+	selection, err := parseDisplayModeString(mode)
+	if err != nil {
+		return err
+	}
+	return execWlrRandrApply(contextFromEnv(), []DisplayOutputChange{{Name: output, ListedMode: &selection}}, DisplayApplyOptions{})
+}
+
 // SetListedMode switches one display to an advertised timing.
 func (DisplayService) SetListedMode(output string, mode ListedModeSelection, opts DisplayApplyOptions) error {
 	m := mode
@@ -223,6 +273,25 @@ func (DisplayService) SetBacklight(outputName string, value int) error {
 	return provider.SetBacklight(outputName, value)
 }
 
+// GetBrightness returns the sysfs backlight level as a percentage (0-100) of the
+// device's max_brightness. Returns ErrNoBacklightDevice when there is no backlight.
+func (s DisplayService) GetBrightness() (int, error) {
+	return readBrightnessPercent(s.backlightPath())
+}
+
+// SetBrightness sets the sysfs backlight level as a percentage (0-100) of the
+// device's max_brightness. Returns ErrNoBacklightDevice when there is no backlight.
+func (s DisplayService) SetBrightness(percent int) error {
+	return writeBrightnessPercent(s.backlightPath(), percent)
+}
+
+func (s DisplayService) backlightPath() string {
+	if s.backlightDir != "" {
+		return s.backlightDir
+	}
+	return defaultBacklightDir
+}
+
 func displayList() ([]DisplayOutput, error) {
 	stdout, _, err := execWlrRandrCapture(contextFromEnv())
 	if err != nil {
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultBacklightDir is the sysfs class directory exposing backlight devices.
+const defaultBacklightDir = "/sys/class/backlight"
+
+// ErrNoBacklightDevice is returned when the device exposes no sysfs backlight.
+var ErrNoBacklightDevice = errors.New("no backlight device found")
+
+// backlightDevice returns the first backlight device directory under dir.
+func backlightDevice(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNoBacklightDevice
+		}
+		return "", fmt.Errorf("failed to list backlight devices: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		device := filepath.Join(dir, name)
+		if _, err := os.Stat(filepath.Join(device, "max_brightness")); err == nil {
+			return device, nil
+		}
+	}
+	return "", ErrNoBacklightDevice
+}
+
+func readBacklightValue(device, name string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(device, name))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", name, strings.TrimSpace(string(data)))
+	}
+	return value, nil
+}
+
+// readBrightnessPercent returns the backlight level of the first device under dir
+// as a percentage of max_brightness.
+func readBrightnessPercent(dir string) (int, error) {
+	device, err := backlightDevice(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	maxBrightness, err := readBacklightValue(device, "max_brightness")
+	if err != nil {
+		return 0, err
+	}
+	if maxBrightness <= 0 {
+		return 0, fmt.Errorf("backlight %s reports max_brightness %d", filepath.Base(device), maxBrightness)
+	}
+
+	brightness, err := readBacklightValue(device, "brightness")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(math.Round(float64(brightness) * 100 / float64(maxBrightness))), nil
+}
+
+// writeBrightnessPercent scales percent against max_brightness and writes it to
+// the first backlight device under dir.
+func writeBrightnessPercent(dir string, percent int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("brightness must be between 0 and 100")
+	}
+
+	device, err := backlightDevice(dir)
+	if err != nil {
+		return err
+	}
+
+	maxBrightness, err := readBacklightValue(device, "max_brightness")
+	if err != nil {
+		return err
+	}
+
+	value := int(math.Round(float64(maxBrightness) * float64(percent) / 100))
+	if err := os.WriteFile(filepath.Join(device, "brightness"), []byte(strconv.Itoa(value)), 0644); err != nil {
+		return fmt.Errorf("failed to set brightness: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDisplayServiceBrightnessScalesAgainstMax(t *testing.T) {
+	tempDir := t.TempDir()
+	device := filepath.Join(tempDir, "intel_backlight")
+	if err := os.MkdirAll(device, 0755); err != nil {
+		t.Fatalf("failed to create device dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(device, "max_brightness"), []byte("255\n"), 0644); err != nil {
+		t.Fatalf("failed to write max_brightness: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(device, "brightness"), []byte("51\n"), 0644); err != nil {
+		t.Fatalf("failed to write brightness: %v", err)
+	}
+
+	display := DisplayService{backlightDir: tempDir}
+
+	percent, err := display.GetBrightness()
+	if err != nil {
+		t.Fatalf("GetBrightness failed: %v", err)
+	}
+	if percent != 20 {
+		t.Fatalf("expected 20%%, got %d", percent)
+	}
+
+	if err := display.SetBrightness(50); err != nil {
+		t.Fatalf("SetBrightness failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(device, "brightness"))
+	if err != nil {
+		t.Fatalf("failed to read brightness: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "128" {
+		t.Fatalf("expected raw brightness 128, got %q", data)
+	}
+
+	if err := display.SetBrightness(101); err == nil {
+		t.Fatal("expected out-of-range brightness to fail")
+	}
+}
+
+func TestDisplayServiceBrightnessWithoutBacklight(t *testing.T) {
+	display := DisplayService{backlightDir: filepath.Join(t.TempDir(), "missing")}
+
+	if _, err := display.GetBrightness(); !errors.Is(err, ErrNoBacklightDevice) {
+		t.Fatalf("expected ErrNoBacklightDevice, got %v", err)
+	}
+	if err := display.SetBrightness(50); !errors.Is(err, ErrNoBacklightDevice) {
+		t.Fatalf("expected ErrNoBacklightDevice, got %v", err)
+	}
+}
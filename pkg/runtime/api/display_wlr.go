@@ -24,6 +24,7 @@ var (
 	displayTransformLine  = regexp.MustCompile(`^\s+Transform:\s+(\S+)\s*$`)
 	displayScaleLine      = regexp.MustCompile(`^\s+Scale:\s+(\S+)\s*$`)
 	validDisplayOutputID  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	displayModeString     = regexp.MustCompile(`^(\d+)x(\d+)(?:@([0-9]+(?:\.[0-9]+)?)\s*(?:Hz)?)?$`)
 
 	validWlrTransforms = map[string]struct{}{
 		"normal":      {},
@@ -233,6 +234,35 @@ func formatRandrModeTriple(width, height int, refreshMilliHz int) string {
 	return fmt.Sprintf("%dx%d@%g Hz", width, height, hz)
 }
 
+// formatDisplayMode renders a mode as "WIDTHxHEIGHT@REFRESHHz", omitting the
+// refresh rate when wlr-randr did not report one.
+func formatDisplayMode(mode DisplayMode) string {
+	if mode.RefreshHz <= 0 {
+		return fmt.Sprintf("%dx%d", mode.WidthPX, mode.HeightPX)
+	}
+	return fmt.Sprintf("%dx%d@%sHz", mode.WidthPX, mode.HeightPX, strconv.FormatFloat(mode.RefreshHz, 'f', -1, 64))
+}
+
+// parseDisplayModeString parses a mode string produced by formatDisplayMode.
+func parseDisplayModeString(mode string) (ListedModeSelection, error) {
+	m := displayModeString.FindStringSubmatch(strings.TrimSpace(mode))
+	if m == nil {
+		return ListedModeSelection{}, fmt.Errorf("invalid mode %q: expected WIDTHxHEIGHT or WIDTHxHEIGHT@REFRESHHz", mode)
+	}
+
+	width, _ := strconv.Atoi(m[1])
+	height, _ := strconv.Atoi(m[2])
+	selection := ListedModeSelection{Width: width, Height: height}
+	if m[3] != "" {
+		hz, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return ListedModeSelection{}, fmt.Errorf("invalid refresh rate in mode %q", mode)
+		}
+		selection.RefreshMilliHz = int(hz*1000 + 0.5)
+	}
+	return selection, nil
+}
+
 func parseWlrRandrStdout(stdout string) ([]DisplayOutput, error) {
 	lines := strings.Split(stdout, "\n")
 	var outs []DisplayOutput
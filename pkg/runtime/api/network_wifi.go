@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoWiFiDevice is returned when NetworkManager reports no Wi-Fi device.
+var ErrNoWiFiDevice = errors.New("no wifi device found")
+
+// nmcliWait is how long nmcli may wait for a forced rescan or a connection
+// (association plus DHCP) to complete. Both regularly take longer than
+// systemCommandTimeout.
+const nmcliWait = 30 * time.Second
+
+// nmcliWaitTimeout kills nmcli only after it had time to give up on its own,
+// so a slow operation is reported by nmcli instead of as a killed process.
+const nmcliWaitTimeout = nmcliWait + 5*time.Second
+
+// nmcliWaitArgs prefixes args with nmcli's --wait option set to nmcliWait
+func nmcliWaitArgs(args ...string) []string {
+	return append([]string{"--wait", strconv.Itoa(int(nmcliWait / time.Second))}, args...)
+}
+
+// nmcliScanFields are the `nmcli -t` columns requested by ScanWiFi, in order.
+var nmcliScanFields = []string{"DEVICE", "SSID", "BSSID", "SIGNAL", "SECURITY", "CHAN"}
+
+// splitNmcliFields splits one line of `nmcli -t` output. nmcli separates fields
+// with ':' and escapes literal colons and backslashes inside values.
+func splitNmcliFields(line string) []string {
+	var fields []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(fields, current.String())
+}
+
+// parseNmcliWiFiList parses `nmcli -t -f DEVICE,SSID,BSSID,SIGNAL,SECURITY,CHAN
+// device wifi list` output. Hidden networks (empty SSID) are skipped.
+func parseNmcliWiFiList(output string) []WiFiNetwork {
+	networks := []WiFiNetwork{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		fields := splitNmcliFields(line)
+		if len(fields) != len(nmcliScanFields) || fields[1] == "" {
+			continue
+		}
+		signal, _ := strconv.Atoi(fields[3])
+		channel, _ := strconv.Atoi(fields[5])
+		networks = append(networks, WiFiNetwork{
+			InterfaceName:  fields[0],
+			SSID:           fields[1],
+			BSSID:          fields[2],
+			SignalStrength: signal,
+			Security:       fields[4],
+			Channel:        channel,
+		})
+	}
+	return networks
+}
+
+// validateWiFiCredentials checks an SSID/PSK pair before it is passed to nmcli.
+// Values are passed as separate argv entries (never through a shell), so this
+// only rejects values nmcli or the 802.11 spec cannot accept.
+func validateWiFiCredentials(ssid, psk string) error {
+	if ssid == "" {
+		return fmt.Errorf("wifi SSID is empty")
+	}
+	if len(ssid) > 32 {
+		return fmt.Errorf("wifi SSID must be at most 32 bytes")
+	}
+	if strings.ContainsRune(ssid, '\x00') {
+		return fmt.Errorf("wifi SSID contains a NUL byte")
+	}
+	if psk == "" {
+		return nil
+	}
+	if strings.ContainsRune(psk, '\x00') {
+		return fmt.Errorf("wifi password contains a NUL byte")
+	}
+	if len(psk) < 8 || len(psk) > 64 {
+		return fmt.Errorf("wifi password must be 8-63 characters or a 64-digit hex key")
+	}
+	return nil
+}
+
+// ScanWiFi rescans and returns nearby access points using NetworkManager. It
+// does not require a BSP Wi-Fi provider; use the wifi namespace for adapter
+// selection and saved profiles.
+func (NetworkService) ScanWiFi() ([]WiFiNetwork, error) {
+	args := nmcliWaitArgs("-t", "-f", strings.Join(nmcliScanFields, ","), "device", "wifi", "list", "--rescan", "yes")
+	output, err := runSystemCommandTimeout(nmcliWaitTimeout, "nmcli", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan wifi: %w", err)
+	}
+	return parseNmcliWiFiList(output), nil
+}
+
+// ConnectWiFi connects to an SSID using NetworkManager. psk may be empty for
+// open networks.
+func (NetworkService) ConnectWiFi(ssid, psk string) error {
+	if err := validateWiFiCredentials(ssid, psk); err != nil {
+		return err
+	}
+
+	args := nmcliWaitArgs("device", "wifi", "connect", ssid)
+	if psk != "" {
+		args = append(args, "password", psk)
+	}
+	if _, err := runSystemCommandTimeout(nmcliWaitTimeout, "nmcli", args...); err != nil {
+		return fmt.Errorf("failed to connect to wifi: %w", err)
+	}
+	return nil
+}
+
+// WiFiStatus returns the NetworkManager state of the first Wi-Fi device (e.g.
+// "connected", "disconnected", "unavailable"). Returns ErrNoWiFiDevice when
+// there is no Wi-Fi device.
+func (NetworkService) WiFiStatus() (string, error) {
+	output, err := runSystemCommand("nmcli", "-t", "-f", "TYPE,STATE", "device", "status")
+	if err != nil {
+		return "", fmt.Errorf("failed to read wifi status: %w", err)
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := splitNmcliFields(strings.TrimRight(line, "\r"))
+		if len(fields) == 2 && fields[0] == "wifi" {
+			return fields[1], nil
+		}
+	}
+	return "", ErrNoWiFiDevice
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseNmcliWiFiListHandlesEscapedColons(t *testing.T) {
+	output := "wlan0:Cafe\\: Guest:AA\\:BB\\:CC\\:DD\\:EE\\:FF:72:WPA2:6\n" +
+		"wlan0::11\\:22\\:33\\:44\\:55\\:66:40::11\n" +
+		"wlan0:Home:66\\:55\\:44\\:33\\:22\\:11:55:WPA1 WPA2:36\n"
+
+	networks := parseNmcliWiFiList(output)
+	if len(networks) != 2 {
+		t.Fatalf("expected 2 networks, got %d: %+v", len(networks), networks)
+	}
+
+	first := networks[0]
+	if first.SSID != "Cafe: Guest" || first.BSSID != "AA:BB:CC:DD:EE:FF" {
+		t.Fatalf("unexpected first network: %+v", first)
+	}
+	if first.SignalStrength != 72 || first.Channel != 6 || first.InterfaceName != "wlan0" {
+		t.Fatalf("unexpected first network: %+v", first)
+	}
+	if networks[1].SSID != "Home" || networks[1].Security != "WPA1 WPA2" {
+		t.Fatalf("unexpected second network: %+v", networks[1])
+	}
+}
+
+func TestValidateWiFiCredentials(t *testing.T) {
+	if err := validateWiFiCredentials("Home", ""); err != nil {
+		t.Fatalf("open network failed: %v", err)
+	}
+	if err := validateWiFiCredentials("Home; rm -rf /", "correct horse"); err != nil {
+		t.Fatalf("shell metacharacters should be passed through verbatim: %v", err)
+	}
+	if err := validateWiFiCredentials("", "password"); err == nil {
+		t.Fatal("expected empty SSID to fail")
+	}
+	if err := validateWiFiCredentials("Home", "short"); err == nil {
+		t.Fatal("expected short password to fail")
+	}
+}
+
+func TestNmcliWaitOutlastsSystemCommandTimeout(t *testing.T) {
+	if nmcliWait != 30*time.Second {
+		t.Fatalf("expected nmcli to wait 30s for scans and connects, got %v", nmcliWait)
+	}
+	if nmcliWaitTimeout <= nmcliWait || nmcliWaitTimeout <= systemCommandTimeout {
+		t.Fatalf("expected the nmcli timeout %v to outlast --wait %v and the default %v", nmcliWaitTimeout, nmcliWait, systemCommandTimeout)
+	}
+
+	got := nmcliWaitArgs("device", "wifi", "connect", "Home")
+	want := []string{"--wait", "30", "device", "wifi", "connect", "Home"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected args %q, got %q", want, got)
+	}
+}
@@ -1,14 +1,21 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 )
 
 const SystemNamespace = "system"
 
+// systemCommandTimeout bounds each external tool invocation made by SystemService.
+const systemCommandTimeout = 5 * time.Second
+
 // deviceModelPath is the device-tree node exposing the hardware model string on
 // most ARM/RISC-V Linux boards.
 const deviceModelPath = "/proc/device-tree/model"
@@ -18,6 +25,18 @@ const deviceModelPath = "/proc/device-tree/model"
 type SystemService struct {
 	// infoPath overrides the project metadata file location (used in tests).
 	infoPath string
+	// healthPath overrides the client health report location (used in tests).
+	healthPath string
+	// logsPath overrides the client recent logs location (used in tests).
+	logsPath string
+	// cogLogPath overrides the Cage/Cog log location (used in tests).
+	cogLogPath string
+	// cogExitPath overrides the Cog exit record location (used in tests).
+	cogExitPath string
+	// staleBinaryPaths overrides the leftover update binaries (used in tests).
+	staleBinaryPaths []string
+	// tmpDir overrides the directory of the logs DiskCleanup rotates (used in tests).
+	tmpDir string
 }
 
 // BSP returns the name of the board support package the running image was built
@@ -67,3 +86,35 @@ func (s *SystemService) Hostname() (string, error) {
 	}
 	return hostname, nil
 }
+
+// runSystemCommand runs a system tool and returns its stdout. Arguments are
+// passed directly to the binary without a shell.
+func runSystemCommand(name string, args ...string) (string, error) {
+	return runSystemCommandTimeout(systemCommandTimeout, name, args...)
+}
+
+// runSystemCommandTimeout is runSystemCommand with its own time limit, for
+// tools that are expected to take longer than systemCommandTimeout.
+func runSystemCommandTimeout(timeout time.Duration, name string, args ...string) (string, error) {
+	bin, err := exec.LookPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	if runErr := cmd.Run(); runErr != nil {
+		msg := strings.TrimSpace(errBuf.String())
+		if msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, runErr, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, runErr)
+	}
+	return outBuf.String(), nil
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// wpctlDefaultSink is the WirePlumber alias for the default output device.
+	wpctlDefaultSink = "@DEFAULT_AUDIO_SINK@"
+
+	// amixerControl is the ALSA mixer control used when WirePlumber is unavailable.
+	amixerControl = "Master"
+)
+
+// ErrNoAudioDevice is returned when neither wpctl nor amixer can reach an output device.
+var ErrNoAudioDevice = errors.New("no audio output device found")
+
+var (
+	wpctlVolumeLine  = regexp.MustCompile(`Volume:\s+([0-9.]+)`)
+	amixerPercentTag = regexp.MustCompile(`\[(\d+)%\]`)
+)
+
+// Error output that means the audio output itself is missing, as opposed to
+// the tool failing for some other reason.
+var (
+	wpctlMissingSink     = []string{"Translate ID error", "is not a valid ID", "not found"}
+	amixerMissingControl = []string{"Unable to find simple control", "Mixer attach", "Invalid card number", "cannot find card"}
+)
+
+// wpctlNoPipeWire is the wpctl error output when the PipeWire daemon is not
+// running or not reachable; amixer may still work in that case.
+const wpctlNoPipeWire = "Could not connect to PipeWire"
+
+// audioCommandError wraps err in ErrNoAudioDevice when the wpctl or amixer
+// output it carries shows the sink or control is missing, and returns it
+// unchanged otherwise.
+func audioCommandError(backend string, err error) error {
+	markers := amixerMissingControl
+	if backend == "wpctl" {
+		markers = wpctlMissingSink
+	}
+	for _, marker := range markers {
+		if strings.Contains(err.Error(), marker) {
+			return fmt.Errorf("%w: %v", ErrNoAudioDevice, err)
+		}
+	}
+	return err
+}
+
+// runAudioCommand runs wpctl with wpctlArgs, or amixer with amixerArgs when
+// wpctl is not installed or cannot reach PipeWire. It returns the backend that
+// produced the output.
+func runAudioCommand(wpctlArgs, amixerArgs []string) (string, string, error) {
+	_, amixerErr := exec.LookPath("amixer")
+
+	if _, err := exec.LookPath("wpctl"); err == nil {
+		output, err := runSystemCommand("wpctl", wpctlArgs...)
+		if err == nil {
+			return "wpctl", output, nil
+		}
+		if !strings.Contains(err.Error(), wpctlNoPipeWire) || amixerErr != nil {
+			return "wpctl", "", audioCommandError("wpctl", err)
+		}
+	} else if amixerErr != nil {
+		return "", "", ErrNoAudioDevice
+	}
+
+	output, err := runSystemCommand("amixer", amixerArgs...)
+	if err != nil {
+		return "amixer", "", audioCommandError("amixer", err)
+	}
+	return "amixer", output, nil
+}
+
+// clampPercent limits a percentage to the 0-100 range.
+func clampPercent(percent int) int {
+	if percent < 0 {
+		return 0
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// parseWpctlVolume parses `wpctl get-volume` output such as "Volume: 0.40 [MUTED]".
+func parseWpctlVolume(output string) (int, error) {
+	m := wpctlVolumeLine.FindStringSubmatch(output)
+	if m == nil {
+		return 0, fmt.Errorf("unexpected wpctl output %q", strings.TrimSpace(output))
+	}
+	volume, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid wpctl volume %q", m[1])
+	}
+	return clampPercent(int(math.Round(volume * 100))), nil
+}
+
+// parseAmixerVolume parses the first "[NN%]" level from `amixer get` output.
+func parseAmixerVolume(output string) (int, error) {
+	m := amixerPercentTag.FindStringSubmatch(output)
+	if m == nil {
+		return 0, fmt.Errorf("unexpected amixer output %q", strings.TrimSpace(output))
+	}
+	volume, _ := strconv.Atoi(m[1])
+	return clampPercent(volume), nil
+}
+
+// GetVolume returns the default output volume as a percentage (0-100), using
+// wpctl when available and falling back to amixer. Returns ErrNoAudioDevice
+// when no audio output is present.
+func (s *SystemService) GetVolume() (int, error) {
+	backend, output, err := runAudioCommand(
+		[]string{"get-volume", wpctlDefaultSink},
+		[]string{"get", amixerControl},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	parse := parseAmixerVolume
+	if backend == "wpctl" {
+		parse = parseWpctlVolume
+	}
+	// Some versions report a missing sink on stdout and still exit 0
+	volume, err := parse(output)
+	if err != nil {
+		return 0, audioCommandError(backend, err)
+	}
+	return volume, nil
+}
+
+// SetVolume sets the default output volume. Values outside 0-100 are clamped.
+func (s *SystemService) SetVolume(percent int) error {
+	percent = clampPercent(percent)
+
+	level := strconv.FormatFloat(float64(percent)/100, 'f', 2, 64)
+	_, _, err := runAudioCommand(
+		[]string{"set-volume", wpctlDefaultSink, level},
+		[]string{"-q", "set", amixerControl, strconv.Itoa(percent) + "%"},
+	)
+	return err
+}
+
+// SetMuted mutes or unmutes the default output device.
+func (s *SystemService) SetMuted(muted bool) error {
+	wpctlState, amixerState := "0", "unmute"
+	if muted {
+		wpctlState, amixerState = "1", "mute"
+	}
+
+	_, _, err := runAudioCommand(
+		[]string{"set-mute", wpctlDefaultSink, wpctlState},
+		[]string{"-q", "set", amixerControl, amixerState},
+	)
+	return err
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseWpctlVolume(t *testing.T) {
+	tests := []struct {
+		output string
+		want   int
+	}{
+		{"Volume: 0.40\n", 40},
+		{"Volume: 0.40 [MUTED]\n", 40},
+		{"Volume: 0.00 [MUTED]\n", 0},
+		{"Volume: 1.00\n", 100},
+		{"Volume: 0.555\n", 56},
+		// wpctl allows boosting above 100%
+		{"Volume: 1.50\n", 100},
+	}
+	for _, tt := range tests {
+		got, err := parseWpctlVolume(tt.output)
+		if err != nil {
+			t.Fatalf("parseWpctlVolume(%q) failed: %v", tt.output, err)
+		}
+		if got != tt.want {
+			t.Fatalf("parseWpctlVolume(%q) = %d, want %d", tt.output, got, tt.want)
+		}
+	}
+
+	for _, output := range []string{"", "Translate ID error: '@DEFAULT_AUDIO_SINK@' is not a valid ID\n"} {
+		if _, err := parseWpctlVolume(output); err == nil {
+			t.Fatalf("expected parseWpctlVolume(%q) to fail", output)
+		}
+	}
+}
+
+func TestParseAmixerVolume(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   int
+	}{
+		{"stereo", `Simple mixer control 'Master',0
+  Capabilities: pvolume pswitch pswitch-joined
+  Playback channels: Front Left - Front Right
+  Limits: Playback 0 - 65536
+  Mono:
+  Front Left: Playback 26214 [40%] [on]
+  Front Right: Playback 26214 [40%] [on]
+`, 40},
+		{"muted", `Simple mixer control 'Master',0
+  Capabilities: pvolume pswitch pswitch-joined
+  Playback channels: Front Left - Front Right
+  Limits: Playback 0 - 65536
+  Mono:
+  Front Left: Playback 49152 [75%] [off]
+  Front Right: Playback 49152 [75%] [off]
+`, 75},
+		{"mono with dB", `Simple mixer control 'Master',0
+  Capabilities: pvolume pvolume-joined pswitch pswitch-joined
+  Playback channels: Mono
+  Limits: Playback 0 - 87
+  Mono: Playback 87 [100%] [0.00dB] [on]
+`, 100},
+		{"unbalanced channels use the first", `Simple mixer control 'Master',0
+  Capabilities: pvolume pswitch
+  Playback channels: Front Left - Front Right
+  Limits: Playback 0 - 65536
+  Mono:
+  Front Left: Playback 32768 [50%] [on]
+  Front Right: Playback 19661 [30%] [on]
+`, 50},
+	}
+	for _, tt := range tests {
+		got, err := parseAmixerVolume(tt.output)
+		if err != nil {
+			t.Fatalf("%s: parseAmixerVolume failed: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: parseAmixerVolume = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	for _, output := range []string{"", "amixer: Unable to find simple control 'Master',0\n"} {
+		if _, err := parseAmixerVolume(output); err == nil {
+			t.Fatalf("expected parseAmixerVolume(%q) to fail", output)
+		}
+	}
+}
+
+func TestAudioCommandError(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		err     error
+		missing bool
+	}{
+		{"wpctl missing sink", "wpctl", errors.New("wpctl: exit status 1: Translate ID error: '@DEFAULT_AUDIO_SINK@' is not a valid ID"), true},
+		{"wpctl missing sink on stdout", "wpctl", errors.New(`unexpected wpctl output "Translate ID error: '@DEFAULT_AUDIO_SINK@' is not a valid ID"`), true},
+		{"wpctl no pipewire", "wpctl", errors.New("wpctl: exit status 1: Could not connect to PipeWire"), false},
+		{"wpctl timeout", "wpctl", errors.New("wpctl: signal: killed"), false},
+		{"amixer missing control", "amixer", errors.New("amixer: exit status 1: amixer: Unable to find simple control 'Master',0"), true},
+		{"amixer no sound card", "amixer", errors.New("amixer: exit status 1: amixer: Mixer attach default error: No such file or directory"), true},
+		{"amixer permission denied", "amixer", errors.New("amixer: exit status 1: amixer: Control default open error: Permission denied"), false},
+	}
+	for _, tt := range tests {
+		got := audioCommandError(tt.backend, tt.err)
+		if missing := errors.Is(got, ErrNoAudioDevice); missing != tt.missing {
+			t.Fatalf("%s: errors.Is(err, ErrNoAudioDevice) = %v, want %v", tt.name, missing, tt.missing)
+		}
+		if !tt.missing && got != tt.err {
+			t.Fatalf("%s: expected the error to pass through unchanged, got %v", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultCogExitPath is written by Cage each time a Cog instance exits
+	// without Cage stopping it.
+	defaultCogExitPath = "/tmp/strux-cog-exit"
+
+	// cogExitLogLines is how many log lines leading up to an exit are returned.
+	cogExitLogLines = 20
+)
+
+// CogExitInfo describes the last unexpected exit of a Cog browser instance.
+type CogExitInfo struct {
+	// Exited is false when no Cog instance has exited unexpectedly since boot.
+	Exited bool   `json:"exited"`
+	Output string `json:"output,omitempty"` // the output the Cog instance was showing, e.g. "HDMI-A-1"
+	PID    int    `json:"pid,omitempty"`
+	// ExitCode is the exit status, or 128 plus the signal number when Cog was
+	// killed by a signal, as a shell reports it.
+	ExitCode int    `json:"exitCode"`
+	Signal   int    `json:"signal,omitempty"` // e.g. 6 (SIGABRT) for a failed WebKit assertion
+	ExitedAt string `json:"exitedAt,omitempty"`
+	// LogLines are the last lines of the Cage/Cog log before the exit, empty
+	// if the log has since been recreated.
+	LogLines []string `json:"logLines"`
+}
+
+// LastCogExit returns the exit status of the last Cog instance that crashed or
+// exited on its own, with the log lines leading up to it, for diagnosing
+// recurring WebKit crashes. Cog instances Cage stops itself (on navigation or
+// when an output is removed) are not reported.
+func (s *SystemService) LastCogExit() (CogExitInfo, error) {
+	path := s.cogExitPath
+	if path == "" {
+		path = defaultCogExitPath
+	}
+
+	info := CogExitInfo{LogLines: []string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return info, nil
+	}
+	if err != nil {
+		return CogExitInfo{}, fmt.Errorf("failed to read Cog exit: %w", err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "output":
+			info.Output = value
+		case "pid":
+			info.PID, _ = strconv.Atoi(value)
+		case "exit_code":
+			info.ExitCode, _ = strconv.Atoi(value)
+		case "signal":
+			info.Signal, _ = strconv.Atoi(value)
+		case "time":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.ExitedAt = time.Unix(sec, 0).UTC().Format(time.RFC3339)
+			}
+		}
+	}
+	info.Exited = true
+
+	logPath := s.cogLogPath
+	if logPath == "" {
+		logPath = defaultCogLogPath
+	}
+	info.LogLines = linesBeforeCogExit(logPath, info.PID)
+	return info, nil
+}
+
+// linesBeforeCogExit returns the log lines preceding the line Cage logs when
+// the Cog instance with the given PID exits. If the line appears more than
+// once (a PID reused after a Cage restart), the last occurrence wins.
+func linesBeforeCogExit(path string, pid int) []string {
+	lines := []string{}
+	file, err := os.Open(path)
+	if err != nil {
+		return lines
+	}
+	defer file.Close()
+
+	marker := fmt.Sprintf("(PID %d) exited unexpectedly", pid)
+	recent := make([]string, 0, cogExitLogLines)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, marker) {
+			lines = append(lines[:0], recent...)
+		}
+		if len(recent) == cogExitLogLines {
+			recent = append(recent[:0], recent[1:]...)
+		}
+		recent = append(recent, line)
+	}
+	return lines
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLastCogExit(t *testing.T) {
+	dir := t.TempDir()
+	exitPath := filepath.Join(dir, "strux-cog-exit")
+	logPath := filepath.Join(dir, "strux-cage.log")
+
+	record := "output=HDMI-A-1\npid=412\nexit_code=134\nsignal=6\ntime=1700000000\n"
+	if err := os.WriteFile(exitPath, []byte(record), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	log := "Cog spawned for output HDMI-A-1 (PID 412)\n" +
+		"EGL assertion failed\n" +
+		"Cog for output HDMI-A-1 (PID 412) exited unexpectedly, killed by signal 6\n" +
+		"line after the exit\n"
+	if err := os.WriteFile(logPath, []byte(log), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	system := &SystemService{cogExitPath: exitPath, cogLogPath: logPath}
+	info, err := system.LastCogExit()
+	if err != nil {
+		t.Fatalf("LastCogExit failed: %v", err)
+	}
+
+	want := CogExitInfo{
+		Exited:   true,
+		Output:   "HDMI-A-1",
+		PID:      412,
+		ExitCode: 134,
+		Signal:   6,
+		ExitedAt: "2023-11-14T22:13:20Z",
+		LogLines: []string{"Cog spawned for output HDMI-A-1 (PID 412)", "EGL assertion failed"},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Fatalf("unexpected exit info %+v", info)
+	}
+}
+
+func TestLastCogExitWithoutExit(t *testing.T) {
+	system := &SystemService{cogExitPath: filepath.Join(t.TempDir(), "missing")}
+
+	info, err := system.LastCogExit()
+	if err != nil {
+		t.Fatalf("LastCogExit failed: %v", err)
+	}
+	if info.Exited || info.LogLines == nil {
+		t.Fatalf("expected an empty report, got %+v", info)
+	}
+}
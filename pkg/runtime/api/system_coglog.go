@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCogLogPath is where strux-client writes the output of Cage and Cog,
+// including WebKit console messages.
+const defaultCogLogPath = "/tmp/strux-cage.log"
+
+// CogLogEvent is the event StreamCogLog emits for each new log line. Its data
+// is the line without the trailing newline.
+const CogLogEvent = "strux.system.cogLog"
+
+// cogLogPollInterval is how often the log is checked for new content at EOF.
+const cogLogPollInterval = 200 * time.Millisecond
+
+// EventEmitter pushes an event to every connected frontend.
+type EventEmitter func(event string, data interface{})
+
+var (
+	eventEmitterMu sync.RWMutex
+	eventEmitter   EventEmitter
+)
+
+// SetEventEmitter installs the function services use to push events to the
+// frontend. The runtime calls this when it is created.
+func SetEventEmitter(emit EventEmitter) {
+	eventEmitterMu.Lock()
+	defer eventEmitterMu.Unlock()
+	eventEmitter = emit
+}
+
+func emitEvent(event string, data interface{}) {
+	eventEmitterMu.RLock()
+	emit := eventEmitter
+	eventEmitterMu.RUnlock()
+	if emit != nil {
+		emit(event, data)
+	}
+}
+
+// cogLogTail is the single shared tail of the Cog log. Services are stateless
+// values, so the running tail lives at package level.
+var cogLogTail struct {
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+// StreamCogLog starts pushing new lines of the Cage/Cog log to the frontend as
+// CogLogEvent events, for showing WebKit console output in on-device
+// diagnostics. Only lines written after the call are sent. Calling it while
+// the log is already streaming has no effect; call StopCogLog to end it.
+func (s *SystemService) StreamCogLog() error {
+	path := s.cogLogPath
+	if path == "" {
+		path = defaultCogLogPath
+	}
+
+	cogLogTail.mu.Lock()
+	defer cogLogTail.mu.Unlock()
+	if cogLogTail.stop != nil {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to open Cog log: %w", err)
+	}
+
+	stop := make(chan struct{})
+	cogLogTail.stop = stop
+	go tailLines(path, info.Size(), stop, func(line string) {
+		emitEvent(CogLogEvent, line)
+	})
+	return nil
+}
+
+// StopCogLog stops the stream started by StreamCogLog.
+func (s *SystemService) StopCogLog() error {
+	cogLogTail.mu.Lock()
+	defer cogLogTail.mu.Unlock()
+	if cogLogTail.stop != nil {
+		close(cogLogTail.stop)
+		cogLogTail.stop = nil
+	}
+	return nil
+}
+
+// tailLines calls onLine for every complete line appended to path after
+// offset, until stop is closed. If the file is truncated or recreated (as when
+// Cage restarts), reading starts over from the beginning.
+func tailLines(path string, offset int64, stop <-chan struct{}, onLine func(string)) {
+	var file *os.File
+	var reader *bufio.Reader
+	var partial strings.Builder
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		if file == nil {
+			f, err := os.Open(path)
+			if err == nil {
+				if _, err = f.Seek(offset, io.SeekStart); err != nil {
+					f.Close()
+				}
+			}
+			if err != nil {
+				// The log may be between a removal and a recreation
+				time.Sleep(cogLogPollInterval)
+				continue
+			}
+			file = f
+			reader = bufio.NewReader(file)
+		}
+
+		chunk, err := reader.ReadString('\n')
+		offset += int64(len(chunk))
+		partial.WriteString(chunk)
+		if err == nil {
+			onLine(strings.TrimRight(partial.String(), "\r\n"))
+			partial.Reset()
+			continue
+		}
+		if err != io.EOF {
+			file.Close()
+			file = nil
+			continue
+		}
+
+		// EOF: keep any partial line until the rest of it is written
+		time.Sleep(cogLogPollInterval)
+		if info, statErr := os.Stat(path); statErr != nil || info.Size() < offset || !sameFile(file, info) {
+			file.Close()
+			file = nil
+			offset = 0
+			partial.Reset()
+		}
+	}
+}
+
+// sameFile reports whether the open file is still the one at its path
+func sameFile(file *os.File, info os.FileInfo) bool {
+	openInfo, err := file.Stat()
+	return err == nil && os.SameFile(openInfo, info)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStreamCogLogEmitsNewLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strux-cage.log")
+	if err := os.WriteFile(path, []byte("old line\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	lines := make(chan string, 10)
+	SetEventEmitter(func(event string, data interface{}) {
+		if event == CogLogEvent {
+			lines <- data.(string)
+		}
+	})
+	defer SetEventEmitter(nil)
+
+	system := &SystemService{cogLogPath: path}
+	if err := system.StreamCogLog(); err != nil {
+		t.Fatalf("StreamCogLog failed: %v", err)
+	}
+	defer system.StopCogLog()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer file.Close()
+
+	// A line written in two parts is only emitted once it is complete
+	file.WriteString("CONSOLE LOG ")
+	time.Sleep(2 * cogLogPollInterval)
+	file.WriteString("hello\n")
+
+	select {
+	case line := <-lines:
+		if line != "CONSOLE LOG hello" {
+			t.Fatalf("unexpected line %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a log line")
+	}
+}
+
+func TestStreamCogLogWithoutLog(t *testing.T) {
+	system := &SystemService{cogLogPath: filepath.Join(t.TempDir(), "missing.log")}
+	if err := system.StreamCogLog(); err == nil {
+		t.Fatal("expected an error when the log does not exist")
+	}
+}
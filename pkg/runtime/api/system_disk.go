@@ -0,0 +1,220 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultTmpDir holds the logs written by strux.sh and strux-client.
+	defaultTmpDir = "/tmp"
+
+	// oversizedLogBytes is the size above which DiskCleanup rotates a log.
+	oversizedLogBytes = 5 * 1024 * 1024
+
+	// rotatedLogBytes is how much of the end of a rotated log is kept, in a
+	// ".1" file next to it.
+	rotatedLogBytes = 1024 * 1024
+
+	// staleBinaryAge protects a binary update that is still being written:
+	// leftover binaries younger than this are not removed.
+	staleBinaryAge = 10 * time.Minute
+)
+
+// Binary updates pushed in dev mode are written to a temporary file next to
+// the installed binary and renamed over it, or into the writable directory
+// when /strux is read-only. These mirror the Strux client's updater
+// (client-base binary.go and storage.go).
+const (
+	binaryTempName       = "main.new"
+	clientBinaryTempName = "client.new"
+	writableDirEnvVar    = "STRUX_WRITABLE_DIR"
+	defaultWritableDir   = "/strux-data/strux/writable"
+)
+
+// defaultStaleBinaryPaths returns the temporary files binary updates leave
+// behind when they are interrupted, in /strux and in the writable directory.
+func defaultStaleBinaryPaths() []string {
+	writableDir := strings.TrimSpace(os.Getenv(writableDirEnvVar))
+	if writableDir == "" {
+		writableDir = defaultWritableDir
+	}
+
+	var paths []string
+	for _, dir := range []string{"/strux", writableDir} {
+		paths = append(paths,
+			filepath.Join(dir, binaryTempName),
+			filepath.Join(dir, clientBinaryTempName),
+		)
+	}
+	return paths
+}
+
+// diskUsagePaths are the filesystems DiskUsage reports on.
+var diskUsagePaths = []string{"/strux", defaultTmpDir}
+
+// FilesystemUsage describes the space on the filesystem holding Path.
+type FilesystemUsage struct {
+	Path       string `json:"path"`
+	TotalBytes int64  `json:"totalBytes"`
+	UsedBytes  int64  `json:"usedBytes"`
+	FreeBytes  int64  `json:"freeBytes"` // available to the app, excluding reserved blocks
+}
+
+// DiskUsage summarizes disk space on the device.
+type DiskUsage struct {
+	Filesystems []FilesystemUsage `json:"filesystems"`
+	// ReclaimableBytes is roughly how much DiskCleanup would free right now.
+	ReclaimableBytes int64 `json:"reclaimableBytes"`
+}
+
+// DiskUsage returns the space used and available on the filesystems holding
+// /strux and /tmp, and how much DiskCleanup could reclaim.
+func (s *SystemService) DiskUsage() (DiskUsage, error) {
+	usage := DiskUsage{Filesystems: []FilesystemUsage{}}
+	for _, path := range diskUsagePaths {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(path, &stat); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return DiskUsage{}, fmt.Errorf("failed to read disk usage of %s: %w", path, err)
+		}
+		blockSize := int64(stat.Bsize)
+		total := int64(stat.Blocks) * blockSize
+		usage.Filesystems = append(usage.Filesystems, FilesystemUsage{
+			Path:       path,
+			TotalBytes: total,
+			UsedBytes:  total - int64(stat.Bfree)*blockSize,
+			FreeBytes:  int64(stat.Bavail) * blockSize,
+		})
+	}
+
+	for _, path := range s.staleBinaries() {
+		if info, err := os.Stat(path); err == nil {
+			usage.ReclaimableBytes += info.Size()
+		}
+	}
+	for _, path := range s.oversizedLogs() {
+		if info, err := os.Stat(path); err == nil {
+			usage.ReclaimableBytes += allocatedBytes(info) - rotatedLogBytes
+		}
+	}
+	return usage, nil
+}
+
+// DiskCleanup removes binaries left behind by dev-mode updates and rotates
+// logs in /tmp larger than 5 MB, keeping their last 1 MB in a ".1" file. It
+// returns the number of bytes freed. Leftover binaries modified in the last
+// ten minutes are kept, as an update may still be writing them.
+func (s *SystemService) DiskCleanup() (int64, error) {
+	var freed int64
+
+	for _, path := range s.staleBinaries() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return freed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		freed += info.Size()
+	}
+
+	for _, path := range s.oversizedLogs() {
+		n, err := rotateLog(path)
+		if err != nil {
+			return freed, fmt.Errorf("failed to rotate %s: %w", path, err)
+		}
+		freed += n
+	}
+
+	return freed, nil
+}
+
+// staleBinaries returns the leftover binaries old enough to remove
+func (s *SystemService) staleBinaries() []string {
+	paths := s.staleBinaryPaths
+	if paths == nil {
+		paths = defaultStaleBinaryPaths()
+	}
+
+	var stale []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || time.Since(info.ModTime()) < staleBinaryAge {
+			continue
+		}
+		stale = append(stale, path)
+	}
+	return stale
+}
+
+// oversizedLogs returns the Strux logs in the temp directory due for rotation
+func (s *SystemService) oversizedLogs() []string {
+	dir := s.tmpDir
+	if dir == "" {
+		dir = defaultTmpDir
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "strux-*.log"))
+	var logs []string
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || allocatedBytes(info) <= oversizedLogBytes {
+			continue
+		}
+		logs = append(logs, path)
+	}
+	return logs
+}
+
+// allocatedBytes returns the disk space a file takes up. A log truncated
+// under a writer that doesn't append turns into a sparse file as large as
+// before, so its size says nothing about the space rotating it would free.
+func allocatedBytes(info os.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int64(stat.Blocks) * 512
+	}
+	return info.Size()
+}
+
+// rotateLog copies the end of a log to path.1 and truncates the log in place,
+// returning the bytes freed. The log is truncated rather than renamed because
+// the processes writing it keep it open; they open it in append mode so their
+// next write starts at the new end.
+func rotateLog(path string) (int64, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Seek(info.Size()-rotatedLogBytes, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	rotated, err := os.OpenFile(path+".1", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	kept, err := io.Copy(rotated, file)
+	rotated.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := file.Truncate(0); err != nil {
+		return 0, err
+	}
+	return allocatedBytes(info) - kept, nil
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiskCleanup(t *testing.T) {
+	dir := t.TempDir()
+
+	staleBinary := filepath.Join(dir, "client.new")
+	freshBinary := filepath.Join(dir, "main.new")
+	for _, path := range []string{staleBinary, freshBinary} {
+		if err := os.WriteFile(path, make([]byte, 1000), 0755); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(staleBinary, old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	bigLog := filepath.Join(dir, "strux-cage.log")
+	content := append(bytes.Repeat([]byte("a"), oversizedLogBytes), bytes.Repeat([]byte("b"), rotatedLogBytes)...)
+	if err := os.WriteFile(bigLog, content, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	smallLog := filepath.Join(dir, "strux-backend.log")
+	if err := os.WriteFile(smallLog, []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	system := &SystemService{staleBinaryPaths: []string{staleBinary, freshBinary}, tmpDir: dir}
+
+	usage, err := system.DiskUsage()
+	if err != nil {
+		t.Fatalf("DiskUsage failed: %v", err)
+	}
+	if want := int64(1000 + oversizedLogBytes); usage.ReclaimableBytes != want {
+		t.Fatalf("expected %d reclaimable bytes, got %d", want, usage.ReclaimableBytes)
+	}
+
+	freed, err := system.DiskCleanup()
+	if err != nil {
+		t.Fatalf("DiskCleanup failed: %v", err)
+	}
+	if want := int64(1000 + oversizedLogBytes); freed != want {
+		t.Fatalf("expected %d bytes freed, got %d", want, freed)
+	}
+
+	if _, err := os.Stat(staleBinary); !os.IsNotExist(err) {
+		t.Fatal("expected the stale binary to be removed")
+	}
+	if _, err := os.Stat(freshBinary); err != nil {
+		t.Fatal("expected a recently written binary to be kept")
+	}
+	if info, _ := os.Stat(bigLog); info.Size() != 0 {
+		t.Fatalf("expected the oversized log to be truncated, size %d", info.Size())
+	}
+	rotated, err := os.ReadFile(bigLog + ".1")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !bytes.Equal(rotated, bytes.Repeat([]byte("b"), rotatedLogBytes)) {
+		t.Fatal("expected the rotated log to hold the end of the log")
+	}
+	if data, _ := os.ReadFile(smallLog); string(data) != "hello\n" {
+		t.Fatal("expected a small log to be left alone")
+	}
+}
+
+func TestDiskCleanupWithNonAppendWriter(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "strux-backend.log")
+
+	// A writer opened without O_APPEND, as a plain > shell redirect is
+	writer, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer writer.Close()
+	if _, err := writer.Write(bytes.Repeat([]byte("a"), oversizedLogBytes+rotatedLogBytes)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	system := &SystemService{staleBinaryPaths: []string{}, tmpDir: dir}
+	if _, err := system.DiskCleanup(); err != nil {
+		t.Fatalf("DiskCleanup failed: %v", err)
+	}
+
+	// The next write lands at the old offset, leaving a sparse file as large
+	// as before the rotation
+	if _, err := writer.Write([]byte("after\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if info, _ := os.Stat(logPath); info.Size() <= oversizedLogBytes {
+		t.Fatalf("expected a sparse log past the rotation size, size %d", info.Size())
+	}
+
+	usage, err := system.DiskUsage()
+	if err != nil {
+		t.Fatalf("DiskUsage failed: %v", err)
+	}
+	if usage.ReclaimableBytes != 0 {
+		t.Fatalf("expected nothing reclaimable from a sparse log, got %d", usage.ReclaimableBytes)
+	}
+	freed, err := system.DiskCleanup()
+	if err != nil {
+		t.Fatalf("DiskCleanup failed: %v", err)
+	}
+	if freed != 0 {
+		t.Fatalf("expected a sparse log not to be rotated again, freed %d", freed)
+	}
+	rotated, err := os.ReadFile(logPath + ".1")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if bytes.IndexByte(rotated, 0) >= 0 {
+		t.Fatal("expected the rotated log to hold no NUL bytes")
+	}
+}
+
+func TestDefaultStaleBinaryPaths(t *testing.T) {
+	t.Setenv(writableDirEnvVar, "/data/writable")
+
+	want := []string{"/strux/main.new", "/strux/client.new", "/data/writable/main.new", "/data/writable/client.new"}
+	if got := defaultStaleBinaryPaths(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
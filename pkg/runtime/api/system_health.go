@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultClientHealthPath is written every few seconds by strux-client.
+	defaultClientHealthPath = "/run/strux/client-health.json"
+
+	// clientHealthStaleAfter marks the client report stale when it has not been
+	// refreshed for several write intervals.
+	clientHealthStaleAfter = 15 * time.Second
+)
+
+// CogHealth describes the Cage/Cog process state reported by strux-client.
+type CogHealth struct {
+	Running bool `json:"running"`
+	PID     int  `json:"pid,omitempty"`
+}
+
+// DevServerHealth describes the dev server connection reported by strux-client.
+type DevServerHealth struct {
+	Connected bool   `json:"connected"`
+	Host      string `json:"host,omitempty"`
+	Port      int    `json:"port,omitempty"`
+	RTTMs     int64  `json:"rttMs,omitempty"` // round-trip time of the last WebSocket ping
+}
+
+// HealthReport summarizes device subsystem status in a single object.
+type HealthReport struct {
+	// Backend is always true: the report is served by the running app backend.
+	Backend bool `json:"backend"`
+	// ClientReported is false when strux-client has not written a report yet.
+	ClientReported bool `json:"clientReported"`
+	// Stale is true when the client report has not been refreshed recently.
+	Stale        bool             `json:"stale"`
+	Mode         string           `json:"mode"`
+	BackendReady bool             `json:"backendReady"`
+	NetworkReady bool             `json:"networkReady"`
+	Cog          CogHealth        `json:"cog"`
+	DevServer    *DevServerHealth `json:"devServer,omitempty"`
+	LogStreams   []string         `json:"logStreams"`
+	UpdatedAt    string           `json:"updatedAt,omitempty"`
+}
+
+// Health returns a consolidated report of backend readiness, network status,
+// Cog process status, dev server connection state, and active log streams, as
+// last reported by strux-client.
+func (s *SystemService) Health() (HealthReport, error) {
+	path := s.healthPath
+	if path == "" {
+		path = defaultClientHealthPath
+	}
+
+	report := HealthReport{LogStreams: []string{}}
+	exists, err := readOptionalJSON(path, &report)
+	if err != nil {
+		return HealthReport{}, fmt.Errorf("failed to read client health: %w", err)
+	}
+
+	report.Backend = true
+	report.ClientReported = exists
+	if report.LogStreams == nil {
+		report.LogStreams = []string{}
+	}
+
+	if exists {
+		updatedAt, err := time.Parse(time.RFC3339, report.UpdatedAt)
+		report.Stale = err != nil || time.Since(updatedAt) > clientHealthStaleAfter
+	}
+
+	return report, nil
+}
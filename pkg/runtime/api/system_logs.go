@@ -0,0 +1,29 @@
+package api
+
+import "fmt"
+
+// defaultClientLogsPath is mirrored every few seconds by strux-client.
+const defaultClientLogsPath = "/run/strux/client-logs.json"
+
+// RecentLogs returns the last n Strux client log lines, oldest first. n <= 0
+// returns every buffered line (the client keeps the most recent 500). Returns
+// an empty list if the client has not written any logs yet.
+func (s *SystemService) RecentLogs(n int) ([]string, error) {
+	path := s.logsPath
+	if path == "" {
+		path = defaultClientLogsPath
+	}
+
+	var lines []string
+	if _, err := readOptionalJSON(path, &lines); err != nil {
+		return nil, fmt.Errorf("failed to read client logs: %w", err)
+	}
+	if lines == nil {
+		lines = []string{}
+	}
+
+	if n > 0 && n < len(lines) {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
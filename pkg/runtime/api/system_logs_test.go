@@ -0,0 +1,43 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecentLogsReturnsNewestLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client-logs.json")
+	if err := os.WriteFile(path, []byte(`["one","two","three"]`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	system := &SystemService{logsPath: path}
+
+	lines, err := system.RecentLogs(2)
+	if err != nil {
+		t.Fatalf("RecentLogs failed: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != "two" || lines[1] != "three" {
+		t.Fatalf("unexpected lines: %v", lines)
+	}
+
+	lines, err = system.RecentLogs(0)
+	if err != nil {
+		t.Fatalf("RecentLogs failed: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected all lines, got %v", lines)
+	}
+}
+
+func TestRecentLogsWithoutClient(t *testing.T) {
+	system := &SystemService{logsPath: filepath.Join(t.TempDir(), "missing.json")}
+
+	lines, err := system.RecentLogs(10)
+	if err != nil {
+		t.Fatalf("RecentLogs failed: %v", err)
+	}
+	if lines == nil || len(lines) != 0 {
+		t.Fatalf("expected empty list, got %#v", lines)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localtimePath is the symlink into the zoneinfo database naming the system timezone.
+const localtimePath = "/etc/localtime"
+
+// validTimezoneName matches IANA zone names such as "UTC", "Europe/London" or
+// "America/Argentina/Buenos_Aires". Anything else is rejected before reaching a
+// system tool.
+var validTimezoneName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+)*$`)
+
+// validateTimezone checks that tz is a well-formed IANA zone name known to the
+// local zoneinfo database.
+func validateTimezone(tz string) error {
+	if !validTimezoneName.MatchString(tz) {
+		return fmt.Errorf("invalid timezone %q", tz)
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("unknown timezone %q: %w", tz, err)
+	}
+	return nil
+}
+
+// GetTimezone returns the system's IANA timezone name (e.g. "Europe/London").
+func (s *SystemService) GetTimezone() (string, error) {
+	if output, err := runSystemCommand("timedatectl", "show", "--property=Timezone", "--value"); err == nil {
+		if tz := strings.TrimSpace(output); tz != "" {
+			return tz, nil
+		}
+	}
+
+	target, err := os.Readlink(localtimePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "UTC", nil
+		}
+		return "", fmt.Errorf("failed to read timezone: %w", err)
+	}
+
+	if _, tz, ok := strings.Cut(filepath.ToSlash(target), "zoneinfo/"); ok {
+		return tz, nil
+	}
+	return "", fmt.Errorf("failed to read timezone: unexpected %s target %q", localtimePath, target)
+}
+
+// SetTimezone sets the system timezone via timedatectl. tz must be an IANA zone
+// name such as "America/New_York".
+func (s *SystemService) SetTimezone(tz string) error {
+	if err := validateTimezone(tz); err != nil {
+		return err
+	}
+	if _, err := runSystemCommand("timedatectl", "set-timezone", tz); err != nil {
+		return fmt.Errorf("failed to set timezone: %w", err)
+	}
+	return nil
+}
+
+// SetTime sets the system clock from an RFC 3339 timestamp (e.g.
+// "2025-01-02T15:04:05Z"). timedatectl is tried first; date is used as a
+// fallback on systems without it. timedatectl refuses while NTP sync is active.
+func (s *SystemService) SetTime(rfc3339 string) error {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(rfc3339))
+	if err != nil {
+		return fmt.Errorf("invalid time %q: expected RFC 3339", rfc3339)
+	}
+
+	// timedatectl interprets the value in the system timezone, so hand it UTC
+	// with an explicit suffix to avoid ambiguity.
+	_, tdErr := runSystemCommand("timedatectl", "set-time", t.UTC().Format("2006-01-02 15:04:05")+" UTC")
+	if tdErr == nil {
+		return nil
+	}
+
+	if _, err := runSystemCommand("date", "-u", "-s", "@"+strconv.FormatInt(t.Unix(), 10)); err != nil {
+		return fmt.Errorf("failed to set time: %v; %w", tdErr, err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package api
+
+import "testing"
+
+func TestValidateTimezoneRejectsUnsafeNames(t *testing.T) {
+	for _, tz := range []string{"UTC", "Europe/London", "America/Argentina/Buenos_Aires", "Etc/GMT+5"} {
+		if err := validateTimezone(tz); err != nil {
+			t.Fatalf("validateTimezone(%q) failed: %v", tz, err)
+		}
+	}
+
+	for _, tz := range []string{"", "../etc/passwd", "Europe/London; reboot", "-h", "Not/AZone"} {
+		if err := validateTimezone(tz); err == nil {
+			t.Fatalf("expected validateTimezone(%q) to fail", tz)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package runtime
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// authMethod is the call a connection opens with when the runtime requires an
+// auth token:
+//
+//	JS -> Go: {"id":"1","method":"__auth","params":["<token>"]}
+//	Go -> JS: {"id":"1","result":true}
+//
+// It comes before the channel handshake on the WPE extension's connections.
+const authMethod = "__auth"
+
+// writeAuthToken writes the auth token to RuntimeOptions.AuthTokenFile,
+// generating one first if none was configured
+func (rt *Runtime) writeAuthToken() error {
+	if rt.opts.AuthTokenFile == "" {
+		return nil
+	}
+	if rt.authToken == "" {
+		token := make([]byte, 32)
+		if _, err := rand.Read(token); err != nil {
+			return fmt.Errorf("failed to generate auth token: %w", err)
+		}
+		rt.authToken = hex.EncodeToString(token)
+	}
+
+	os.Remove(rt.opts.AuthTokenFile)
+	if err := os.WriteFile(rt.opts.AuthTokenFile, []byte(rt.authToken), 0600); err != nil {
+		return fmt.Errorf("failed to write auth token: %w", err)
+	}
+	return nil
+}
+
+// authenticate checks that the first message on a connection is an __auth
+// call with the runtime's token and answers it. On failure the connection
+// gets an Unauthorized error and should be closed.
+func (rt *Runtime) authenticate(firstMsg json.RawMessage, encoder *json.Encoder) bool {
+	var msg Message
+	json.Unmarshal(firstMsg, &msg)
+
+	if msg.Method != authMethod {
+		fmt.Printf("Strux Runtime: Rejected connection that did not authenticate\n")
+		encoder.Encode(Response{ID: msg.ID, Error: "authentication required", Code: string(CodeUnauthorized)})
+		return false
+	}
+
+	var params []string
+	json.Unmarshal(msg.Params, &params)
+	if len(params) != 1 || subtle.ConstantTimeCompare([]byte(params[0]), []byte(rt.authToken)) != 1 {
+		fmt.Printf("Strux Runtime: Rejected connection with an invalid auth token\n")
+		encoder.Encode(Response{ID: msg.ID, Error: "invalid auth token", Code: string(CodeUnauthorized)})
+		return false
+	}
+	return encoder.Encode(Response{ID: msg.ID, Result: true}) == nil
+}
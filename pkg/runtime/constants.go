@@ -0,0 +1,28 @@
+package runtime
+
+// RegisterConstants makes Go constants readable from the frontend through the
+// __constants call, so limits and flags aren't duplicated in TypeScript. Call
+// it at startup with the values `strux types` lists under "constants", e.g.
+// rt.RegisterConstants(map[string]interface{}{"MaxVolume": MaxVolume}).
+// Registering a name again replaces its value.
+func (rt *Runtime) RegisterConstants(constants map[string]interface{}) {
+	rt.constantsMu.Lock()
+	defer rt.constantsMu.Unlock()
+	if rt.constants == nil {
+		rt.constants = make(map[string]interface{}, len(constants))
+	}
+	for name, value := range constants {
+		rt.constants[name] = value
+	}
+}
+
+// Constants returns a copy of the registered constants
+func (rt *Runtime) Constants() map[string]interface{} {
+	rt.constantsMu.RLock()
+	defer rt.constantsMu.RUnlock()
+	constants := make(map[string]interface{}, len(rt.constants))
+	for name, value := range rt.constants {
+		constants[name] = value
+	}
+	return constants
+}
@@ -0,0 +1,68 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode identifies the kind of a failed call. It is sent alongside the
+// error message so the frontend can branch on the kind of failure instead of
+// parsing English.
+type ErrorCode string
+
+const (
+	// CodeMethodNotFound means no bound method or extension method has the name
+	CodeMethodNotFound ErrorCode = "MethodNotFound"
+	// CodeInvalidParams means the parameters could not be decoded for the method
+	CodeInvalidParams ErrorCode = "InvalidParams"
+	// CodeFieldNotFound means a field path does not resolve to a bound field
+	CodeFieldNotFound ErrorCode = "FieldNotFound"
+	// CodeInternal means the runtime failed, e.g. a method panicked
+	CodeInternal ErrorCode = "Internal"
+	// CodeTimeout means the call did not finish in time
+	CodeTimeout ErrorCode = "Timeout"
+	// CodeBusy means the method was already running as many calls as it allows
+	CodeBusy ErrorCode = "Busy"
+	// CodeUnauthorized means the connection did not authenticate with the
+	// runtime's auth token
+	CodeUnauthorized ErrorCode = "Unauthorized"
+	// CodeAppError is the default for errors returned by app methods
+	CodeAppError ErrorCode = "AppError"
+)
+
+// CodedError is implemented by app errors that want the frontend to see their
+// own code instead of AppError:
+//
+//	type NotPairedError struct{}
+//
+//	func (NotPairedError) Error() string     { return "device is not paired" }
+//	func (NotPairedError) ErrorCode() string { return "NotPaired" }
+type CodedError interface {
+	error
+	ErrorCode() string
+}
+
+// callError is a runtime failure tagged with its error code
+type callError struct {
+	code ErrorCode
+	err  error
+}
+
+func (e *callError) Error() string     { return e.err.Error() }
+func (e *callError) Unwrap() error     { return e.err }
+func (e *callError) ErrorCode() string { return string(e.code) }
+
+// codedErrorf formats an error tagged with code
+func codedErrorf(code ErrorCode, format string, args ...interface{}) error {
+	return &callError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+// errorCode returns the code reported for err: the code of the first
+// CodedError in its chain, or AppError for plain errors.
+func errorCode(err error) string {
+	var coded CodedError
+	if errors.As(err, &coded) && coded.ErrorCode() != "" {
+		return coded.ErrorCode()
+	}
+	return string(CodeAppError)
+}
@@ -0,0 +1,111 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventInfo describes an event registered with RegisterEvent
+type EventInfo struct {
+	Name   string `json:"name"`
+	GoType string `json:"goType,omitempty"` // empty for events without a payload
+	TSType string `json:"tsType"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// RegisterEvent declares an event the app emits and the shape of its payload,
+// given as an example value such as Progress{} (or nil for an event without
+// data). Registered events are listed by __getEvents, and `strux types` types
+// the frontend's strux.ipc.on callbacks for them. Emitting an event that was
+// never registered still works; its payload is typed any.
+func (rt *Runtime) RegisterEvent(event string, payload interface{}) {
+	rt.events.typesMu.Lock()
+	defer rt.events.typesMu.Unlock()
+	rt.events.types[event] = reflect.TypeOf(payload)
+}
+
+// Events returns the registered events, sorted by name
+func (rt *Runtime) Events() []EventInfo {
+	rt.events.typesMu.RLock()
+	defer rt.events.typesMu.RUnlock()
+
+	events := make([]EventInfo, 0, len(rt.events.types))
+	for name, t := range rt.events.types {
+		info := EventInfo{Name: name, TSType: "void"}
+		if t != nil {
+			info.GoType = t.String()
+			info.TSType = payloadTSType(t, map[reflect.Type]bool{})
+		}
+		events = append(events, info)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+	return events
+}
+
+// payloadTSType maps a payload type to the TypeScript type of its JSON
+// encoding. Structs are spelled out field by field, named by their json tags,
+// since the frontend has no declaration for them.
+func payloadTSType(t reflect.Type, seen map[reflect.Type]bool) string {
+	switch t {
+	case timeType:
+		return "string"
+	case durationType:
+		return "number"
+	case rawMessageType:
+		return "any"
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return payloadTSType(t.Elem(), seen)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // base64
+		}
+		return payloadTSType(t.Elem(), seen) + "[]"
+	case reflect.Array:
+		return payloadTSType(t.Elem(), seen) + "[]"
+	case reflect.Map:
+		return fmt.Sprintf("Record<%s, %s>", goTypeToTS(t.Key()), payloadTSType(t.Elem(), seen))
+	case reflect.Struct:
+		if seen[t] {
+			return "object" // recursive type
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		var fields []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" && opts == "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			optional := ""
+			if strings.Contains(","+opts+",", ",omitempty,") {
+				optional = "?"
+			}
+			fields = append(fields, fmt.Sprintf("%s%s: %s", name, optional, payloadTSType(field.Type, seen)))
+		}
+		if len(fields) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(fields, "; ") + " }"
+	}
+	return goTypeToTS(t)
+}
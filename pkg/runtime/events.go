@@ -3,7 +3,9 @@ package runtime
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
+	"reflect"
 	"sync"
 	"sync/atomic"
 )
@@ -15,6 +17,34 @@ type EventMessage struct {
 	Data  interface{} `json:"data,omitempty"`
 }
 
+// eventNotificationMethod is the method of the unsolicited messages Emit
+// writes to method-call connections
+const eventNotificationMethod = "__event"
+
+// eventNotification delivers an event on a method-call connection. It has no
+// ID, so it can't be mistaken for a response.
+type eventNotification struct {
+	Method string `json:"method"`
+	Params struct {
+		Event string      `json:"event"`
+		Data  interface{} `json:"data,omitempty"`
+	} `json:"params"`
+}
+
+// ipcClient is a method-call connection that also receives the events sent
+// with Emit. Writes are serialized so an event never interleaves with a
+// response.
+type ipcClient struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (c *ipcClient) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.w.Write(p)
+}
+
 // EventHandler is a registered Go-side handler for events from JavaScript
 type EventHandler struct {
 	ID       uint64
@@ -27,22 +57,34 @@ type eventState struct {
 	eventConns   map[net.Conn]struct{}
 	eventConnsMu sync.RWMutex
 
+	// Method-call connections that receive events as __event notifications
+	ipcClients   map[*ipcClient]struct{}
+	ipcClientsMu sync.RWMutex
+
 	// Go-side event listeners (for events coming from JS)
 	handlers   map[string][]EventHandler // event name -> handlers
 	handlersMu sync.RWMutex
 
 	// Auto-incrementing handler ID
 	nextHandlerID atomic.Uint64
+
+	// Payload types of events registered with RegisterEvent
+	types   map[string]reflect.Type
+	typesMu sync.RWMutex
 }
 
 func newEventState() *eventState {
 	return &eventState{
 		eventConns: make(map[net.Conn]struct{}),
+		ipcClients: make(map[*ipcClient]struct{}),
 		handlers:   make(map[string][]EventHandler),
+		types:      make(map[string]reflect.Type),
 	}
 }
 
-// Emit sends an event to all connected JavaScript frontends
+// Emit sends an event to all connected JavaScript frontends, and as an
+// __event notification to every method-call connection that didn't open as
+// a WPE extension channel
 func (rt *Runtime) Emit(event string, data interface{}) {
 	msg := EventMessage{
 		Type:  "event",
@@ -73,6 +115,51 @@ func (rt *Runtime) Emit(event string, data interface{}) {
 			conn.Close()
 		}
 	}
+
+	rt.notifyIPCClients(event, data)
+}
+
+// notifyIPCClients writes an event to the registered method-call
+// connections. A failed write is left for the connection's read loop to
+// notice, which deregisters the client when it exits.
+func (rt *Runtime) notifyIPCClients(event string, data interface{}) {
+	rt.events.ipcClientsMu.RLock()
+	clients := make([]*ipcClient, 0, len(rt.events.ipcClients))
+	for client := range rt.events.ipcClients {
+		clients = append(clients, client)
+	}
+	rt.events.ipcClientsMu.RUnlock()
+	if len(clients) == 0 {
+		return
+	}
+
+	notification := eventNotification{Method: eventNotificationMethod}
+	notification.Params.Event = event
+	notification.Params.Data = data
+	jsonData, err := json.Marshal(notification)
+	if err != nil {
+		fmt.Printf("Strux Runtime: Failed to marshal event %s: %v\n", event, err)
+		return
+	}
+	jsonData = append(jsonData, '\n')
+
+	for _, client := range clients {
+		client.Write(jsonData)
+	}
+}
+
+// addIPCClient registers a method-call connection for event notifications
+func (rt *Runtime) addIPCClient(client *ipcClient) {
+	rt.events.ipcClientsMu.Lock()
+	defer rt.events.ipcClientsMu.Unlock()
+	rt.events.ipcClients[client] = struct{}{}
+}
+
+// removeIPCClient deregisters a connection added with addIPCClient
+func (rt *Runtime) removeIPCClient(client *ipcClient) {
+	rt.events.ipcClientsMu.Lock()
+	defer rt.events.ipcClientsMu.Unlock()
+	delete(rt.events.ipcClients, client)
 }
 
 // On registers a handler for events emitted from JavaScript.
@@ -114,6 +201,7 @@ func (rt *Runtime) handleEventConnection(conn net.Conn) {
 		rt.events.eventConnsMu.Lock()
 		delete(rt.events.eventConns, conn)
 		rt.events.eventConnsMu.Unlock()
+		rt.removeFieldSubscriptions(conn)
 		conn.Close()
 	}()
 
@@ -129,6 +217,14 @@ func (rt *Runtime) handleEventConnection(conn net.Conn) {
 			continue
 		}
 
+		// Field subscriptions are handled by the runtime itself
+		if rt.handleFieldSubscriptionEvent(conn, msg) {
+			continue
+		}
+		if rt.handleStreamEvent(conn, msg) {
+			continue
+		}
+
 		// Dispatch to registered Go handlers
 		rt.events.handlersMu.RLock()
 		handlers := make([]EventHandler, len(rt.events.handlers[msg.Event]))
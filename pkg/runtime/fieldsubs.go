@@ -0,0 +1,227 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Field subscription protocol (on the events channel):
+//
+//	JS -> Go: {"type":"event","event":"__subscribeField","data":"Settings.Volume"}
+//	JS -> Go: {"type":"event","event":"__unsubscribeField","data":"Settings.Volume"}
+//	Go -> JS: {"type":"event","event":"__fieldChanged","data":{"field":"Settings.Volume","value":42}}
+//
+// data may also be a list of field paths. Changes are only pushed to the
+// connections subscribed to that field, except for changes made through
+// __setField, which are broadcast to every client with the previous value:
+//
+//	Go -> JS: {"type":"event","event":"__fieldChanged","data":{"field":"Settings.Volume","value":42,"oldValue":30}}
+const (
+	subscribeFieldEvent   = "__subscribeField"
+	unsubscribeFieldEvent = "__unsubscribeField"
+	fieldChangedEvent     = "__fieldChanged"
+)
+
+// FieldChange is the payload of a __fieldChanged event
+type FieldChange struct {
+	Field string      `json:"field"`
+	Value interface{} `json:"value"`
+	// OldValue is only set for changes made through __setField
+	OldValue interface{} `json:"oldValue,omitempty"`
+}
+
+// fieldSubscriptions tracks which event connections observe which fields
+type fieldSubscriptions struct {
+	mu    sync.Mutex
+	conns map[net.Conn]map[string]struct{} // connection -> subscribed field paths
+	last  map[string][]byte                // field path -> JSON of the last value pushed
+}
+
+func newFieldSubscriptions() *fieldSubscriptions {
+	return &fieldSubscriptions{
+		conns: make(map[net.Conn]map[string]struct{}),
+		last:  make(map[string][]byte),
+	}
+}
+
+// fieldPathsFromEventData accepts a single field path or a list of paths
+func fieldPathsFromEventData(data interface{}) []string {
+	switch v := data.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			if path, ok := item.(string); ok {
+				paths = append(paths, path)
+			}
+		}
+		return paths
+	}
+	return nil
+}
+
+// handleFieldSubscriptionEvent handles subscribe/unsubscribe events from an
+// events connection. Returns false if the event is not a subscription event.
+func (rt *Runtime) handleFieldSubscriptionEvent(conn net.Conn, msg EventMessage) bool {
+	switch msg.Event {
+	case subscribeFieldEvent:
+		for _, path := range fieldPathsFromEventData(msg.Data) {
+			value, err := rt.getField(path)
+			if err != nil {
+				fmt.Printf("Strux Runtime: Cannot subscribe to field %s: %v\n", path, err)
+				continue
+			}
+
+			subs := rt.fieldSubs
+			subs.mu.Lock()
+			if subs.conns[conn] == nil {
+				subs.conns[conn] = make(map[string]struct{})
+			}
+			subs.conns[conn][path] = struct{}{}
+			subs.last[path] = snapshotValue(value)
+			subs.mu.Unlock()
+
+			// Send the current value so the subscriber starts in sync
+			writeEvent(conn, fieldChangedEvent, FieldChange{Field: path, Value: value})
+		}
+		return true
+
+	case unsubscribeFieldEvent:
+		subs := rt.fieldSubs
+		subs.mu.Lock()
+		for _, path := range fieldPathsFromEventData(msg.Data) {
+			delete(subs.conns[conn], path)
+		}
+		if len(subs.conns[conn]) == 0 {
+			delete(subs.conns, conn)
+		}
+		subs.pruneLocked()
+		subs.mu.Unlock()
+		return true
+	}
+	return false
+}
+
+// removeFieldSubscriptions drops all subscriptions held by a closed connection
+func (rt *Runtime) removeFieldSubscriptions(conn net.Conn) {
+	rt.fieldSubs.mu.Lock()
+	delete(rt.fieldSubs.conns, conn)
+	rt.fieldSubs.pruneLocked()
+	rt.fieldSubs.mu.Unlock()
+}
+
+// pruneLocked forgets last values for fields nobody is subscribed to anymore.
+// Callers must hold s.mu.
+func (s *fieldSubscriptions) pruneLocked() {
+	for path := range s.last {
+		if len(s.subscribersOf(path)) == 0 {
+			delete(s.last, path)
+		}
+	}
+}
+
+// subscribersOf returns the connections subscribed to a field path
+func (s *fieldSubscriptions) subscribersOf(path string) []net.Conn {
+	var conns []net.Conn
+	for conn, paths := range s.conns {
+		if _, ok := paths[path]; ok {
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
+// NotifyFieldChanged pushes the current value of a field to every connection
+// subscribed to it. Call this after changing a field from Go code so
+// subscribed frontends update without polling.
+func (rt *Runtime) NotifyFieldChanged(path string) {
+	value, err := rt.getField(path)
+	if err != nil {
+		return
+	}
+
+	rt.fieldSubs.mu.Lock()
+	conns := rt.fieldSubs.subscribersOf(path)
+	if len(conns) > 0 {
+		rt.fieldSubs.last[path] = snapshotValue(value)
+	}
+	rt.fieldSubs.mu.Unlock()
+
+	for _, conn := range conns {
+		writeEvent(conn, fieldChangedEvent, FieldChange{Field: path, Value: value})
+	}
+}
+
+// broadcastFieldChange emits a __fieldChanged event for a field set through
+// __setField to every connected client, so windows bound to the same field
+// stay in sync. Subscribers receive the broadcast too, so the last pushed
+// value is updated to keep checkSubscribedFields from repeating it.
+func (rt *Runtime) broadcastFieldChange(path string, value, oldValue interface{}) {
+	rt.fieldSubs.mu.Lock()
+	if _, ok := rt.fieldSubs.last[path]; ok {
+		rt.fieldSubs.last[path] = snapshotValue(value)
+	}
+	rt.fieldSubs.mu.Unlock()
+
+	rt.Emit(fieldChangedEvent, FieldChange{Field: path, Value: value, OldValue: oldValue})
+}
+
+// checkSubscribedFields compares every subscribed field against the last value
+// pushed and notifies subscribers of any that changed. Called after method
+// calls, since those are the usual way app state changes.
+func (rt *Runtime) checkSubscribedFields() {
+	rt.fieldSubs.mu.Lock()
+	if len(rt.fieldSubs.conns) == 0 {
+		rt.fieldSubs.mu.Unlock()
+		return
+	}
+	paths := make([]string, 0, len(rt.fieldSubs.last))
+	for path := range rt.fieldSubs.last {
+		paths = append(paths, path)
+	}
+	rt.fieldSubs.mu.Unlock()
+
+	for _, path := range paths {
+		value, err := rt.getField(path)
+		if err != nil {
+			continue
+		}
+
+		snapshot := snapshotValue(value)
+
+		rt.fieldSubs.mu.Lock()
+		changed := !bytes.Equal(rt.fieldSubs.last[path], snapshot)
+		var conns []net.Conn
+		if changed {
+			rt.fieldSubs.last[path] = snapshot
+			conns = rt.fieldSubs.subscribersOf(path)
+		}
+		rt.fieldSubs.mu.Unlock()
+
+		for _, conn := range conns {
+			writeEvent(conn, fieldChangedEvent, FieldChange{Field: path, Value: value})
+		}
+	}
+}
+
+// snapshotValue encodes a field value for change comparison. JSON is used
+// rather than the value itself so in-place slice/map mutations are detected.
+func snapshotValue(value interface{}) []byte {
+	data, _ := json.Marshal(value)
+	return data
+}
+
+// writeEvent writes a single event message to one connection
+func writeEvent(conn net.Conn, event string, data interface{}) error {
+	jsonData, err := json.Marshal(EventMessage{Type: "event", Event: event, Data: data})
+	if err != nil {
+		fmt.Printf("Strux Runtime: Failed to marshal event %s: %v\n", event, err)
+		return err
+	}
+	_, err = conn.Write(append(jsonData, '\n'))
+	return err
+}
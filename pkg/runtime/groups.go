@@ -0,0 +1,190 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// struxTagName is the struct tag used for Strux field options, e.g.
+//
+//	Volume int `strux:"group=settings"`
+const struxTagName = "strux"
+
+// fieldGroup is a named set of top-level app fields that the frontend reads
+// and writes as a single object (e.g. a "settings" form).
+type fieldGroup struct {
+	name   string
+	fields map[string]int // field name -> index in the app struct
+}
+
+// parseStruxTag parses a `strux:"key=value,flag"` tag into its options.
+// Flags without a value map to an empty string.
+func parseStruxTag(tag string) map[string]string {
+	options := make(map[string]string)
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		options[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return options
+}
+
+// groupAccessorName returns the Get/Set method suffix for a group name,
+// e.g. "settings" -> "Settings".
+func groupAccessorName(group string) string {
+	if group == "" {
+		return ""
+	}
+	return strings.ToUpper(group[:1]) + group[1:]
+}
+
+// buildFieldGroups collects `strux:"group=..."` fields on the app root and binds
+// Get<Group>/Set<Group> methods for each group on the root node. Accessors are
+// skipped if the app already defines a method with the same name.
+func (rt *Runtime) buildFieldGroups() {
+	rt.groups = make(map[string]*fieldGroup)
+	if rt.tree == nil {
+		return
+	}
+
+	typ := rt.tree.typ
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		group := parseStruxTag(field.Tag.Get(struxTagName))["group"]
+		if group == "" {
+			continue
+		}
+		if rt.groups[group] == nil {
+			rt.groups[group] = &fieldGroup{name: group, fields: make(map[string]int)}
+		}
+		rt.groups[group].fields[field.Name] = i
+	}
+
+	getterType := reflect.TypeOf(func() (map[string]interface{}, error) { return nil, nil })
+	setterType := reflect.TypeOf(func(map[string]interface{}) error { return nil })
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+
+	for name := range rt.groups {
+		group := name
+		suffix := groupAccessorName(group)
+
+		if _, exists := rt.tree.methods["Get"+suffix]; !exists {
+			getter := reflect.MakeFunc(getterType, func(args []reflect.Value) []reflect.Value {
+				values, err := rt.GetGroup(group)
+				errValue := reflect.Zero(errorType)
+				if err != nil {
+					errValue = reflect.ValueOf(err)
+				}
+				return []reflect.Value{reflect.ValueOf(values), errValue}
+			})
+			rt.tree.methods["Get"+suffix] = getter
+			rt.methods["Get"+suffix] = getter
+		}
+
+		if _, exists := rt.tree.methods["Set"+suffix]; !exists {
+			setter := reflect.MakeFunc(setterType, func(args []reflect.Value) []reflect.Value {
+				values, _ := args[0].Interface().(map[string]interface{})
+				errValue := reflect.Zero(errorType)
+				if err := rt.SetGroup(group, values); err != nil {
+					errValue = reflect.ValueOf(err)
+				}
+				return []reflect.Value{errValue}
+			})
+			rt.tree.methods["Set"+suffix] = setter
+			rt.methods["Set"+suffix] = setter
+		}
+	}
+}
+
+// GetGroup returns the current values of all fields in a field group, keyed by
+// Go field name.
+func (rt *Runtime) GetGroup(name string) (map[string]interface{}, error) {
+	group, ok := rt.groups[name]
+	if !ok {
+		return nil, codedErrorf(CodeFieldNotFound, "field group %s not found", name)
+	}
+
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	values := make(map[string]interface{}, len(group.fields))
+	for fieldName, idx := range group.fields {
+		values[fieldName] = rt.tree.value.Field(idx).Interface()
+	}
+	return values, nil
+}
+
+// SetGroup updates fields in a field group as a single operation. Every value is
+// converted before any field is written, so either all fields change or none do.
+// Fields absent from values keep their current value. Like __setField, each
+// written field is broadcast to connected clients and a new Title is sent to
+// the compositor.
+func (rt *Runtime) SetGroup(name string, values map[string]interface{}) error {
+	group, ok := rt.groups[name]
+	if !ok {
+		return codedErrorf(CodeFieldNotFound, "field group %s not found", name)
+	}
+
+	converted := make(map[string]reflect.Value, len(values))
+	for fieldName, value := range values {
+		idx, ok := group.fields[fieldName]
+		if !ok {
+			return codedErrorf(CodeFieldNotFound, "field %s is not in group %s", fieldName, name)
+		}
+		fieldValue := rt.tree.value.Field(idx)
+		if !fieldValue.CanSet() {
+			return codedErrorf(CodeInvalidParams, "field %s cannot be set", fieldName)
+		}
+		newValue, err := convertFieldValue(value, fieldValue.Type())
+		if err != nil {
+			return fmt.Errorf("field %s: %w", fieldName, err)
+		}
+		converted[fieldName] = newValue
+	}
+
+	type change struct{ value, oldValue interface{} }
+	changes := make(map[string]change, len(converted))
+
+	rt.mu.Lock()
+	for fieldName, newValue := range converted {
+		fieldValue := rt.tree.value.Field(group.fields[fieldName])
+		oldValue := fieldValue.Interface()
+		fieldValue.Set(newValue)
+		changes[fieldName] = change{value: fieldValue.Interface(), oldValue: oldValue}
+	}
+	rt.mu.Unlock()
+
+	for fieldName, c := range changes {
+		rt.broadcastFieldChange(fieldName, c.value, c.oldValue)
+	}
+	if _, ok := changes[titleField]; ok {
+		go rt.syncTitle()
+	}
+	return nil
+}
+
+// convertFieldValue converts a decoded JSON value to the given field type.
+func convertFieldValue(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	newValue := reflect.ValueOf(value)
+	if newValue.IsValid() && newValue.Type() == typ {
+		return newValue, nil
+	}
+
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return reflect.Value{}, codedErrorf(CodeInvalidParams, "failed to convert value: %w", err)
+	}
+	newValue, err = decodeParam(jsonData, typ)
+	if err != nil {
+		return reflect.Value{}, codedErrorf(CodeInvalidParams, "failed to convert value to %s: %w", typ, err)
+	}
+	return newValue, nil
+}
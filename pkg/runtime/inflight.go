@@ -0,0 +1,106 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InflightCall describes a method call that has not returned yet
+type InflightCall struct {
+	ID        string    `json:"id"`        // request ID chosen by the caller
+	Method    string    `json:"method"`    // method path, e.g. "Settings.Save"
+	Session   uint64    `json:"session"`   // ID of the calling connection's session
+	StartedAt time.Time `json:"startedAt"` // when the runtime started the call
+}
+
+// inflightCall is a tracked call and the cancel function of its context
+type inflightCall struct {
+	info   InflightCall
+	cancel context.CancelFunc
+}
+
+// inflightCalls tracks running method calls so they can be listed and canceled
+type inflightCalls struct {
+	mu    sync.Mutex
+	calls map[*inflightCall]struct{}
+}
+
+func newInflightCalls() *inflightCalls {
+	return &inflightCalls{calls: make(map[*inflightCall]struct{})}
+}
+
+// trackCall registers a call and returns the context it runs with, plus a
+// function to call once it returns. The context is canceled if the calling
+// connection is lost. Calls without a request ID (fire-and-forget) are tracked
+// too, with an empty ID.
+func (rt *Runtime) trackCall(msg Message, session *Session) (context.Context, func()) {
+	parent := context.Background()
+	if session != nil {
+		parent = session.ctx
+	}
+	ctx, cancel := context.WithCancel(parent)
+	call := &inflightCall{
+		info: InflightCall{
+			ID:        msg.ID,
+			Method:    msg.Method,
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+	if session != nil {
+		call.info.Session = session.ID()
+	}
+
+	rt.inflight.mu.Lock()
+	rt.inflight.calls[call] = struct{}{}
+	rt.inflight.mu.Unlock()
+
+	return ctx, func() {
+		rt.inflight.mu.Lock()
+		delete(rt.inflight.calls, call)
+		rt.inflight.mu.Unlock()
+		cancel()
+	}
+}
+
+// InflightCalls returns the method calls currently running, oldest first.
+// Useful for finding a hung method on a live device.
+func (rt *Runtime) InflightCalls() []InflightCall {
+	rt.inflight.mu.Lock()
+	calls := make([]InflightCall, 0, len(rt.inflight.calls))
+	for call := range rt.inflight.calls {
+		calls = append(calls, call.info)
+	}
+	rt.inflight.mu.Unlock()
+
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].StartedAt.Before(calls[j].StartedAt)
+	})
+	return calls
+}
+
+// CancelCall cancels the context of the running call with the given request
+// ID. Request IDs are chosen per connection, so session narrows the match to
+// one connection; 0 matches calls from any connection. Methods only stop early
+// if they take a context.Context and watch it. Returns the number of calls
+// canceled.
+func (rt *Runtime) CancelCall(id string, session uint64) int {
+	rt.inflight.mu.Lock()
+	defer rt.inflight.mu.Unlock()
+
+	canceled := 0
+	for call := range rt.inflight.calls {
+		if call.info.ID != id || (session != 0 && call.info.Session != session) {
+			continue
+		}
+		call.cancel()
+		canceled++
+	}
+	if canceled > 0 {
+		fmt.Printf("Strux Runtime: Canceled %d call(s) with request ID %s\n", canceled, id)
+	}
+	return canceled
+}
@@ -0,0 +1,291 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ReadyHook can be implemented by the app struct to run initialization after
+// the IPC server is listening but before the first frontend call is served
+// (e.g. warming caches or opening a database). Returning an error aborts Start.
+type ReadyHook interface {
+	OnReady() error
+}
+
+// ShutdownHook can be implemented by the app struct to flush state or release
+// resources when the runtime stops. It is called once from Stop, after the IPC
+// listener has been closed so no new connections are accepted.
+type ShutdownHook interface {
+	OnShutdown()
+}
+
+// StartupHook can be implemented by the app struct to acquire resources tied
+// to the runtime's lifetime (open a database, start a timer). Startup is called
+// from Start before the first connection is accepted and before OnReady;
+// returning an error aborts Start. ctx is canceled when the runtime stops, so
+// background work started here can watch it.
+type StartupHook interface {
+	Startup(ctx context.Context) error
+}
+
+// StopHook is the counterpart of StartupHook: Shutdown is called once from
+// Stop, after OnShutdown, to release what Startup acquired. It is only called
+// if Startup succeeded (or the app has no Startup), and its error is returned
+// by StopContext.
+type StopHook interface {
+	Shutdown() error
+}
+
+// OptionalParamsProvider can be implemented by the app struct to let selected
+// methods be called with fewer arguments than they declare. It maps a method
+// path as called from the frontend (e.g. "Greet" or "Settings.Save") to the
+// number of required leading parameters; missing trailing parameters are
+// zero-filled. Methods that are not listed keep strict arity.
+type OptionalParamsProvider interface {
+	OptionalParams() map[string]int
+}
+
+// FireAndForgetProvider can be implemented by the app struct to mark methods
+// that return nothing useful (logging, telemetry) as fire-and-forget. It lists
+// method paths as called from the frontend (e.g. "Log" or "Metrics.Track").
+// The frontend sends these calls without an ID and resolves immediately; the
+// runtime sends no response and only logs failures.
+type FireAndForgetProvider interface {
+	FireAndForget() []string
+}
+
+// MaxConcurrentProvider can be implemented by the app struct to cap how many
+// calls of expensive methods (image processing, exports) run at once across
+// all connections. It maps method paths as called from the frontend (e.g.
+// "Resize" or "Media.Transcode") to the limit. Excess calls wait for a slot,
+// or fail with a Busy error if RuntimeOptions.RejectBusyCalls is set.
+type MaxConcurrentProvider interface {
+	MaxConcurrent() map[string]int
+}
+
+// ReturnNamesProvider can be implemented by the app struct to return a
+// method's results to the frontend as an object keyed by name, e.g.
+// {count, total}, instead of a positional array. It maps method paths as
+// called from the frontend (e.g. "Stats" or "Media.Probe") to one name per
+// non-error result; Go does not keep result names at run time, so they are
+// listed here rather than read from the signature.
+type ReturnNamesProvider interface {
+	ReturnNames() map[string][]string
+}
+
+// lifecycleMethods are app methods reserved for runtime hooks.
+// They are invoked by the runtime and never exposed to the frontend.
+var lifecycleMethods = map[string]bool{
+	"OnReady":        true,
+	"OnShutdown":     true,
+	"OptionalParams": true,
+	"FireAndForget":  true,
+	"MaxConcurrent":  true,
+	"ReturnNames":    true,
+}
+
+// isLifecycleMethod reports whether a method on the app root is a runtime
+// hook. Startup and Shutdown only count with the hook signatures, so an app
+// method such as Shutdown() that powers off the device stays bound.
+func (rt *Runtime) isLifecycleMethod(name string) bool {
+	switch name {
+	case "Startup":
+		_, ok := rt.app.(StartupHook)
+		return ok
+	case "Shutdown":
+		_, ok := rt.app.(StopHook)
+		return ok
+	}
+	return lifecycleMethods[name]
+}
+
+// runStartupHook calls the app's Startup hook if it implements StartupHook.
+// The hook's context lives until runStopHook.
+func (rt *Runtime) runStartupHook() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	if hook, ok := rt.app.(StartupHook); ok {
+		if err := hook.Startup(ctx); err != nil {
+			cancel()
+			return err
+		}
+	}
+	rt.startupCancel = cancel
+	return nil
+}
+
+// runStopHook cancels the Startup context and calls the app's Shutdown hook
+// if it implements StopHook. It does nothing unless Startup has succeeded.
+func (rt *Runtime) runStopHook() error {
+	if rt.startupCancel == nil {
+		return nil
+	}
+	rt.startupCancel()
+	rt.startupCancel = nil
+
+	hook, ok := rt.app.(StopHook)
+	if !ok {
+		return nil
+	}
+	if err := hook.Shutdown(); err != nil {
+		fmt.Printf("Strux Runtime: App Shutdown failed: %v\n", err)
+		return fmt.Errorf("app Shutdown failed: %w", err)
+	}
+	return nil
+}
+
+// runReadyHook calls the app's OnReady hook if it implements ReadyHook
+func (rt *Runtime) runReadyHook() error {
+	hook, ok := rt.app.(ReadyHook)
+	if !ok {
+		return nil
+	}
+	return hook.OnReady()
+}
+
+// runShutdownHook calls the app's OnShutdown hook if it implements ShutdownHook
+func (rt *Runtime) runShutdownHook() {
+	if hook, ok := rt.app.(ShutdownHook); ok {
+		hook.OnShutdown()
+	}
+}
+
+// loadOptionalParams reads the app's OptionalParams declaration, ignoring
+// entries for unknown methods or with out-of-range counts.
+func (rt *Runtime) loadOptionalParams() {
+	rt.requiredParams = make(map[string]int)
+
+	provider, ok := rt.app.(OptionalParamsProvider)
+	if !ok {
+		return
+	}
+
+	for path, required := range provider.OptionalParams() {
+		method, exists := rt.methods[path]
+		if !exists {
+			fmt.Printf("Strux Runtime: OptionalParams names unknown method %s\n", path)
+			continue
+		}
+		numParams := method.Type().NumIn() - injectedParams(method.Type())
+		if required < 0 || required > numParams {
+			fmt.Printf("Strux Runtime: OptionalParams for %s must be between 0 and %d\n", path, numParams)
+			continue
+		}
+		rt.requiredParams[path] = required
+	}
+}
+
+// loadFireAndForget reads the app's FireAndForget declaration, ignoring
+// entries for unknown methods.
+func (rt *Runtime) loadFireAndForget() {
+	rt.fireAndForget = make(map[string]bool)
+
+	provider, ok := rt.app.(FireAndForgetProvider)
+	if !ok {
+		return
+	}
+
+	for _, path := range provider.FireAndForget() {
+		if _, exists := rt.methods[path]; !exists {
+			fmt.Printf("Strux Runtime: FireAndForget names unknown method %s\n", path)
+			continue
+		}
+		rt.fireAndForget[path] = true
+	}
+}
+
+// loadMaxConcurrent reads the app's MaxConcurrent declaration, ignoring
+// entries for unknown methods or with limits below 1.
+func (rt *Runtime) loadMaxConcurrent() {
+	rt.callSlots = make(map[string]chan struct{})
+
+	provider, ok := rt.app.(MaxConcurrentProvider)
+	if !ok {
+		return
+	}
+
+	for path, limit := range provider.MaxConcurrent() {
+		if _, exists := rt.methods[path]; !exists {
+			fmt.Printf("Strux Runtime: MaxConcurrent names unknown method %s\n", path)
+			continue
+		}
+		if limit < 1 {
+			fmt.Printf("Strux Runtime: MaxConcurrent for %s must be at least 1\n", path)
+			continue
+		}
+		rt.callSlots[path] = make(chan struct{}, limit)
+	}
+}
+
+// loadReturnNames reads the app's ReturnNames declaration, ignoring entries
+// for unknown methods, streamed results, or names that do not match the
+// method's non-error results one to one.
+func (rt *Runtime) loadReturnNames() {
+	rt.returnNames = make(map[string][]string)
+
+	provider, ok := rt.app.(ReturnNamesProvider)
+	if !ok {
+		return
+	}
+
+	for path, names := range provider.ReturnNames() {
+		method, exists := rt.methods[path]
+		if !exists {
+			fmt.Printf("Strux Runtime: ReturnNames names unknown method %s\n", path)
+			continue
+		}
+		methodType := method.Type()
+		if returnsReader(methodType) || returnsChannel(methodType) {
+			fmt.Printf("Strux Runtime: ReturnNames cannot name the streamed result of %s\n", path)
+			continue
+		}
+		numResults := methodType.NumOut()
+		if numResults > 0 && methodType.Out(numResults-1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+			numResults--
+		}
+		if len(names) != numResults || !uniqueNames(names) {
+			fmt.Printf("Strux Runtime: ReturnNames for %s must give %d distinct, non-empty names\n", path, numResults)
+			continue
+		}
+		rt.returnNames[path] = names
+	}
+}
+
+// uniqueNames reports whether names are all non-empty and distinct
+func uniqueNames(names []string) bool {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" || seen[name] {
+			return false
+		}
+		seen[name] = true
+	}
+	return true
+}
+
+// acquireCallSlot takes one of a limited method's call slots, waiting for one
+// to free up unless RejectBusyCalls is set. The returned release func must be
+// called when the call returns; methods without a limit get a no-op.
+func (rt *Runtime) acquireCallSlot(ctx context.Context, method string) (func(), error) {
+	slots, limited := rt.callSlots[method]
+	if !limited {
+		return func() {}, nil
+	}
+	release := func() { <-slots }
+
+	if rt.opts.RejectBusyCalls {
+		select {
+		case slots <- struct{}{}:
+			return release, nil
+		default:
+			return nil, codedErrorf(CodeBusy, "method %s is already running %d call(s)", method, cap(slots))
+		}
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, codedErrorf(CodeTimeout, "method %s did not get a call slot: %w", method, ctx.Err())
+	}
+}
@@ -0,0 +1,472 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testLifecycleApp struct {
+	shutdownCalls int
+}
+
+func (a *testLifecycleApp) Ping() string {
+	return "pong"
+}
+
+func (a *testLifecycleApp) OnShutdown() {
+	a.shutdownCalls++
+}
+
+func TestStopCallsOnShutdownOnce(t *testing.T) {
+	app := &testLifecycleApp{}
+	rt := New(app)
+
+	rt.Stop()
+	rt.Stop()
+
+	if app.shutdownCalls != 1 {
+		t.Fatalf("expected OnShutdown to be called once, got %d", app.shutdownCalls)
+	}
+}
+
+type testDrainApp struct {
+	release chan struct{}
+}
+
+func (a *testDrainApp) Slow() string {
+	<-a.release
+	return "done"
+}
+
+// startSlowCall sends a Slow call on a new connection and waits until the
+// runtime is running it
+func startSlowCall(t *testing.T, rt *Runtime) net.Conn {
+	t.Helper()
+	server, client := net.Pipe()
+	go rt.handleConnection(server)
+	if err := json.NewEncoder(client).Encode(Message{ID: "1", Method: "Slow", Params: json.RawMessage(`[]`)}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rt.InflightCalls()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("call never showed up as in flight")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return client
+}
+
+func TestStopContextDrainsInflightCalls(t *testing.T) {
+	app := &testDrainApp{release: make(chan struct{})}
+	rt := New(app)
+	client := startSlowCall(t, rt)
+	defer client.Close()
+
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		stopped <- rt.StopContext(ctx)
+	}()
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("StopContext returned before the call finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(app.release)
+	var resp Response
+	if err := json.NewDecoder(client).Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if resp.Result != "done" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if err := <-stopped; err != nil {
+		t.Fatalf("StopContext failed: %v", err)
+	}
+}
+
+func TestStopContextGivesUpAfterGracePeriod(t *testing.T) {
+	app := &testDrainApp{release: make(chan struct{})}
+	defer close(app.release)
+	rt := New(app)
+	client := startSlowCall(t, rt)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := rt.StopContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the grace period to run out, got %v", err)
+	}
+}
+
+func TestStopContextClosesIdleConnections(t *testing.T) {
+	rt := NewWithOptions(&testLifecycleApp{}, RuntimeOptions{AuthToken: "secret"})
+
+	// Neither connection sends anything, so both wait for their __auth message
+	var clients []net.Conn
+	for i := 0; i < 2; i++ {
+		server, client := net.Pipe()
+		defer client.Close()
+		go rt.handleConnection(server)
+		clients = append(clients, client)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for rt.ConnectionCount() < len(clients) {
+		if time.Now().After(deadline) {
+			t.Fatal("connections never opened")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := rt.StopContext(ctx); err != nil {
+		t.Fatalf("StopContext failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("StopContext waited %s for idle connections", elapsed)
+	}
+	for _, client := range clients {
+		if _, err := client.Read(make([]byte, 1)); err == nil {
+			t.Fatal("expected the idle connection to be closed")
+		}
+	}
+}
+
+func TestLifecycleHooksAreNotBound(t *testing.T) {
+	rt := New(&testLifecycleApp{})
+	defer rt.Stop()
+
+	if _, ok := rt.methods["Ping"]; !ok {
+		t.Fatalf("expected Ping to be bound")
+	}
+	if _, ok := rt.methods["OnShutdown"]; ok {
+		t.Fatalf("expected OnShutdown not to be bound")
+	}
+}
+
+type testFireAndForgetApp struct {
+	logged []string
+}
+
+func (a *testFireAndForgetApp) Log(line string) {
+	a.logged = append(a.logged, line)
+}
+
+func (a *testFireAndForgetApp) Ping() string {
+	return "pong"
+}
+
+func (a *testFireAndForgetApp) FireAndForget() []string {
+	return []string{"Log", "Missing"}
+}
+
+func TestFireAndForgetCallsSendNoResponse(t *testing.T) {
+	app := &testFireAndForgetApp{}
+	rt := New(app)
+	defer rt.Stop()
+
+	if _, ok := rt.methods["FireAndForget"]; ok {
+		t.Fatalf("expected FireAndForget not to be bound")
+	}
+	if !rt.fireAndForget["Log"] || rt.fireAndForget["Missing"] {
+		t.Fatalf("unexpected fire-and-forget set: %v", rt.fireAndForget)
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	if err := rt.handleMessage(Message{Method: "Log", Params: json.RawMessage(`["hello"]`)}, encoder, nil); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	if err := rt.handleMessage(Message{Method: "Log", Params: json.RawMessage(`[]`)}, encoder, nil); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no response for calls without an ID, got %q", out.String())
+	}
+	if len(app.logged) != 1 || app.logged[0] != "hello" {
+		t.Fatalf("expected Log to run once, got %v", app.logged)
+	}
+
+	bindings := rt.serializeTreeNode(rt.tree)
+	for _, method := range bindings["methods"].([]MethodInfo) {
+		if method.FireAndForget != (method.Name == "Log") {
+			t.Fatalf("unexpected fireAndForget flag on %s: %v", method.Name, method.FireAndForget)
+		}
+	}
+}
+
+type testReturnNamesApp struct{}
+
+func (a *testReturnNamesApp) Stats() (int, int, error) {
+	return 3, 10, nil
+}
+
+func (a *testReturnNamesApp) Pair() (string, string) {
+	return "a", "b"
+}
+
+func (a *testReturnNamesApp) ReturnNames() map[string][]string {
+	return map[string][]string{
+		"Stats":   {"count", "total"},
+		"Pair":    {"first"},
+		"Missing": {"value"},
+	}
+}
+
+func TestReturnNamesGiveNamedResults(t *testing.T) {
+	rt := New(&testReturnNamesApp{})
+	defer rt.Stop()
+
+	if _, ok := rt.methods["ReturnNames"]; ok {
+		t.Fatalf("expected ReturnNames not to be bound")
+	}
+	if _, ok := rt.returnNames["Pair"]; ok {
+		t.Fatalf("expected names that do not match the results to be ignored")
+	}
+
+	result, err := rt.executeMethod(context.Background(), "Stats", json.RawMessage(`[]`), nil)
+	if err != nil {
+		t.Fatalf("executeMethod failed: %v", err)
+	}
+	want := map[string]interface{}{"count": 3, "total": 10}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("expected %v, got %v", want, result)
+	}
+
+	result, err = rt.executeMethod(context.Background(), "Pair", json.RawMessage(`[]`), nil)
+	if err != nil {
+		t.Fatalf("executeMethod failed: %v", err)
+	}
+	if !reflect.DeepEqual(result, []interface{}{"a", "b"}) {
+		t.Fatalf("expected unnamed results as an array, got %v", result)
+	}
+
+	bindings := rt.serializeTreeNode(rt.tree)
+	for _, method := range bindings["methods"].([]MethodInfo) {
+		if method.Name == "Stats" && !reflect.DeepEqual(method.ReturnNames, []string{"count", "total"}) {
+			t.Fatalf("unexpected returnNames on Stats: %v", method.ReturnNames)
+		}
+	}
+}
+
+type testMaxConcurrentApp struct {
+	release chan struct{}
+}
+
+func (a *testMaxConcurrentApp) Work() string {
+	<-a.release
+	return "done"
+}
+
+func (a *testMaxConcurrentApp) MaxConcurrent() map[string]int {
+	return map[string]int{"Work": 1, "Missing": 2}
+}
+
+// startWork runs a Work call in the background and waits until it holds the
+// method's only slot
+func startWork(t *testing.T, rt *Runtime) chan error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() {
+		_, err := rt.executeMethod(context.Background(), "Work", json.RawMessage(`[]`), nil)
+		done <- err
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rt.callSlots["Work"]) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("first call never took its slot")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return done
+}
+
+func TestMaxConcurrentQueuesExcessCalls(t *testing.T) {
+	app := &testMaxConcurrentApp{release: make(chan struct{})}
+	rt := New(app)
+	defer rt.Stop()
+
+	if _, ok := rt.methods["MaxConcurrent"]; ok {
+		t.Fatalf("expected MaxConcurrent not to be bound")
+	}
+	first := startWork(t, rt)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := rt.executeMethod(ctx, "Work", json.RawMessage(`[]`), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second call to wait for a slot, got %v", err)
+	}
+	if errorCode(err) != string(CodeTimeout) {
+		t.Fatalf("expected a Timeout error, got %s (%v)", errorCode(err), err)
+	}
+
+	close(app.release)
+	if err := <-first; err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+	if result, err := rt.executeMethod(context.Background(), "Work", json.RawMessage(`[]`), nil); err != nil || result != "done" {
+		t.Fatalf("expected a call after the slot freed up to run, got %v, %v", result, err)
+	}
+}
+
+func TestMaxConcurrentRejectsBusyCalls(t *testing.T) {
+	app := &testMaxConcurrentApp{release: make(chan struct{})}
+	rt := NewWithOptions(app, RuntimeOptions{RejectBusyCalls: true})
+	defer rt.Stop()
+
+	first := startWork(t, rt)
+	_, err := rt.executeMethod(context.Background(), "Work", json.RawMessage(`[]`), nil)
+	if errorCode(err) != string(CodeBusy) {
+		t.Fatalf("expected a Busy error, got %v", err)
+	}
+
+	close(app.release)
+	if err := <-first; err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+}
+
+type testStartupApp struct {
+	startupErr  error
+	startupCtx  context.Context
+	shutdowns   int
+	shutdownErr error
+}
+
+func (a *testStartupApp) Startup(ctx context.Context) error {
+	a.startupCtx = ctx
+	return a.startupErr
+}
+
+func (a *testStartupApp) Shutdown() error {
+	a.shutdowns++
+	return a.shutdownErr
+}
+
+func (a *testStartupApp) Ping() string { return "pong" }
+
+func TestStartupAndShutdownHooks(t *testing.T) {
+	app := &testStartupApp{shutdownErr: errors.New("flush failed")}
+	rt := NewWithOptions(app, RuntimeOptions{SocketPath: filepath.Join(t.TempDir(), "ipc.sock")})
+
+	for _, name := range []string{"Startup", "Shutdown"} {
+		if _, ok := rt.methods[name]; ok {
+			t.Fatalf("expected %s not to be bound", name)
+		}
+	}
+	if _, ok := rt.methods["Ping"]; !ok {
+		t.Fatal("expected Ping to be bound")
+	}
+
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if app.startupCtx == nil || app.startupCtx.Err() != nil {
+		t.Fatal("expected Startup to get a live context")
+	}
+
+	if err := rt.StopContext(context.Background()); err == nil || !errors.Is(err, app.shutdownErr) {
+		t.Fatalf("expected StopContext to return the Shutdown error, got %v", err)
+	}
+	if app.shutdowns != 1 || app.startupCtx.Err() == nil {
+		t.Fatalf("expected one Shutdown and a canceled Startup context, got %d shutdowns", app.shutdowns)
+	}
+}
+
+func TestStartupErrorAbortsStart(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "ipc.sock")
+	app := &testStartupApp{startupErr: errors.New("no database")}
+	rt := NewWithOptions(app, RuntimeOptions{SocketPath: socketPath})
+
+	if err := rt.Start(); err == nil || !errors.Is(err, app.startupErr) {
+		t.Fatalf("expected Start to fail with the Startup error, got %v", err)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Fatal("expected the socket to be removed after a failed Startup")
+	}
+
+	rt.Stop()
+	if app.shutdowns != 0 {
+		t.Fatalf("expected no Shutdown after a failed Startup, got %d", app.shutdowns)
+	}
+}
+
+type testPowerApp struct{}
+
+// Shutdown without the hook signature is an ordinary method
+func (a *testPowerApp) Shutdown() string { return "powering off" }
+
+func TestShutdownMethodWithoutHookSignatureIsBound(t *testing.T) {
+	rt := New(&testPowerApp{})
+	defer rt.Stop()
+
+	if _, ok := rt.methods["Shutdown"]; !ok {
+		t.Fatal("expected Shutdown() string to be bound")
+	}
+}
+
+type testReadyApp struct {
+	socketPath string
+	readyErr   error
+	readyCalls int
+	dialErr    error
+}
+
+// OnReady checks the IPC socket is already accepting connections
+func (a *testReadyApp) OnReady() error {
+	a.readyCalls++
+	conn, err := net.Dial("unix", a.socketPath)
+	if err == nil {
+		conn.Close()
+	}
+	a.dialErr = err
+	return a.readyErr
+}
+
+func TestOnReadyRunsOnceAfterListen(t *testing.T) {
+	app := &testReadyApp{socketPath: filepath.Join(t.TempDir(), "ipc.sock")}
+	rt := NewWithOptions(app, RuntimeOptions{SocketPath: app.socketPath})
+
+	if _, ok := rt.methods["OnReady"]; ok {
+		t.Fatal("expected OnReady not to be bound")
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	rt.Stop()
+
+	if app.readyCalls != 1 {
+		t.Fatalf("expected OnReady to be called once, got %d", app.readyCalls)
+	}
+	if app.dialErr != nil {
+		t.Fatalf("expected the listener to be up when OnReady ran: %v", app.dialErr)
+	}
+}
+
+func TestOnReadyErrorAbortsStart(t *testing.T) {
+	app := &testReadyApp{socketPath: filepath.Join(t.TempDir(), "ipc.sock"), readyErr: errors.New("cache warmup failed")}
+	rt := NewWithOptions(app, RuntimeOptions{SocketPath: app.socketPath})
+	defer rt.Stop()
+
+	if err := rt.Start(); err == nil || !errors.Is(err, app.readyErr) {
+		t.Fatalf("expected Start to fail with the OnReady error, got %v", err)
+	}
+	if _, err := os.Stat(app.socketPath); !os.IsNotExist(err) {
+		t.Fatal("expected the socket to be removed after a failed OnReady")
+	}
+}
@@ -0,0 +1,138 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Transport selects what kind of listener the IPC server uses
+type Transport string
+
+const (
+	// TransportUnix listens on a Unix domain socket (the default)
+	TransportUnix Transport = "unix"
+	// TransportTCP listens on a TCP address, for platforms without AF_UNIX
+	// or for reaching the bridge from browser dev tools
+	TransportTCP Transport = "tcp"
+)
+
+// defaultTCPAddr binds to loopback on a port chosen by the OS
+const defaultTCPAddr = "127.0.0.1:0"
+
+// defaultSocketPath is where the WPE extension on the device connects
+const defaultSocketPath = "/tmp/strux-ipc.sock"
+
+// defaultSocketMode restricts the IPC socket to the user the app runs as
+const defaultSocketMode os.FileMode = 0600
+
+// RuntimeOptions configures a Runtime. The zero value uses the defaults.
+type RuntimeOptions struct {
+	// Transport is the kind of listener the IPC server uses. Defaults to
+	// TransportUnix. Messages are framed the same way on every transport.
+	Transport Transport
+
+	// TCPAddr is the address listened on with TransportTCP. Defaults to
+	// 127.0.0.1:0, a loopback port chosen by the OS; Runtime.Addr reports the
+	// port actually bound.
+	TCPAddr string
+
+	// SocketPath is the Unix socket the IPC server listens on with
+	// TransportUnix. Defaults to
+	// /tmp/strux-ipc.sock, where the WPE extension connects; set it to run
+	// more than one runtime on a machine, e.g. in parallel tests.
+	SocketPath string
+
+	// SocketMode is applied to the IPC socket after it is created. Defaults to
+	// 0600 so other users on the device cannot call app methods.
+	SocketMode os.FileMode
+
+	// AuthToken, when set, must be presented by every IPC connection before
+	// anything else: the first message has to be an __auth call whose param
+	// is the token. Connections that skip it or send the wrong token get an
+	// Unauthorized error and are closed. Empty (the default) accepts every
+	// connection that can reach the socket.
+	AuthToken string
+
+	// AuthTokenFile, when set, makes Start write the auth token to this file
+	// with mode 0600, generating a random token if AuthToken is empty, so
+	// only the app's user can read it. The file is removed on Stop. Set it to
+	// /tmp/strux-ipc.token, where the WPE extension reads the token from.
+	AuthTokenFile string
+
+	// IdleTimeout closes IPC connections that send no message for this long.
+	// Zero (the default) disables the timeout. Event channels are exempt since
+	// they are expected to sit idle between events.
+	IdleTimeout time.Duration
+
+	// CallTimeout fails app method calls that run longer than this with a
+	// Timeout error, so a hung method doesn't leave the frontend waiting
+	// forever. The method itself keeps running in the background and its
+	// results are discarded; anything it changes after the deadline is up to
+	// the app. Methods taking a context.Context see the deadline on it. Zero
+	// (the default) disables the timeout.
+	CallTimeout time.Duration
+
+	// MethodTimeouts overrides CallTimeout for individual method paths (e.g.
+	// "Export" or "Settings.Save"). A zero duration disables the timeout for
+	// that method.
+	MethodTimeouts map[string]time.Duration
+
+	// RejectBusyCalls fails calls to a method that is at its MaxConcurrent
+	// limit with a Busy error instead of queuing them until a call finishes.
+	RejectBusyCalls bool
+
+	// TraceFile, when set, records every message received on the IPC bridge
+	// and every response sent, with timestamps, as line-delimited JSON
+	// appended to this file. Meant for reproducing frontend/backend desync
+	// bugs; see TraceEntry for the format.
+	TraceFile string
+
+	// TraceRedact lists method paths (e.g. "Login" or "Settings.SetPassword")
+	// whose params and results are replaced with "[redacted]" in the trace.
+	TraceRedact []string
+}
+
+// transport returns the configured transport or the default, rejecting
+// unknown values
+func (o RuntimeOptions) transport() (Transport, error) {
+	switch o.Transport {
+	case "", TransportUnix:
+		return TransportUnix, nil
+	case TransportTCP:
+		return TransportTCP, nil
+	}
+	return "", fmt.Errorf("unknown transport %q", o.Transport)
+}
+
+// tcpAddr returns the configured TCP address or the default
+func (o RuntimeOptions) tcpAddr() string {
+	if o.TCPAddr == "" {
+		return defaultTCPAddr
+	}
+	return o.TCPAddr
+}
+
+// socketPath returns the configured socket path or the default
+func (o RuntimeOptions) socketPath() string {
+	if o.SocketPath == "" {
+		return defaultSocketPath
+	}
+	return o.SocketPath
+}
+
+// socketMode returns the configured socket permissions or the default
+func (o RuntimeOptions) socketMode() os.FileMode {
+	if o.SocketMode == 0 {
+		return defaultSocketMode
+	}
+	return o.SocketMode
+}
+
+// callTimeout returns the timeout of a method path, or 0 for none
+func (o RuntimeOptions) callTimeout(method string) time.Duration {
+	if timeout, ok := o.MethodTimeouts[method]; ok {
+		return timeout
+	}
+	return o.CallTimeout
+}
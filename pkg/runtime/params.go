@@ -0,0 +1,184 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// splitParams splits a JSON params array into its raw elements, so numbers keep
+// their original text instead of being rounded through float64.
+func splitParams(paramsRaw json.RawMessage) ([]json.RawMessage, error) {
+	if len(bytes.TrimSpace(paramsRaw)) == 0 {
+		return nil, nil
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(paramsRaw, &params); err != nil {
+		return nil, codedErrorf(CodeInvalidParams, "invalid parameters: %w", err)
+	}
+	return params, nil
+}
+
+// decodeParam decodes one JSON value into typ.
+//
+// Integer types accept any JS number with an integral value (5, 5.0, 5e2) and
+// decimal strings ("9007199254740993"), and reject values outside the type's
+// range. JS numbers are doubles, so integers beyond ±2^53 should be sent as
+// strings to keep full precision. Types with their own JSON or text
+// unmarshaling are decoded as-is.
+//
+// Structs start from their `strux:"default=..."` tag values, so a partial JSON
+// object (a JS options object) only overrides the fields it sets. A missing or
+// null struct gets the defaults alone.
+func decodeParam(raw json.RawMessage, typ reflect.Type) (reflect.Value, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		if isOptionsStruct(typ) {
+			value := reflect.New(typ).Elem()
+			if err := applyStructDefaults(value); err != nil {
+				return reflect.Value{}, err
+			}
+			return value, nil
+		}
+		return reflect.Zero(typ), nil
+	}
+
+	if typ.Kind() == reflect.Ptr && !hasCustomUnmarshal(typ) {
+		elem, err := decodeParam(trimmed, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	if isIntegerKind(typ.Kind()) && !hasCustomUnmarshal(typ) {
+		return decodeInteger(trimmed, typ)
+	}
+
+	value := reflect.New(typ)
+	if isOptionsStruct(typ) {
+		if err := applyStructDefaults(value.Elem()); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if err := json.Unmarshal(trimmed, value.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return value.Elem(), nil
+}
+
+// applyStructDefaults sets every exported field with a `strux:"default=..."`
+// tag to its default, recursing into nested struct fields. Defaults are written
+// as they would be in JSON, without quotes for strings:
+//
+//	type SearchOptions struct {
+//		Query string
+//		Limit int  `strux:"default=20"`
+//		Exact bool `strux:"default=true"`
+//	}
+func applyStructDefaults(value reflect.Value) error {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+
+		if literal, ok := parseStruxTag(field.Tag.Get(struxTagName))["default"]; ok {
+			if err := setDefault(fieldValue, literal); err != nil {
+				return codedErrorf(CodeInternal, "invalid default for field %s: %w", field.Name, err)
+			}
+			continue
+		}
+		if isOptionsStruct(field.Type) {
+			if err := applyStructDefaults(fieldValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setDefault decodes a default tag value into a field
+func setDefault(fieldValue reflect.Value, literal string) error {
+	if fieldValue.Kind() == reflect.String {
+		fieldValue.SetString(literal)
+		return nil
+	}
+	decoded, err := decodeParam(json.RawMessage(literal), fieldValue.Type())
+	if err != nil {
+		return err
+	}
+	fieldValue.Set(decoded)
+	return nil
+}
+
+// decodeInteger converts a JSON number or numeric string into an integer type
+// with range checking.
+func decodeInteger(raw []byte, typ reflect.Type) (reflect.Value, error) {
+	literal := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &literal); err != nil {
+			return reflect.Value{}, err
+		}
+		literal = strings.TrimSpace(literal)
+	}
+
+	number, ok := new(big.Float).SetPrec(256).SetString(literal)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %s", raw, typ)
+	}
+	if !number.IsInt() {
+		return reflect.Value{}, fmt.Errorf("cannot use non-integer %s as %s", literal, typ)
+	}
+	integer, _ := number.Int(nil)
+
+	value := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !integer.IsInt64() || value.OverflowInt(integer.Int64()) {
+			return reflect.Value{}, fmt.Errorf("value %s overflows %s", literal, typ)
+		}
+		value.SetInt(integer.Int64())
+	default:
+		if !integer.IsUint64() || value.OverflowUint(integer.Uint64()) {
+			return reflect.Value{}, fmt.Errorf("value %s overflows %s", literal, typ)
+		}
+		value.SetUint(integer.Uint64())
+	}
+	return value, nil
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// isOptionsStruct reports whether typ is a plain struct that can be decoded
+// from a partial JS options object
+func isOptionsStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && !hasCustomUnmarshal(typ)
+}
+
+// hasCustomUnmarshal reports whether typ (or a pointer to it) decodes itself
+func hasCustomUnmarshal(typ reflect.Type) bool {
+	ptr := reflect.PointerTo(typ)
+	return typ.Implements(jsonUnmarshalerType) || ptr.Implements(jsonUnmarshalerType) ||
+		typ.Implements(textUnmarshalerType) || ptr.Implements(textUnmarshalerType)
+}
@@ -0,0 +1,238 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type testParamsApp struct{}
+
+func (a *testParamsApp) Add(a1 int, a2 int) int { return a1 + a2 }
+
+func (a *testParamsApp) Echo64(v int64) int64 { return v }
+
+func (a *testParamsApp) Scale(v float64) float64 { return v * 2 }
+
+func (a *testParamsApp) Greet(name string, greeting string) string {
+	if greeting == "" {
+		greeting = "Hello"
+	}
+	return greeting + " " + name
+}
+
+type testSearchFilter struct {
+	Tag   string
+	Depth int `strux:"default=2"`
+}
+
+type testSearchOptions struct {
+	Query  string
+	Limit  int    `strux:"default=20"`
+	Exact  bool   `strux:"default=true"`
+	Sort   string `strux:"default=name"`
+	Filter testSearchFilter
+}
+
+func (a *testParamsApp) Search(opts testSearchOptions) testSearchOptions { return opts }
+
+func (a *testParamsApp) OptionalParams() map[string]int {
+	return map[string]int{"Greet": 1}
+}
+
+func TestDecodeParamIntegers(t *testing.T) {
+	cases := []struct {
+		raw  string
+		typ  reflect.Type
+		want interface{}
+	}{
+		{`5`, reflect.TypeOf(int(0)), int(5)},
+		{`5.0`, reflect.TypeOf(int(0)), int(5)},
+		{`5e2`, reflect.TypeOf(int32(0)), int32(500)},
+		{`-128`, reflect.TypeOf(int8(0)), int8(-128)},
+		{`255`, reflect.TypeOf(uint8(0)), uint8(255)},
+		{`"9007199254740993"`, reflect.TypeOf(int64(0)), int64(9007199254740993)},
+		{`9007199254740993`, reflect.TypeOf(int64(0)), int64(9007199254740993)},
+		{`"18446744073709551615"`, reflect.TypeOf(uint64(0)), uint64(18446744073709551615)},
+		{`9223372036854775807`, reflect.TypeOf(int64(0)), int64(math.MaxInt64)},
+		{`"9223372036854775807"`, reflect.TypeOf(int64(0)), int64(math.MaxInt64)},
+		{`"-9223372036854775808"`, reflect.TypeOf(int64(0)), int64(math.MinInt64)},
+		{`18446744073709551615`, reflect.TypeOf(uint64(0)), uint64(math.MaxUint64)},
+		{`null`, reflect.TypeOf(int(0)), int(0)},
+	}
+
+	for _, tc := range cases {
+		got, err := decodeParam(json.RawMessage(tc.raw), tc.typ)
+		if err != nil {
+			t.Fatalf("decodeParam(%s, %s) failed: %v", tc.raw, tc.typ, err)
+		}
+		if got.Interface() != tc.want {
+			t.Fatalf("decodeParam(%s, %s) = %v, want %v", tc.raw, tc.typ, got.Interface(), tc.want)
+		}
+	}
+}
+
+func TestDecodeParamRejectsOverflowAndFractions(t *testing.T) {
+	cases := []struct {
+		raw string
+		typ reflect.Type
+	}{
+		{`5.5`, reflect.TypeOf(int(0))},
+		{`128`, reflect.TypeOf(int8(0))},
+		{`-1`, reflect.TypeOf(uint(0))},
+		{`-1`, reflect.TypeOf(uint64(0))},
+		{`"-1"`, reflect.TypeOf(uint64(0))},
+		{`-0.5`, reflect.TypeOf(uint8(0))},
+		{`9007199254740993.5`, reflect.TypeOf(int64(0))},
+		{`"2.5"`, reflect.TypeOf(int64(0))},
+		{`"18446744073709551616"`, reflect.TypeOf(uint64(0))},
+		{`256`, reflect.TypeOf(uint8(0))},
+		{`"9223372036854775808"`, reflect.TypeOf(int64(0))},
+		{`1e400`, reflect.TypeOf(int64(0))},
+		{`"abc"`, reflect.TypeOf(int(0))},
+		{`true`, reflect.TypeOf(int(0))},
+	}
+
+	for _, tc := range cases {
+		if _, err := decodeParam(json.RawMessage(tc.raw), tc.typ); err == nil {
+			t.Fatalf("expected decodeParam(%s, %s) to fail", tc.raw, tc.typ)
+		}
+	}
+}
+
+func TestDecodeParamFloatsAndPointers(t *testing.T) {
+	got, err := decodeParam(json.RawMessage(`2.5`), reflect.TypeOf(float64(0)))
+	if err != nil || got.Float() != 2.5 {
+		t.Fatalf("expected 2.5, got %v (err %v)", got, err)
+	}
+
+	got, err = decodeParam(json.RawMessage(`7.0`), reflect.TypeOf((*int)(nil)))
+	if err != nil {
+		t.Fatalf("decodeParam(*int) failed: %v", err)
+	}
+	if got.Elem().Int() != 7 {
+		t.Fatalf("expected *int pointing to 7, got %v", got.Elem())
+	}
+}
+
+func TestExecuteMethodCoercesNumericParams(t *testing.T) {
+	rt := New(&testParamsApp{})
+	defer rt.Stop()
+
+	result, err := rt.executeMethod(context.Background(), "Add", json.RawMessage(`[2.0, 3]`), nil)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if result != 5 {
+		t.Fatalf("expected 5, got %v", result)
+	}
+
+	result, err = rt.executeMethod(context.Background(), "Echo64", json.RawMessage(`["9007199254740993"]`), nil)
+	if err != nil {
+		t.Fatalf("Echo64 failed: %v", err)
+	}
+	if result != int64(9007199254740993) {
+		t.Fatalf("expected exact int64, got %v", result)
+	}
+
+	result, err = rt.executeMethod(context.Background(), "Scale", json.RawMessage(`[1.25]`), nil)
+	if err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	if result != 2.5 {
+		t.Fatalf("expected 2.5, got %v", result)
+	}
+
+	if _, err := rt.executeMethod(context.Background(), "Add", json.RawMessage(`[1.5, 2]`), nil); err == nil {
+		t.Fatal("expected fractional int parameter to fail")
+	}
+}
+
+func TestExecuteMethodZeroFillsOptionalParams(t *testing.T) {
+	rt := New(&testParamsApp{})
+	defer rt.Stop()
+
+	if _, ok := rt.methods["OptionalParams"]; ok {
+		t.Fatal("expected OptionalParams not to be bound")
+	}
+
+	result, err := rt.executeMethod(context.Background(), "Greet", json.RawMessage(`["Ada"]`), nil)
+	if err != nil {
+		t.Fatalf("Greet with one param failed: %v", err)
+	}
+	if result != "Hello Ada" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	result, err = rt.executeMethod(context.Background(), "Greet", json.RawMessage(`["Ada", "Hi"]`), nil)
+	if err != nil {
+		t.Fatalf("Greet with two params failed: %v", err)
+	}
+	if result != "Hi Ada" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	if _, err := rt.executeMethod(context.Background(), "Greet", json.RawMessage(`[]`), nil); err == nil {
+		t.Fatal("expected missing required param to fail")
+	}
+	if _, err := rt.executeMethod(context.Background(), "Add", json.RawMessage(`[1]`), nil); err == nil {
+		t.Fatal("expected strict arity for methods without optional params")
+	}
+}
+
+func TestExecuteMethodAppliesOptionsDefaults(t *testing.T) {
+	rt := New(&testParamsApp{})
+	defer rt.Stop()
+
+	result, err := rt.executeMethod(context.Background(), "Search", json.RawMessage(`[{"Query":"ada","Exact":false,"Filter":{"Tag":"x"}}]`), nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	want := testSearchOptions{Query: "ada", Limit: 20, Exact: false, Sort: "name", Filter: testSearchFilter{Tag: "x", Depth: 2}}
+	if result != want {
+		t.Fatalf("expected %+v, got %+v", want, result)
+	}
+
+	// The options object may be left out entirely
+	result, err = rt.executeMethod(context.Background(), "Search", json.RawMessage(`[]`), nil)
+	if err != nil {
+		t.Fatalf("Search without options failed: %v", err)
+	}
+	want = testSearchOptions{Limit: 20, Exact: true, Sort: "name", Filter: testSearchFilter{Depth: 2}}
+	if result != want {
+		t.Fatalf("expected defaults %+v, got %+v", want, result)
+	}
+}
+
+type testCounterApp struct {
+	Total int64
+	Count uint32
+}
+
+func TestSetFieldKeepsIntegerPrecision(t *testing.T) {
+	app := &testCounterApp{}
+	rt := New(app)
+	defer rt.Stop()
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	rt.handleMessage(Message{ID: "1", Method: "__setField", Params: json.RawMessage(`["Total", 9223372036854775807]`)}, encoder, nil)
+	if app.Total != math.MaxInt64 {
+		t.Fatalf("expected Total %d, got %d (%s)", int64(math.MaxInt64), app.Total, out.String())
+	}
+
+	for _, params := range []string{`["Count", -1]`, `["Count", 2.5]`} {
+		out.Reset()
+		rt.handleMessage(Message{ID: "2", Method: "__setField", Params: json.RawMessage(params)}, encoder, nil)
+		var resp Response
+		if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if resp.Code != string(CodeInvalidParams) || app.Count != 0 {
+			t.Fatalf("expected %s to be rejected, got %+v (Count %d)", params, resp, app.Count)
+		}
+	}
+}
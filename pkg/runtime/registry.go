@@ -1,10 +1,14 @@
 package runtime
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Registry manages all registered extensions
@@ -24,7 +28,11 @@ func newRegistry() *Registry {
 func (r *Registry) Register(namespace string, subNamespace string, instance interface{}) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	return r.registerLocked(namespace, subNamespace, instance)
+}
 
+// registerLocked adds an extension. Callers must hold r.mu.
+func (r *Registry) registerLocked(namespace string, subNamespace string, instance interface{}) error {
 	if namespace == "" || subNamespace == "" {
 		return fmt.Errorf("namespace and sub-namespace cannot be empty")
 	}
@@ -43,6 +51,37 @@ func (r *Registry) Register(namespace string, subNamespace string, instance inte
 	return nil
 }
 
+// ExtensionEntry describes one extension for RegisterAll
+type ExtensionEntry struct {
+	Namespace    string
+	SubNamespace string
+	Instance     interface{}
+}
+
+// RegisterAll registers a set of extensions as a single operation. If any entry
+// is invalid or already registered (including duplicates within the set), none
+// of them are registered, so the registry is never left half-populated.
+func (r *Registry) RegisterAll(entries []ExtensionEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := make([]ExtensionEntry, 0, len(entries))
+	for _, entry := range entries {
+		if err := r.registerLocked(entry.Namespace, entry.SubNamespace, entry.Instance); err != nil {
+			// Roll back the entries added so far
+			for _, prev := range added {
+				delete(r.extensions[prev.Namespace], prev.SubNamespace)
+				if len(r.extensions[prev.Namespace]) == 0 {
+					delete(r.extensions, prev.Namespace)
+				}
+			}
+			return err
+		}
+		added = append(added, entry)
+	}
+	return nil
+}
+
 // GetAllBindings returns all extension bindings in the format expected by the IPC protocol
 func (r *Registry) GetAllBindings() map[string]interface{} {
 	r.mu.RLock()
@@ -80,16 +119,7 @@ func (r *Registry) extractMethods(instance interface{}) []MethodInfo {
 
 		// Only include exported methods
 		if methodName[0] >= 'A' && methodName[0] <= 'Z' {
-			paramTypes := make([]string, methodType.NumIn())
-			for j := 0; j < methodType.NumIn(); j++ {
-				paramTypes[j] = methodType.In(j).Kind().String()
-			}
-
-			methods = append(methods, MethodInfo{
-				Name:       methodName,
-				ParamCount: methodType.NumIn(),
-				ParamTypes: paramTypes,
-			})
+			methods = append(methods, newMethodInfo(methodName, methodType))
 		}
 	}
 
@@ -98,17 +128,24 @@ func (r *Registry) extractMethods(instance interface{}) []MethodInfo {
 
 // ExecuteMethod executes a method on a registered extension
 func (r *Registry) ExecuteMethod(namespace, subNamespace, methodName string, params []interface{}) (interface{}, error) {
+	return r.ExecuteMethodInSession(context.Background(), namespace, subNamespace, methodName, params, nil)
+}
+
+// ExecuteMethodInSession executes a method on a registered extension on behalf
+// of a connection. Methods taking a context.Context receive ctx, and methods
+// taking a *Session receive session, or a fresh one if session is nil.
+func (r *Registry) ExecuteMethodInSession(ctx context.Context, namespace, subNamespace, methodName string, params []interface{}, session *Session) (interface{}, error) {
 	r.mu.RLock()
 	subNamespaces, exists := r.extensions[namespace]
 	if !exists {
 		r.mu.RUnlock()
-		return nil, fmt.Errorf("namespace %s not found", namespace)
+		return nil, codedErrorf(CodeMethodNotFound, "namespace %s not found", namespace)
 	}
 
 	instance, exists := subNamespaces[subNamespace]
 	if !exists {
 		r.mu.RUnlock()
-		return nil, fmt.Errorf("sub-namespace %s.%s not found", namespace, subNamespace)
+		return nil, codedErrorf(CodeMethodNotFound, "sub-namespace %s.%s not found", namespace, subNamespace)
 	}
 	r.mu.RUnlock()
 
@@ -116,39 +153,48 @@ func (r *Registry) ExecuteMethod(namespace, subNamespace, methodName string, par
 	val := reflect.ValueOf(instance)
 	method := val.MethodByName(methodName)
 	if !method.IsValid() {
-		return nil, fmt.Errorf("method %s not found on %s.%s", methodName, namespace, subNamespace)
+		return nil, codedErrorf(CodeMethodNotFound, "method %s not found on %s.%s", methodName, namespace, subNamespace)
 	}
 
 	methodType := method.Type()
-	numParams := methodType.NumIn()
+	injected := injectedParams(methodType)
+	numParams := methodType.NumIn() - injected
 
 	if len(params) != numParams {
-		return nil, fmt.Errorf("expected %d parameters, got %d", numParams, len(params))
+		return nil, codedErrorf(CodeInvalidParams, "expected %d parameters, got %d", numParams, len(params))
 	}
 
+	if session == nil {
+		session = newSession()
+		defer session.close()
+	}
+	args := injectedArgs(methodType, ctx, session)
+
 	// Convert parameters to the correct types
-	args := make([]reflect.Value, numParams)
 	for i := 0; i < numParams; i++ {
-		expectedType := methodType.In(i)
+		expectedType := methodType.In(injected + i)
 		if params[i] == nil {
-			args[i] = reflect.Zero(expectedType)
+			args = append(args, reflect.Zero(expectedType))
 			continue
 		}
 
 		paramJSON, err := json.Marshal(params[i])
 		if err != nil {
-			return nil, fmt.Errorf("parameter %d could not be encoded: %w", i, err)
+			return nil, codedErrorf(CodeInvalidParams, "parameter %d could not be encoded: %w", i, err)
 		}
 
-		paramValue := reflect.New(expectedType)
-		if err := json.Unmarshal(paramJSON, paramValue.Interface()); err != nil {
-			return nil, fmt.Errorf("parameter %d type mismatch: %w", i, err)
+		paramValue, err := decodeParam(paramJSON, expectedType)
+		if err != nil {
+			return nil, codedErrorf(CodeInvalidParams, "parameter %d type mismatch: %w", i, err)
 		}
-		args[i] = paramValue.Elem()
+		args = append(args, paramValue)
 	}
 
 	// Call the method
-	results := method.Call(args)
+	results, err := callRecovered(method, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s.%s: %w", namespace, subNamespace, methodName, err)
+	}
 
 	// Handle return values
 	if len(results) == 0 {
@@ -182,3 +228,58 @@ func (r *Registry) ExecuteMethod(namespace, subNamespace, methodName string, par
 	}
 	return resultArray, nil
 }
+
+// panicStackLines is how many lines of the stack trace are kept in a panic error
+const panicStackLines = 12
+
+// callRecovered calls a method, converting a panic into an error carrying the
+// panic value and the top of the stack, so a misbehaving method fails the call
+// instead of crashing the connection goroutine.
+func callRecovered(method reflect.Value, args []reflect.Value) (results []reflect.Value, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = codedErrorf(CodeInternal, "method panicked: %v\n%s", p, stackSnippet(panicStackLines))
+		}
+	}()
+	return method.Call(args), nil
+}
+
+// callWithTimeout calls a method like callRecovered, but gives up waiting after
+// timeout (0 waits as long as it takes). A method that times out keeps running
+// on its own goroutine and its results are discarded. returned is called once
+// the method has actually returned, even after a timeout.
+func callWithTimeout(method reflect.Value, args []reflect.Value, timeout time.Duration, returned func()) ([]reflect.Value, error) {
+	if timeout <= 0 {
+		defer returned()
+		return callRecovered(method, args)
+	}
+
+	type outcome struct {
+		results []reflect.Value
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		results, err := callRecovered(method, args)
+		returned()
+		done <- outcome{results, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case o := <-done:
+		return o.results, o.err
+	case <-timer.C:
+		return nil, codedErrorf(CodeTimeout, "method did not return within %s", timeout)
+	}
+}
+
+// stackSnippet returns the first lines of the current goroutine's stack trace
+func stackSnippet(lines int) string {
+	stack := strings.Split(strings.TrimSpace(string(debug.Stack())), "\n")
+	if len(stack) > lines {
+		stack = append(stack[:lines], "...")
+	}
+	return strings.Join(stack, "\n")
+}
@@ -1,6 +1,9 @@
 package runtime
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 type testRegistryHost struct {
 	Host string `json:"host"`
@@ -12,8 +15,19 @@ type testRegistryConfig struct {
 	Hosts []testRegistryHost `json:"hosts"`
 }
 
+type testRegistryMode string
+
+const (
+	testRegistryModeAuto   testRegistryMode = "auto"
+	testRegistryModeManual testRegistryMode = "manual"
+)
+
 type testRegistryMethods struct{}
 
+func (m *testRegistryMethods) SetMode(mode testRegistryMode) testRegistryMode {
+	return mode
+}
+
 func (m *testRegistryMethods) Describe(config testRegistryConfig) string {
 	return config.Name + ":" + config.Hosts[0].Host
 }
@@ -26,6 +40,26 @@ func (m *testRegistryMethods) CountPorts(hosts []testRegistryHost) int {
 	return total
 }
 
+func (m *testRegistryMethods) Explode() string {
+	var hosts []testRegistryHost
+	return hosts[3].Host
+}
+
+func TestRegistryExecuteMethodRecoversFromPanic(t *testing.T) {
+	registry := newRegistry()
+	if err := registry.Register("test", "config", &testRegistryMethods{}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	_, err := registry.ExecuteMethod("test", "config", "Explode", []interface{}{})
+	if err == nil {
+		t.Fatal("expected panicking method to return an error")
+	}
+	if !strings.Contains(err.Error(), "panicked") || !strings.Contains(err.Error(), "index out of range") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestRegistryExecuteMethodDecodesStructParameters(t *testing.T) {
 	registry := newRegistry()
 	if err := registry.Register("test", "config", &testRegistryMethods{}); err != nil {
@@ -80,3 +114,78 @@ func TestRegistryExecuteMethodDecodesSliceParameters(t *testing.T) {
 		t.Fatalf("unexpected result: %d", got)
 	}
 }
+
+func TestRegistryRegisterAllRollsBackOnDuplicate(t *testing.T) {
+	registry := newRegistry()
+	if err := registry.Register("test", "existing", &testRegistryMethods{}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	err := registry.RegisterAll([]ExtensionEntry{
+		{Namespace: "batch", SubNamespace: "first", Instance: &testRegistryMethods{}},
+		{Namespace: "test", SubNamespace: "second", Instance: &testRegistryMethods{}},
+		{Namespace: "test", SubNamespace: "existing", Instance: &testRegistryMethods{}},
+	})
+	if err == nil {
+		t.Fatal("expected duplicate registration to fail")
+	}
+
+	if _, ok := registry.extensions["test"]["second"]; ok {
+		t.Fatal("expected entries added before the failure to be rolled back")
+	}
+	if _, ok := registry.extensions["test"]["existing"]; !ok {
+		t.Fatal("expected previously registered extension to be kept")
+	}
+	if _, ok := registry.extensions["batch"]; ok {
+		t.Fatal("expected empty namespace to be removed")
+	}
+}
+
+func TestRegistryExecuteMethodDecodesNamedStringParameters(t *testing.T) {
+	registry := newRegistry()
+	if err := registry.Register("test", "config", &testRegistryMethods{}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	result, err := registry.ExecuteMethod("test", "config", "SetMode", []interface{}{"manual"})
+	if err != nil {
+		t.Fatalf("ExecuteMethod returned error: %v", err)
+	}
+	if got, ok := result.(testRegistryMode); !ok || got != testRegistryModeManual {
+		t.Fatalf("expected %q as testRegistryMode, got %#v", testRegistryModeManual, result)
+	}
+
+	var info *MethodInfo
+	for _, method := range registry.extractMethods(&testRegistryMethods{}) {
+		if method.Name == "SetMode" {
+			info = &method
+		}
+	}
+	if info == nil {
+		t.Fatal("expected SetMode in extracted methods")
+	}
+	if info.ParamTypes[0] != "string" || info.ParamTypeNames[0] != "runtime.testRegistryMode" {
+		t.Fatalf("unexpected param metadata: %v %v", info.ParamTypes, info.ParamTypeNames)
+	}
+}
+
+func TestRuntimeRegistrationErrorsReportsDuplicates(t *testing.T) {
+	registeredRuntimeExtensionsMu.Lock()
+	saved := registeredRuntimeExtensions
+	registeredRuntimeExtensions = append(append([]registeredRuntimeExtension(nil), saved...),
+		registeredRuntimeExtension{namespace: "strux", subNamespace: "boot", instance: &testRegistryMethods{}})
+	registeredRuntimeExtensionsMu.Unlock()
+	defer func() {
+		registeredRuntimeExtensionsMu.Lock()
+		registeredRuntimeExtensions = saved
+		registeredRuntimeExtensionsMu.Unlock()
+	}()
+
+	rt := New(&testLifecycleApp{})
+	defer rt.Stop()
+
+	errs := rt.RegistrationErrors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "strux.boot") {
+		t.Fatalf("expected one strux.boot registration error, got %v", errs)
+	}
+}
@@ -1,18 +1,30 @@
 package runtime
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/strux-dev/strux/pkg/runtime/api"
 )
 
-const socketPath = "/tmp/strux-ipc.sock"
+// AppVersion is the app build version reported by __appInfo. The build sets it
+// with -ldflags "-X github.com/strux-dev/strux/pkg/runtime.AppVersion=<version>".
+var AppVersion string
+
+// AppDataDir is the writable directory for the app's persistent state, e.g.
+// its database. It survives updates and reboots and is erased by a factory
+// reset.
+const AppDataDir = api.AppDataDir
 
 const CapabilityDisplay = api.CapabilityDisplay
 const CapabilityNetwork = api.CapabilityNetwork
@@ -65,10 +77,38 @@ type Runtime struct {
 	listener   net.Listener
 	mu         sync.RWMutex
 	stopChan   chan struct{}
+	stopOnce   sync.Once
+	connsMu    sync.Mutex
+	conns      map[net.Conn]bool // open IPC connections, true while running a call
+	handlers   sync.WaitGroup    // running handleConnection goroutines
 	structName string
 	pkgName    string
 	extensions *Registry
 	events     *eventState
+	connCount  atomic.Int64           // active IPC connections (all channels)
+	groups     map[string]*fieldGroup // field groups from `strux:"group=..."` tags
+	fieldSubs  *fieldSubscriptions    // per-connection field change subscriptions
+	streams    *streamState           // io.Reader results waiting for the frontend to start them
+	inflight   *inflightCalls         // method calls that have not returned yet
+	trace      *traceLog              // IPC traffic recording, when RuntimeOptions.TraceFile is set
+
+	requiredParams map[string]int           // method path -> required params, for methods with optional trailing params
+	fireAndForget  map[string]bool          // method paths the frontend calls without waiting for a response
+	callSlots      map[string]chan struct{} // method path -> semaphore, for methods with a MaxConcurrent limit
+	returnNames    map[string][]string      // method path -> result names, for methods returning a named object
+
+	registrationErrs []error // extensions that failed to register at startup
+
+	authToken     string             // required by __auth before anything else, when set
+	startupCancel context.CancelFunc // cancels the app's Startup context; set once Startup succeeds
+
+	constantsMu sync.RWMutex
+	constants   map[string]interface{} // values registered with RegisterConstants, served by __constants
+
+	titleMu sync.Mutex       // serializes window title updates
+	cage    *api.CageControl // overrides the Cage control client (used in tests)
+
+	opts RuntimeOptions
 }
 
 type registeredRuntimeExtension struct {
@@ -94,13 +134,20 @@ type Response struct {
 	ID     string      `json:"id"`
 	Result interface{} `json:"result,omitempty"`
 	Error  string      `json:"error,omitempty"`
+	Code   string      `json:"code,omitempty"`   // ErrorCode of the failure; set whenever Error is
+	Stream bool        `json:"stream,omitempty"` // one value of a channel result; more follow
+	Done   bool        `json:"done,omitempty"`   // the end of a channel result
 }
 
 // MethodInfo describes a bound method for the frontend
 type MethodInfo struct {
-	Name       string   `json:"name"`
-	ParamCount int      `json:"paramCount"`
-	ParamTypes []string `json:"paramTypes"`
+	Name            string   `json:"name"`
+	ParamCount      int      `json:"paramCount"`
+	ParamTypes      []string `json:"paramTypes"`                // reflect kinds, e.g. "slice"; kept for older frontends
+	ParamTypeNames  []string `json:"paramTypeNames,omitempty"`  // Go type names, e.g. "gpio.Mode" for a named string type
+	ReturnTypeNames []string `json:"returnTypeNames,omitempty"` // Go type names of the results, including a trailing "error"
+	FireAndForget   bool     `json:"fireAndForget,omitempty"`   // Called without an ID; no response is sent
+	ReturnNames     []string `json:"returnNames,omitempty"`     // Keys of the result object, from the app's ReturnNames()
 }
 
 // FieldInfo describes a bound field for the frontend
@@ -109,17 +156,29 @@ type FieldInfo struct {
 	Type string `json:"type"`
 }
 
-// New creates a new Runtime instance
+// New creates a new Runtime instance with the default options
 func New(app interface{}) *Runtime {
+	return NewWithOptions(app, RuntimeOptions{})
+}
+
+// NewWithOptions creates a new Runtime instance configured by opts
+func NewWithOptions(app interface{}, opts RuntimeOptions) *Runtime {
 	rt := &Runtime{
+		opts:       opts,
+		authToken:  opts.AuthToken,
 		app:        app,
 		methods:    make(map[string]reflect.Value),
 		stopChan:   make(chan struct{}),
+		conns:      make(map[net.Conn]bool),
 		extensions: newRegistry(),
 		events:     newEventState(),
+		fieldSubs:  newFieldSubscriptions(),
+		streams:    newStreamState(),
+		inflight:   newInflightCalls(),
 	}
 
 	rt.extractMetadata()
+	api.SetEventEmitter(rt.Emit)
 
 	// Build the struct tree from the app, discovering all methods and fields
 	val := reflect.ValueOf(app)
@@ -129,6 +188,11 @@ func New(app interface{}) *Runtime {
 		typ = typ.Elem()
 	}
 	rt.tree = rt.buildStructTree(val, typ, "")
+	rt.buildFieldGroups()
+	rt.loadOptionalParams()
+	rt.loadFireAndForget()
+	rt.loadMaxConcurrent()
+	rt.loadReturnNames()
 
 	// Register built-in Strux framework extensions
 	rt.registerBuiltinExtensions()
@@ -148,12 +212,16 @@ func (rt *Runtime) buildStructTree(val reflect.Value, typ reflect.Type, pathPref
 		typ:       typ,
 	}
 
-	// Discover methods (pointer receiver first, then value receiver)
+	// Discover methods (pointer receiver first, then value receiver).
+	// Lifecycle hooks on the app root are called by the runtime, not the frontend.
 	if val.CanAddr() {
 		ptrVal := val.Addr()
 		ptrType := ptrVal.Type()
 		for i := 0; i < ptrType.NumMethod(); i++ {
 			name := ptrType.Method(i).Name
+			if pathPrefix == "" && rt.isLifecycleMethod(name) {
+				continue
+			}
 			if name[0] >= 'A' && name[0] <= 'Z' {
 				method := ptrVal.Method(i)
 				node.methods[name] = method
@@ -167,6 +235,9 @@ func (rt *Runtime) buildStructTree(val reflect.Value, typ reflect.Type, pathPref
 	}
 	for i := 0; i < val.NumMethod(); i++ {
 		name := typ.Method(i).Name
+		if pathPrefix == "" && rt.isLifecycleMethod(name) {
+			continue
+		}
 		if name[0] >= 'A' && name[0] <= 'Z' {
 			if _, exists := node.methods[name]; !exists {
 				method := val.Method(i)
@@ -215,21 +286,42 @@ func (rt *Runtime) buildStructTree(val reflect.Value, typ reflect.Type, pathPref
 	return node
 }
 
+// newMethodInfo describes a bound method's frontend signature. Injected
+// context.Context and *Session parameters are left out.
+func newMethodInfo(name string, typ reflect.Type) MethodInfo {
+	injected := injectedParams(typ)
+	paramTypes := make([]string, typ.NumIn()-injected)
+	paramTypeNames := make([]string, len(paramTypes))
+	for i := range paramTypes {
+		paramTypes[i] = typ.In(injected + i).Kind().String()
+		paramTypeNames[i] = typ.In(injected + i).String()
+	}
+	returnTypeNames := make([]string, typ.NumOut())
+	for i := range returnTypeNames {
+		returnTypeNames[i] = typ.Out(i).String()
+	}
+	return MethodInfo{
+		Name:            name,
+		ParamCount:      len(paramTypes),
+		ParamTypes:      paramTypes,
+		ParamTypeNames:  paramTypeNames,
+		ReturnTypeNames: returnTypeNames,
+	}
+}
+
 // serializeTreeNode converts a tree node to a JSON-serializable map for __getBindings
 func (rt *Runtime) serializeTreeNode(node *structTreeNode) map[string]interface{} {
 	// Methods
 	methods := make([]MethodInfo, 0, len(node.methods))
 	for name, method := range node.methods {
-		typ := method.Type()
-		paramTypes := make([]string, typ.NumIn())
-		for i := 0; i < typ.NumIn(); i++ {
-			paramTypes[i] = typ.In(i).Kind().String()
-		}
-		methods = append(methods, MethodInfo{
-			Name:       name,
-			ParamCount: typ.NumIn(),
-			ParamTypes: paramTypes,
-		})
+		path := name
+		if node.fieldPath != "" {
+			path = node.fieldPath + "." + name
+		}
+		info := newMethodInfo(name, method.Type())
+		info.FireAndForget = rt.fireAndForget[path]
+		info.ReturnNames = rt.returnNames[path]
+		methods = append(methods, info)
 	}
 
 	// Primitive fields only
@@ -283,16 +375,7 @@ func (rt *Runtime) GetMethodInfo() []MethodInfo {
 	}
 	info := make([]MethodInfo, 0, len(rt.tree.methods))
 	for name, method := range rt.tree.methods {
-		typ := method.Type()
-		paramTypes := make([]string, typ.NumIn())
-		for i := 0; i < typ.NumIn(); i++ {
-			paramTypes[i] = typ.In(i).Kind().String()
-		}
-		info = append(info, MethodInfo{
-			Name:       name,
-			ParamCount: typ.NumIn(),
-			ParamTypes: paramTypes,
-		})
+		info = append(info, newMethodInfo(name, method.Type()))
 	}
 	return info
 }
@@ -318,17 +401,88 @@ func (rt *Runtime) GetFieldInfo() []FieldInfo {
 
 // Start begins listening for IPC connections
 func (rt *Runtime) Start() error {
-	os.Remove(socketPath)
-	listener, err := net.Listen("unix", socketPath)
+	listener, err := rt.listen()
 	if err != nil {
-		return fmt.Errorf("failed to create socket: %w", err)
+		return err
+	}
+	if err := rt.writeAuthToken(); err != nil {
+		rt.closeListener(listener)
+		return err
+	}
+	if err := rt.openTrace(); err != nil {
+		rt.closeListener(listener)
+		return err
 	}
 	rt.listener = listener
-	fmt.Printf("Strux Runtime: IPC server listening on %s\n", socketPath)
+	fmt.Printf("Strux Runtime: IPC server listening on %s\n", listener.Addr())
+
+	// Run the app's Startup and OnReady hooks before accepting the first
+	// connection. Connections made in the meantime wait in the listen backlog.
+	if err := rt.runStartupHook(); err != nil {
+		rt.closeListener(listener)
+		return fmt.Errorf("app Startup failed: %w", err)
+	}
+	if err := rt.runReadyHook(); err != nil {
+		rt.runStopHook()
+		rt.closeListener(listener)
+		return fmt.Errorf("app OnReady failed: %w", err)
+	}
+
+	go rt.syncTitle()
 	go rt.acceptConnections()
 	return nil
 }
 
+// listen creates the IPC listener for the configured transport
+func (rt *Runtime) listen() (net.Listener, error) {
+	transport, err := rt.opts.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	if transport == TransportTCP {
+		listener, err := net.Listen("tcp", rt.opts.tcpAddr())
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", rt.opts.tcpAddr(), err)
+		}
+		return listener, nil
+	}
+
+	socketPath := rt.opts.socketPath()
+	os.Remove(socketPath)
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket: %w", err)
+	}
+	if err := os.Chmod(socketPath, rt.opts.socketMode()); err != nil {
+		rt.closeListener(listener)
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// closeListener closes an IPC listener and removes its socket file and auth
+// token file, if any
+func (rt *Runtime) closeListener(listener net.Listener) {
+	listener.Close()
+	if _, ok := listener.(*net.UnixListener); ok {
+		os.Remove(rt.opts.socketPath())
+	}
+	if rt.opts.AuthTokenFile != "" {
+		os.Remove(rt.opts.AuthTokenFile)
+	}
+}
+
+// Addr returns the address the IPC server listens on, or nil before Start.
+// With TransportTCP this includes the port the OS chose when TCPAddr asks
+// for port 0, so the frontend can be told where to connect.
+func (rt *Runtime) Addr() net.Addr {
+	if rt.listener == nil {
+		return nil
+	}
+	return rt.listener.Addr()
+}
+
 // acceptConnections handles incoming IPC connections
 func (rt *Runtime) acceptConnections() {
 	for {
@@ -347,20 +501,52 @@ func (rt *Runtime) acceptConnections() {
 
 // handleConnection processes messages from a single connection.
 func (rt *Runtime) handleConnection(conn net.Conn) {
+	if !rt.trackConn(conn) {
+		conn.Close()
+		return
+	}
+	defer rt.untrackConn(conn)
+	rt.connCount.Add(1)
+	defer rt.connCount.Add(-1)
 	defer conn.Close()
+	session := newSession()
+	defer session.close()
 	decoder := json.NewDecoder(conn)
-	encoder := json.NewEncoder(conn)
+	out := io.Writer(conn)
+	if rt.trace != nil {
+		out = io.MultiWriter(conn, traceResponses{trace: rt.trace, session: session})
+		defer rt.trace.forgetSession(session)
+	}
+	client := &ipcClient{w: out}
+	encoder := json.NewEncoder(client)
 
 	var firstMsg json.RawMessage
+	rt.extendReadDeadline(conn)
 	if err := decoder.Decode(&firstMsg); err != nil {
 		return
 	}
 
+	// With an auth token, nothing else is accepted until __auth succeeds
+	if rt.authToken != "" {
+		if !rt.authenticate(firstMsg, encoder) {
+			return
+		}
+		firstMsg = nil
+		rt.extendReadDeadline(conn)
+		if err := decoder.Decode(&firstMsg); err != nil {
+			return
+		}
+	}
+
+	var first *Message // the first call, on plain connections
 	var handshake ChannelHandshake
 	if err := json.Unmarshal(firstMsg, &handshake); err == nil && handshake.Type == "handshake" {
-		encoder.Encode(map[string]interface{}{"type": "handshake", "ok": true})
+		if err := encoder.Encode(map[string]interface{}{"type": "handshake", "ok": true}); err != nil {
+			return
+		}
 
 		if handshake.Channel == "events" {
+			conn.SetReadDeadline(time.Time{})
 			rt.events.eventConnsMu.Lock()
 			rt.events.eventConns[conn] = struct{}{}
 			rt.events.eventConnsMu.Unlock()
@@ -370,24 +556,157 @@ func (rt *Runtime) handleConnection(conn net.Conn) {
 		}
 		fmt.Printf("Strux Runtime: %s channel connected\n", handshake.Channel)
 	} else {
-		var msg Message
-		if err := json.Unmarshal(firstMsg, &msg); err != nil {
+		// Plain connections (not the WPE extension's request/response
+		// channels) also receive events
+		rt.addIPCClient(client)
+		defer rt.removeIPCClient(client)
+
+		first = new(Message)
+		if err := json.Unmarshal(firstMsg, first); err != nil {
 			return
 		}
-		rt.handleMessage(msg, encoder)
 	}
 
+	// Messages are read on their own goroutine so a dropped connection is
+	// noticed while a call is still running, canceling the call's context
+	msgs := make(chan Message)
+	go func() {
+		defer close(msgs)
+		defer session.disconnect()
+		if first != nil {
+			select {
+			case msgs <- *first:
+			case <-session.ctx.Done():
+				return
+			}
+		}
+		for {
+			var msg Message
+			if err := decoder.Decode(&msg); err != nil {
+				return
+			}
+			select {
+			case msgs <- msg:
+			case <-session.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	rt.extendReadDeadline(conn)
 	for {
+		// Once Stop is called, connections close after their current call
+		select {
+		case <-rt.stopChan:
+			return
+		default:
+		}
 		var msg Message
-		if err := decoder.Decode(&msg); err != nil {
+		select {
+		case m, ok := <-msgs:
+			if !ok {
+				return
+			}
+			msg = m
+		case <-rt.stopChan:
+			return
+		}
+
+		// A connection waiting on a call is not idle
+		if rt.opts.IdleTimeout > 0 {
+			conn.SetReadDeadline(time.Time{})
+		}
+		if !rt.beginCall(conn) {
+			return
+		}
+		rt.trace.message(session, msg)
+		err := rt.handleMessage(msg, encoder, session)
+		rt.endCall(conn)
+		if err != nil {
+			fmt.Printf("Strux Runtime: Failed to write response, closing connection: %v\n", err)
 			return
 		}
-		rt.handleMessage(msg, encoder)
+		rt.extendReadDeadline(conn)
+	}
+}
+
+// trackConn registers a connection for Stop to wait on and close. Returns
+// false once Stop has been called.
+func (rt *Runtime) trackConn(conn net.Conn) bool {
+	rt.connsMu.Lock()
+	defer rt.connsMu.Unlock()
+	select {
+	case <-rt.stopChan:
+		return false
+	default:
+	}
+	rt.conns[conn] = false
+	rt.handlers.Add(1)
+	return true
+}
+
+// beginCall marks a tracked connection as running a call, so Stop lets the
+// call finish instead of closing the connection. Returns false once Stop has
+// been called.
+func (rt *Runtime) beginCall(conn net.Conn) bool {
+	rt.connsMu.Lock()
+	defer rt.connsMu.Unlock()
+	select {
+	case <-rt.stopChan:
+		return false
+	default:
 	}
+	rt.conns[conn] = true
+	return true
 }
 
-// handleMessage processes a single JSON-RPC message
-func (rt *Runtime) handleMessage(msg Message, encoder *json.Encoder) {
+// endCall marks a connection as idle again after beginCall
+func (rt *Runtime) endCall(conn net.Conn) {
+	rt.connsMu.Lock()
+	defer rt.connsMu.Unlock()
+	if _, ok := rt.conns[conn]; ok {
+		rt.conns[conn] = false
+	}
+}
+
+// untrackConn deregisters a connection added with trackConn
+func (rt *Runtime) untrackConn(conn net.Conn) {
+	rt.connsMu.Lock()
+	delete(rt.conns, conn)
+	rt.connsMu.Unlock()
+	rt.handlers.Done()
+}
+
+// closeConns closes every open IPC connection, or only those not running a
+// call when idleOnly is set
+func (rt *Runtime) closeConns(idleOnly bool) {
+	rt.connsMu.Lock()
+	defer rt.connsMu.Unlock()
+	for conn, busy := range rt.conns {
+		if !idleOnly || !busy {
+			conn.Close()
+		}
+	}
+}
+
+// extendReadDeadline pushes the connection's read deadline out by the idle
+// timeout, so a connection that stops sending is closed by the next Decode.
+func (rt *Runtime) extendReadDeadline(conn net.Conn) {
+	if rt.opts.IdleTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(rt.opts.IdleTimeout))
+	}
+}
+
+// handleMessage processes a single JSON-RPC message from the connection owning
+// session. The returned error is only set when the response could not be
+// written to the connection.
+func (rt *Runtime) handleMessage(msg Message, encoder *json.Encoder, session *Session) error {
+	// Messages without an ID are fire-and-forget: nobody is waiting for a reply
+	if msg.ID == "" {
+		rt.handleNotification(msg, session)
+		return nil
+	}
+
 	// __getBindings: return the struct tree + extensions
 	if msg.Method == "__getBindings" {
 		appBindings := rt.serializeTreeNode(rt.tree)
@@ -404,8 +723,63 @@ func (rt *Runtime) handleMessage(msg Message, encoder *json.Encoder) {
 			bindings[namespace] = subNamespaces
 		}
 
-		encoder.Encode(Response{ID: msg.ID, Result: bindings})
-		return
+		return encoder.Encode(Response{ID: msg.ID, Result: bindings})
+	}
+
+	// __auth: connections authenticate before their first message, so a
+	// later call (or one on a runtime without a token) has nothing to check
+	if msg.Method == authMethod {
+		return encoder.Encode(Response{ID: msg.ID, Result: true})
+	}
+
+	// __connections: number of active IPC connections
+	if msg.Method == "__connections" {
+		return encoder.Encode(Response{ID: msg.ID, Result: rt.ConnectionCount()})
+	}
+
+	// __inflight: method calls that have not returned yet
+	if msg.Method == "__inflight" {
+		return encoder.Encode(Response{ID: msg.ID, Result: rt.InflightCalls()})
+	}
+
+	// __cancel: cancel a running call by request ID, optionally limited to one
+	// session: [requestID] or [requestID, sessionID]
+	if msg.Method == "__cancel" {
+		var params []interface{}
+		if len(msg.Params) > 0 {
+			json.Unmarshal(msg.Params, &params)
+		}
+		if len(params) < 1 {
+			return encoder.Encode(Response{ID: msg.ID, Error: "request ID required", Code: string(CodeInvalidParams)})
+		}
+		requestID, ok := params[0].(string)
+		if !ok {
+			return encoder.Encode(Response{ID: msg.ID, Error: "request ID must be a string", Code: string(CodeInvalidParams)})
+		}
+		var sessionID uint64
+		if len(params) > 1 {
+			id, ok := params[1].(float64)
+			if !ok || id < 0 {
+				return encoder.Encode(Response{ID: msg.ID, Error: "session ID must be a number", Code: string(CodeInvalidParams)})
+			}
+			sessionID = uint64(id)
+		}
+		return encoder.Encode(Response{ID: msg.ID, Result: rt.CancelCall(requestID, sessionID)})
+	}
+
+	// __appInfo: app identity without the full bindings tree
+	if msg.Method == "__appInfo" {
+		return encoder.Encode(Response{ID: msg.ID, Result: rt.AppInfo()})
+	}
+
+	// __getEvents: events registered with RegisterEvent and their payload types
+	if msg.Method == "__getEvents" {
+		return encoder.Encode(Response{ID: msg.ID, Result: rt.Events()})
+	}
+
+	// __constants: values registered with RegisterConstants
+	if msg.Method == "__constants" {
+		return encoder.Encode(Response{ID: msg.ID, Result: rt.Constants()})
 	}
 
 	// __getField: support dotted paths (e.g. "Settings.Audio.MasterVolume")
@@ -415,62 +789,131 @@ func (rt *Runtime) handleMessage(msg Message, encoder *json.Encoder) {
 			json.Unmarshal(msg.Params, &params)
 		}
 		if len(params) < 1 {
-			encoder.Encode(Response{ID: msg.ID, Error: "field name required"})
-			return
+			return encoder.Encode(Response{ID: msg.ID, Error: "field name required", Code: string(CodeInvalidParams)})
 		}
 		fieldName, ok := params[0].(string)
 		if !ok {
-			encoder.Encode(Response{ID: msg.ID, Error: "field name must be a string"})
-			return
+			return encoder.Encode(Response{ID: msg.ID, Error: "field name must be a string", Code: string(CodeInvalidParams)})
 		}
 		value, err := rt.getField(fieldName)
-		errStr := ""
-		if err != nil {
-			errStr = err.Error()
+		resp := Response{ID: msg.ID, Result: value}
+		setResponseError(&resp, err)
+		return encoder.Encode(resp)
+	}
+
+	// __getFields: bulk read in one round-trip. Takes a list of field paths
+	// (empty = all bound fields); unknown fields are reported per field.
+	if msg.Method == "__getFields" {
+		var params []interface{}
+		if len(msg.Params) > 0 {
+			json.Unmarshal(msg.Params, &params)
 		}
-		encoder.Encode(Response{ID: msg.ID, Result: value, Error: errStr})
-		return
+		var names []string
+		if len(params) > 0 {
+			list, ok := params[0].([]interface{})
+			if !ok {
+				return encoder.Encode(Response{ID: msg.ID, Error: "field names must be an array", Code: string(CodeInvalidParams)})
+			}
+			for _, item := range list {
+				name, ok := item.(string)
+				if !ok {
+					return encoder.Encode(Response{ID: msg.ID, Error: "field names must be strings", Code: string(CodeInvalidParams)})
+				}
+				names = append(names, name)
+			}
+		}
+		return encoder.Encode(Response{ID: msg.ID, Result: rt.getFields(names)})
 	}
 
 	// __setField: support dotted paths
 	if msg.Method == "__setField" {
 		var params []interface{}
 		if len(msg.Params) > 0 {
-			json.Unmarshal(msg.Params, &params)
+			// UseNumber keeps large integers exact until they are decoded
+			decoder := json.NewDecoder(bytes.NewReader(msg.Params))
+			decoder.UseNumber()
+			decoder.Decode(&params)
 		}
 		if len(params) < 2 {
-			encoder.Encode(Response{ID: msg.ID, Error: "field name and value required"})
-			return
+			return encoder.Encode(Response{ID: msg.ID, Error: "field name and value required", Code: string(CodeInvalidParams)})
 		}
 		fieldName, ok := params[0].(string)
 		if !ok {
-			encoder.Encode(Response{ID: msg.ID, Error: "field name must be a string"})
-			return
+			return encoder.Encode(Response{ID: msg.ID, Error: "field name must be a string", Code: string(CodeInvalidParams)})
 		}
 		err := rt.setField(fieldName, params[1])
-		errStr := ""
-		if err != nil {
-			errStr = err.Error()
+		resp := Response{ID: msg.ID}
+		setResponseError(&resp, err)
+		if encodeErr := encoder.Encode(resp); encodeErr != nil {
+			return encodeErr
 		}
-		encoder.Encode(Response{ID: msg.ID, Error: errStr})
-		return
+		if err == nil {
+			rt.checkSubscribedFields()
+			if fieldName == titleField {
+				go rt.syncTitle()
+			}
+		}
+		return nil
 	}
 
 	// Execute method
-	result, err := rt.executeMethod(msg.Method, msg.Params)
-	resp := Response{ID: msg.ID}
+	ctx, done := rt.trackCall(msg, session)
+	result, err := rt.executeMethod(ctx, msg.Method, msg.Params, session)
+	if stream, ok := result.(*channelStream); ok {
+		// The call stays in flight, and cancelable, until the channel is drained
+		err = stream.drain(msg.ID, encoder)
+		done()
+		if err != nil {
+			return err
+		}
+		rt.checkSubscribedFields()
+		return nil
+	}
+	done()
+	resp := Response{ID: msg.ID, Result: result}
+	setResponseError(&resp, err)
+	if err := encoder.Encode(resp); err != nil {
+		return err
+	}
+
+	// Push changes to subscribed fields made by the call
+	rt.checkSubscribedFields()
+	return nil
+}
+
+// handleNotification runs a call sent without an ID. No response is written,
+// so failures are only logged.
+func (rt *Runtime) handleNotification(msg Message, session *Session) {
+	ctx, done := rt.trackCall(msg, session)
+	result, err := rt.executeMethod(ctx, msg.Method, msg.Params, session)
+	if stream, ok := result.(*channelStream); ok {
+		// Nobody is listening, but the method may block until its values are taken
+		stream.drain(msg.ID, json.NewEncoder(io.Discard))
+	}
+	done()
 	if err != nil {
-		resp.Error = err.Error()
-	} else {
-		resp.Result = result
+		fmt.Printf("Strux Runtime: Fire-and-forget call %s failed: %v\n", msg.Method, err)
+	}
+	rt.checkSubscribedFields()
+}
+
+// setResponseError fills in the error message and code of a response. Results
+// are dropped on error so the frontend only sees one or the other.
+func setResponseError(resp *Response, err error) {
+	if err == nil {
+		return
 	}
-	encoder.Encode(resp)
+	resp.Result = nil
+	resp.Error = err.Error()
+	resp.Code = errorCode(err)
 }
 
 // executeMethod calls a bound method. Checks the flat methods map first (which
 // contains both app methods and nested struct methods with full paths), then
-// falls back to extensions only for unmatched names.
-func (rt *Runtime) executeMethod(methodName string, paramsRaw json.RawMessage) (interface{}, error) {
+// falls back to extensions only for unmatched names. Methods taking a
+// context.Context receive ctx; methods taking a *Session receive session, or a
+// fresh one when the call has no connection.
+func (rt *Runtime) executeMethod(ctx context.Context, methodName string, paramsRaw json.RawMessage, session *Session) (interface{}, error) {
 	// Look up in flat methods map (covers app + all nested struct methods)
 	rt.mu.RLock()
 	method, exists := rt.methods[methodName]
@@ -482,41 +925,88 @@ func (rt *Runtime) executeMethod(methodName string, paramsRaw json.RawMessage) (
 		if len(parts) == 3 {
 			var params []interface{}
 			if len(paramsRaw) > 0 {
-				if err := json.Unmarshal(paramsRaw, &params); err != nil {
-					return nil, fmt.Errorf("invalid parameters: %w", err)
+				// UseNumber keeps large integers exact until they are decoded
+				decoder := json.NewDecoder(bytes.NewReader(paramsRaw))
+				decoder.UseNumber()
+				if err := decoder.Decode(&params); err != nil {
+					return nil, codedErrorf(CodeInvalidParams, "invalid parameters: %w", err)
 				}
 			}
-			return rt.extensions.ExecuteMethod(parts[0], parts[1], parts[2], params)
+			return rt.extensions.ExecuteMethodInSession(ctx, parts[0], parts[1], parts[2], params, session)
 		}
-		return nil, fmt.Errorf("method %s not found", methodName)
+		return nil, codedErrorf(CodeMethodNotFound, "method %s not found", methodName)
 	}
 
 	methodType := method.Type()
-	numParams := methodType.NumIn()
+	injected := injectedParams(methodType)
+	numParams := methodType.NumIn() - injected
+
+	params, err := splitParams(paramsRaw)
+	if err != nil {
+		return nil, err
+	}
 
-	var params []interface{}
-	if len(paramsRaw) > 0 {
-		if err := json.Unmarshal(paramsRaw, &params); err != nil {
-			return nil, fmt.Errorf("invalid parameters: %w", err)
+	required, optional := rt.requiredParams[methodName]
+	if !optional {
+		required = numParams
+		// A sole options struct may be omitted entirely; it decodes to its defaults
+		if numParams == 1 && isOptionsStruct(methodType.In(injected)) {
+			required = 0
 		}
 	}
+	if len(params) < required || len(params) > numParams {
+		if required == numParams {
+			return nil, codedErrorf(CodeInvalidParams, "expected %d parameters, got %d", numParams, len(params))
+		}
+		return nil, codedErrorf(CodeInvalidParams, "expected %d to %d parameters, got %d", required, numParams, len(params))
+	}
 
-	if len(params) != numParams {
-		return nil, fmt.Errorf("expected %d parameters, got %d", numParams, len(params))
+	timeout := rt.opts.callTimeout(methodName)
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
 	}
+	defer func() {
+		// Handed over to a channel result, which keeps the context until drained
+		if cancel != nil {
+			cancel()
+		}
+	}()
 
-	args := make([]reflect.Value, numParams)
+	if session == nil {
+		session = newSession()
+		defer session.close()
+	}
+	args := injectedArgs(methodType, ctx, session)
+
+	// Missing optional trailing parameters decode as their zero value
 	for i := 0; i < numParams; i++ {
-		expectedType := methodType.In(i)
-		paramJSON, _ := json.Marshal(params[i])
-		paramValue := reflect.New(expectedType)
-		if err := json.Unmarshal(paramJSON, paramValue.Interface()); err != nil {
-			return nil, fmt.Errorf("parameter %d type mismatch: %w", i, err)
+		var raw json.RawMessage
+		if i < len(params) {
+			raw = params[i]
+		}
+		paramValue, err := decodeParam(raw, methodType.In(injected+i))
+		if err != nil {
+			return nil, codedErrorf(CodeInvalidParams, "parameter %d type mismatch: %w", i, err)
 		}
-		args[i] = paramValue.Elem()
+		args = append(args, paramValue)
 	}
 
-	results := method.Call(args)
+	// Waiting for a slot counts against the call timeout
+	release, err := rt.acquireCallSlot(ctx, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	// A panicking or hung method fails this call instead of the whole
+	// connection. Its slot is held until it really returns.
+	results, err := callWithTimeout(method, args, timeout, release)
+	if err != nil {
+		if errorCode(err) == string(CodeTimeout) {
+			fmt.Printf("Strux Runtime: Method %s timed out after %s, leaving it running in the background\n", methodName, timeout)
+		}
+		return nil, err
+	}
 
 	if len(results) == 0 {
 		return nil, nil
@@ -533,7 +1023,31 @@ func (rt *Runtime) executeMethod(methodName string, paramsRaw json.RawMessage) (
 	if len(results) == 0 {
 		return nil, nil
 	}
+	if names, ok := rt.returnNames[methodName]; ok {
+		resultObject := make(map[string]interface{}, len(results))
+		for i, r := range results {
+			resultObject[names[i]] = r.Interface()
+		}
+		return resultObject, nil
+	}
 	if len(results) == 1 {
+		// io.Reader results are streamed over the events channel
+		if returnsReader(methodType) {
+			reader, _ := results[0].Interface().(io.Reader)
+			if reader == nil {
+				return nil, nil
+			}
+			return rt.openStream(reader), nil
+		}
+		// Channel results are drained by the caller, one response per value
+		if returnsChannel(methodType) {
+			if results[0].IsNil() {
+				return nil, nil
+			}
+			stream := &channelStream{ch: results[0], ctx: ctx, cancel: cancel}
+			cancel = nil
+			return stream, nil
+		}
 		return results[0].Interface(), nil
 	}
 
@@ -546,6 +1060,13 @@ func (rt *Runtime) executeMethod(methodName string, paramsRaw json.RawMessage) (
 
 // getField retrieves a field value, supporting dotted paths (e.g. "Settings.Audio.MasterVolume")
 func (rt *Runtime) getField(fieldName string) (interface{}, error) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	return rt.lookupField(fieldName)
+}
+
+// lookupField does the work of getField. The caller must hold rt.mu.
+func (rt *Runtime) lookupField(fieldName string) (interface{}, error) {
 	parts := strings.Split(fieldName, ".")
 
 	val := reflect.ValueOf(rt.app)
@@ -556,7 +1077,7 @@ func (rt *Runtime) getField(fieldName string) (interface{}, error) {
 	for _, part := range parts {
 		typ := val.Type()
 		if typ.Kind() != reflect.Struct {
-			return nil, fmt.Errorf("cannot access field %s on non-struct type %s", part, typ)
+			return nil, codedErrorf(CodeFieldNotFound, "cannot access field %s on non-struct type %s", part, typ)
 		}
 
 		found := false
@@ -565,7 +1086,7 @@ func (rt *Runtime) getField(fieldName string) (interface{}, error) {
 				val = val.Field(i)
 				if val.Kind() == reflect.Ptr {
 					if val.IsNil() {
-						return nil, fmt.Errorf("field %s is nil", part)
+						return nil, codedErrorf(CodeFieldNotFound, "field %s is nil", part)
 					}
 					val = val.Elem()
 				}
@@ -574,15 +1095,81 @@ func (rt *Runtime) getField(fieldName string) (interface{}, error) {
 			}
 		}
 		if !found {
-			return nil, fmt.Errorf("field %s not found", part)
+			return nil, codedErrorf(CodeFieldNotFound, "field %s not found", part)
 		}
 	}
 
 	return val.Interface(), nil
 }
 
-// setField sets a field value, supporting dotted paths (e.g. "Settings.Audio.MasterVolume")
+// FieldsResult is the result of a bulk field read. Values holds every field
+// that was read successfully; Errors holds a message for each one that was not.
+type FieldsResult struct {
+	Values map[string]interface{} `json:"values"`
+	Errors map[string]string      `json:"errors,omitempty"`
+}
+
+// getFields reads several fields at once. An empty list reads every bound
+// primitive field in the struct tree, keyed by its dotted path.
+func (rt *Runtime) getFields(names []string) FieldsResult {
+	// Read every field under one lock so the values are consistent
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	if len(names) == 0 {
+		names = rt.allFieldPaths(rt.tree)
+	}
+
+	result := FieldsResult{Values: make(map[string]interface{}, len(names))}
+	for _, name := range names {
+		value, err := rt.lookupField(name)
+		if err != nil {
+			if result.Errors == nil {
+				result.Errors = make(map[string]string)
+			}
+			result.Errors[name] = err.Error()
+			continue
+		}
+		result.Values[name] = value
+	}
+	return result
+}
+
+// allFieldPaths returns the dotted paths of all primitive fields under a node
+func (rt *Runtime) allFieldPaths(node *structTreeNode) []string {
+	if node == nil {
+		return nil
+	}
+	var paths []string
+	for name := range node.fields {
+		if node.fieldPath != "" {
+			name = node.fieldPath + "." + name
+		}
+		paths = append(paths, name)
+	}
+	for _, child := range node.children {
+		paths = append(paths, rt.allFieldPaths(child)...)
+	}
+	return paths
+}
+
+// setField sets a field value, supporting dotted paths (e.g. "Settings.Audio.MasterVolume"),
+// and broadcasts the change to every connected client
 func (rt *Runtime) setField(fieldName string, value interface{}) error {
+	rt.mu.Lock()
+	newValue, oldValue, err := rt.assignField(fieldName, value)
+	rt.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	rt.broadcastFieldChange(fieldName, newValue, oldValue)
+	return nil
+}
+
+// assignField does the work of setField, returning the new and previous
+// values. The caller must hold rt.mu for writing.
+func (rt *Runtime) assignField(fieldName string, value interface{}) (interface{}, interface{}, error) {
 	parts := strings.Split(fieldName, ".")
 
 	val := reflect.ValueOf(rt.app)
@@ -594,7 +1181,7 @@ func (rt *Runtime) setField(fieldName string, value interface{}) error {
 	for _, part := range parts[:len(parts)-1] {
 		typ := val.Type()
 		if typ.Kind() != reflect.Struct {
-			return fmt.Errorf("cannot access field %s on non-struct type %s", part, typ)
+			return nil, nil, codedErrorf(CodeFieldNotFound, "cannot access field %s on non-struct type %s", part, typ)
 		}
 
 		found := false
@@ -603,7 +1190,7 @@ func (rt *Runtime) setField(fieldName string, value interface{}) error {
 				val = val.Field(i)
 				if val.Kind() == reflect.Ptr {
 					if val.IsNil() {
-						return fmt.Errorf("field %s is nil", part)
+						return nil, nil, codedErrorf(CodeFieldNotFound, "field %s is nil", part)
 					}
 					val = val.Elem()
 				}
@@ -612,7 +1199,7 @@ func (rt *Runtime) setField(fieldName string, value interface{}) error {
 			}
 		}
 		if !found {
-			return fmt.Errorf("field %s not found", part)
+			return nil, nil, codedErrorf(CodeFieldNotFound, "field %s not found", part)
 		}
 	}
 
@@ -620,44 +1207,117 @@ func (rt *Runtime) setField(fieldName string, value interface{}) error {
 	targetName := parts[len(parts)-1]
 	typ := val.Type()
 	if typ.Kind() != reflect.Struct {
-		return fmt.Errorf("cannot access field %s on non-struct type %s", targetName, typ)
+		return nil, nil, codedErrorf(CodeFieldNotFound, "cannot access field %s on non-struct type %s", targetName, typ)
 	}
 
 	for i := 0; i < typ.NumField(); i++ {
 		if typ.Field(i).Name == targetName {
 			fieldValue := val.Field(i)
 			if !fieldValue.CanSet() {
-				return fmt.Errorf("field %s cannot be set", fieldName)
+				return nil, nil, codedErrorf(CodeInvalidParams, "field %s cannot be set", fieldName)
 			}
 
-			newValue := reflect.ValueOf(value)
-			if newValue.Type() != fieldValue.Type() {
-				jsonData, err := json.Marshal(value)
-				if err != nil {
-					return fmt.Errorf("failed to convert value: %w", err)
-				}
-				newValuePtr := reflect.New(fieldValue.Type())
-				if err := json.Unmarshal(jsonData, newValuePtr.Interface()); err != nil {
-					return fmt.Errorf("failed to convert value to %s: %w", fieldValue.Type(), err)
-				}
-				newValue = newValuePtr.Elem()
+			newValue, err := convertFieldValue(value, fieldValue.Type())
+			if err != nil {
+				return nil, nil, err
 			}
 
+			oldValue := fieldValue.Interface()
 			fieldValue.Set(newValue)
-			return nil
+			return fieldValue.Interface(), oldValue, nil
 		}
 	}
 
-	return fmt.Errorf("field %s not found", targetName)
+	return nil, nil, codedErrorf(CodeFieldNotFound, "field %s not found", targetName)
 }
 
-// Stop shuts down the IPC server
-func (rt *Runtime) Stop() {
-	close(rt.stopChan)
-	if rt.listener != nil {
-		rt.listener.Close()
+// AppInfo identifies the running app and build
+type AppInfo struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Version string `json:"version"`
+}
+
+// AppInfo returns the app struct name, its Go package name and the build
+// version (empty when not set at build time).
+func (rt *Runtime) AppInfo() AppInfo {
+	return AppInfo{
+		Name:    rt.structName,
+		Package: rt.pkgName,
+		Version: AppVersion,
 	}
-	os.Remove(socketPath)
+}
+
+// ConnectionCount returns the number of currently open IPC connections,
+// across the sync, async and events channels.
+func (rt *Runtime) ConnectionCount() int {
+	return int(rt.connCount.Load())
+}
+
+// stopGracePeriod is how long Stop waits for in-flight calls to finish
+const stopGracePeriod = 5 * time.Second
+
+// Stop shuts down the IPC server and then calls the app's OnShutdown hook,
+// giving in-flight calls up to 5 seconds to finish first. It is safe to call
+// more than once; only the first call has any effect.
+func (rt *Runtime) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), stopGracePeriod)
+	defer cancel()
+	rt.StopContext(ctx)
+}
+
+// StopContext shuts down the IPC server gracefully: it stops accepting
+// connections, closes those not running a call, lets the rest finish the call
+// they are running, and waits for them until ctx is done. Connections still busy then are closed, which
+// cancels their calls' contexts, and ctx's error is returned. The socket file
+// is removed and the app's OnShutdown and Shutdown hooks called last; an error
+// from Shutdown is returned if ctx did not expire. Only the first call to Stop
+// or StopContext has any effect.
+func (rt *Runtime) StopContext(ctx context.Context) error {
+	var err error
+	rt.stopOnce.Do(func() {
+		rt.connsMu.Lock()
+		close(rt.stopChan)
+		rt.connsMu.Unlock()
+		if rt.listener != nil {
+			rt.listener.Close()
+		}
+
+		// Connections between calls, including ones still waiting for their
+		// first message or __auth, would otherwise block in Decode until ctx
+		// is done. No call can start on them once stopChan is closed.
+		rt.closeConns(true)
+
+		// Event channels never finish on their own
+		rt.events.eventConnsMu.RLock()
+		for conn := range rt.events.eventConns {
+			conn.Close()
+		}
+		rt.events.eventConnsMu.RUnlock()
+
+		drained := make(chan struct{})
+		go func() {
+			rt.handlers.Wait()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			err = ctx.Err()
+			fmt.Printf("Strux Runtime: Calls still running at shutdown, closing their connections: %v\n", err)
+		}
+		rt.closeConns(false)
+
+		if rt.listener != nil {
+			rt.closeListener(rt.listener)
+		}
+		rt.runShutdownHook()
+		if stopErr := rt.runStopHook(); err == nil {
+			err = stopErr
+		}
+		rt.trace.close()
+	})
+	return err
 }
 
 // RegisterExtension registers an extension on this runtime instance.
@@ -679,8 +1339,9 @@ func RegisterCustomExtension(name string, instance interface{}) {
 	}
 }
 
-func (rt *Runtime) registerStruxAPI(namespace string, instance interface{}) error {
-	return rt.extensions.Register("strux", namespace, instance)
+// struxAPI returns the registry entry for a built-in API under window.strux.<namespace>
+func struxAPI(namespace string, instance interface{}) ExtensionEntry {
+	return ExtensionEntry{Namespace: "strux", SubNamespace: namespace, Instance: instance}
 }
 
 func (rt *Runtime) registerProcessExtensions() {
@@ -689,15 +1350,25 @@ func (rt *Runtime) registerProcessExtensions() {
 
 	for _, registered := range registeredRuntimeExtensions {
 		if err := rt.extensions.Register(registered.namespace, registered.subNamespace, registered.instance); err != nil {
-			fmt.Fprintf(os.Stderr, "Strux Runtime: failed to register extension %s.%s: %v\n",
-				registered.namespace,
-				registered.subNamespace,
-				err,
-			)
+			rt.recordRegistrationError(fmt.Errorf("extension %s.%s: %w", registered.namespace, registered.subNamespace, err))
 		}
 	}
 }
 
+// recordRegistrationError logs an extension that failed to register during
+// startup and keeps it for RegistrationErrors.
+func (rt *Runtime) recordRegistrationError(err error) {
+	fmt.Fprintf(os.Stderr, "Strux Runtime: ERROR: failed to register %v\n", err)
+	rt.registrationErrs = append(rt.registrationErrs, err)
+}
+
+// RegistrationErrors returns the errors from extensions that failed to register
+// when the runtime was created, e.g. two extensions claiming strux.boot. Those
+// extensions are not callable from the frontend.
+func (rt *Runtime) RegistrationErrors() []error {
+	return append([]error(nil), rt.registrationErrs...)
+}
+
 // RegisterExtension registers a process-wide extension provider. BSP packages can
 // call this from init() so every subsequently created Runtime exposes the methods
 // under window.<namespace>.<subNamespace>.
@@ -0,0 +1,1176 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strux-dev/strux/pkg/runtime/api"
+)
+
+func TestHandleConnectionExitsWhenResponseWriteFails(t *testing.T) {
+	rt := New(&testLifecycleApp{})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		rt.handleConnection(server)
+		close(done)
+	}()
+
+	// The pipe is synchronous, so the request has been read once Write returns.
+	// Closing the client makes the response write fail.
+	if _, err := client.Write([]byte(`{"id":"1","method":"Ping"}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	client.Close()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handleConnection did not exit after the frontend disconnected")
+	}
+
+	if count := rt.ConnectionCount(); count != 0 {
+		t.Fatalf("expected no active connections, got %d", count)
+	}
+}
+
+func TestHandleConnectionClosesIdleConnections(t *testing.T) {
+	rt := NewWithOptions(&testLifecycleApp{}, RuntimeOptions{IdleTimeout: 50 * time.Millisecond})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	done := make(chan struct{})
+	go func() {
+		rt.handleConnection(server)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handleConnection did not close an idle connection")
+	}
+}
+
+func TestSocketPathOption(t *testing.T) {
+	dir := t.TempDir()
+	var runtimes []*Runtime
+	for _, name := range []string{"a.sock", "b.sock"} {
+		rt := NewWithOptions(&testLifecycleApp{}, RuntimeOptions{SocketPath: filepath.Join(dir, name)})
+		if err := rt.Start(); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer rt.Stop()
+		runtimes = append(runtimes, rt)
+	}
+
+	for _, rt := range runtimes {
+		conn, err := net.Dial("unix", rt.opts.SocketPath)
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		conn.Close()
+	}
+
+	runtimes[0].Stop()
+	if _, err := os.Stat(filepath.Join(dir, "a.sock")); !os.IsNotExist(err) {
+		t.Fatal("expected Stop to remove the configured socket")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.sock")); err != nil {
+		t.Fatalf("expected the other runtime's socket to remain: %v", err)
+	}
+}
+
+func TestSocketModeOption(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		mode os.FileMode
+		want os.FileMode
+	}{
+		{"default.sock", 0, 0600},
+		{"group.sock", 0660, 0660},
+	}
+	for _, tt := range tests {
+		socketPath := filepath.Join(dir, tt.name)
+		rt := NewWithOptions(&testLifecycleApp{}, RuntimeOptions{SocketPath: socketPath, SocketMode: tt.mode})
+		if err := rt.Start(); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer rt.Stop()
+
+		info, err := os.Stat(socketPath)
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+		if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != tt.want {
+			t.Fatalf("%s: expected a socket with mode %v, got %v", tt.name, tt.want, info.Mode())
+		}
+	}
+}
+
+func TestTCPTransport(t *testing.T) {
+	rt := NewWithOptions(&testLifecycleApp{}, RuntimeOptions{Transport: TransportTCP})
+	if addr := rt.Addr(); addr != nil {
+		t.Fatalf("expected no address before Start, got %v", addr)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer rt.Stop()
+
+	addr, ok := rt.Addr().(*net.TCPAddr)
+	if !ok || addr.Port == 0 || !addr.IP.IsLoopback() {
+		t.Fatalf("expected a loopback address with the chosen port, got %v", rt.Addr())
+	}
+
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(`{"id":"1","method":"__getBindings","params":[]}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if resp.ID != "1" || resp.Error != "" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestAuthTokenRequired(t *testing.T) {
+	rt := NewWithOptions(&testLifecycleApp{}, RuntimeOptions{AuthToken: "secret"})
+	defer rt.Stop()
+
+	for _, first := range []string{
+		`{"id":"1","method":"__getBindings","params":[]}`,
+		`{"id":"1","method":"__auth","params":["wrong"]}`,
+	} {
+		server, client := net.Pipe()
+		go rt.handleConnection(server)
+		if _, err := client.Write([]byte(first + "\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		decoder := json.NewDecoder(client)
+		var resp Response
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if resp.ID != "1" || resp.Code != string(CodeUnauthorized) {
+			t.Fatalf("expected an Unauthorized error for %s, got %+v", first, resp)
+		}
+		if err := decoder.Decode(&resp); err == nil {
+			t.Fatalf("expected the connection to be closed after %s", first)
+		}
+		client.Close()
+	}
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+	decoder := json.NewDecoder(client)
+	for i, msg := range []string{
+		`{"id":"1","method":"__auth","params":["secret"]}`,
+		`{"id":"2","method":"__connections","params":[]}`,
+	} {
+		if _, err := client.Write([]byte(msg + "\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var resp Response
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if resp.Error != "" || resp.ID != fmt.Sprint(i+1) {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+	}
+}
+
+func TestAuthTokenFile(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "ipc.token")
+	rt := NewWithOptions(&testLifecycleApp{}, RuntimeOptions{SocketPath: filepath.Join(dir, "ipc.sock"), AuthTokenFile: tokenPath})
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer rt.Stop()
+
+	info, err := os.Stat(tokenPath)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected the token file to be 0600, got %v", info.Mode().Perm())
+	}
+	token, err := os.ReadFile(tokenPath)
+	if err != nil || len(token) == 0 || string(token) != rt.authToken {
+		t.Fatalf("expected the generated token in the file, got %q (err %v)", token, err)
+	}
+
+	rt.Stop()
+	if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+		t.Fatal("expected Stop to remove the token file")
+	}
+}
+
+func TestUnknownTransport(t *testing.T) {
+	rt := NewWithOptions(&testLifecycleApp{}, RuntimeOptions{Transport: "udp"})
+	defer rt.Stop()
+	if err := rt.Start(); err == nil {
+		t.Fatal("expected Start to reject an unknown transport")
+	}
+}
+
+type testProgress struct {
+	Percent int       `json:"percent"`
+	File    string    `json:"file,omitempty"`
+	At      time.Time `json:"at"`
+	Chunks  []int
+	secret  string
+}
+
+func TestRegisterEventReportsPayloadTypes(t *testing.T) {
+	rt := New(&testLifecycleApp{})
+	defer rt.Stop()
+	rt.RegisterEvent("progress", &testProgress{})
+	rt.RegisterEvent("done", nil)
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+
+	if _, err := client.Write([]byte(`{"id":"1","method":"__getEvents","params":[]}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var resp struct {
+		Result []EventInfo `json:"result"`
+	}
+	if err := json.NewDecoder(client).Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	expected := []EventInfo{
+		{Name: "done", TSType: "void"},
+		{Name: "progress", GoType: "*runtime.testProgress", TSType: "{ percent: number; file?: string; at: string; Chunks: number[] }"},
+	}
+	if len(resp.Result) != len(expected) {
+		t.Fatalf("expected %d events, got %+v", len(expected), resp.Result)
+	}
+	for i, event := range resp.Result {
+		if event != expected[i] {
+			t.Fatalf("event %d: expected %+v, got %+v", i, expected[i], event)
+		}
+	}
+}
+
+func TestConstantsCall(t *testing.T) {
+	rt := New(&testLifecycleApp{})
+	defer rt.Stop()
+	rt.RegisterConstants(map[string]interface{}{"MaxVolume": 100, "Channel": "beta"})
+	rt.RegisterConstants(map[string]interface{}{"Channel": "stable"})
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+
+	if _, err := client.Write([]byte(`{"id":"1","method":"__constants","params":[]}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var resp struct {
+		Result map[string]interface{} `json:"result"`
+	}
+	if err := json.NewDecoder(client).Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if len(resp.Result) != 2 || resp.Result["MaxVolume"] != float64(100) || resp.Result["Channel"] != "stable" {
+		t.Fatalf("unexpected constants %+v", resp.Result)
+	}
+}
+
+func TestAppInfoCall(t *testing.T) {
+	defer func(version string) { AppVersion = version }(AppVersion)
+	AppVersion = "1.4.2"
+
+	rt := New(&testLifecycleApp{})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+
+	if _, err := client.Write([]byte(`{"id":"1","method":"__appInfo","params":[]}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var resp struct {
+		Result map[string]interface{} `json:"result"`
+	}
+	if err := json.NewDecoder(client).Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	want := map[string]interface{}{"name": "testLifecycleApp", "package": "runtime", "version": "1.4.2"}
+	if !reflect.DeepEqual(resp.Result, want) {
+		t.Fatalf("expected app info %v, got %v", want, resp.Result)
+	}
+}
+
+func TestEmitNotifiesPlainConnections(t *testing.T) {
+	rt := New(&testLifecycleApp{})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+
+	decoder := json.NewDecoder(client)
+	if _, err := client.Write([]byte(`{"id":"1","method":"__connections","params":[]}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var resp Response
+	if err := decoder.Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	go rt.Emit("job:done", map[string]int{"count": 3})
+
+	var notification map[string]json.RawMessage
+	if err := decoder.Decode(&notification); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if _, hasID := notification["id"]; hasID {
+		t.Fatalf("expected a notification without an ID, got %v", notification)
+	}
+	if string(notification["method"]) != `"__event"` || string(notification["params"]) != `{"event":"job:done","data":{"count":3}}` {
+		t.Fatalf("unexpected notification method=%s params=%s", notification["method"], notification["params"])
+	}
+}
+
+func TestEmitSkipsExtensionChannels(t *testing.T) {
+	rt := New(&testLifecycleApp{})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+
+	decoder := json.NewDecoder(client)
+	if _, err := client.Write([]byte(`{"type":"handshake","channel":"sync"}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var ack map[string]interface{}
+	if err := decoder.Decode(&ack); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	// net.Pipe writes block until read, so a notification would hang Emit
+	done := make(chan struct{})
+	go func() {
+		rt.Emit("job:done", nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected no event on the extension's sync channel")
+	}
+}
+
+type testCodedError struct{}
+
+func (testCodedError) Error() string     { return "device is not paired" }
+func (testCodedError) ErrorCode() string { return "NotPaired" }
+
+type testErrorApp struct {
+	Title string
+}
+
+func (a *testErrorApp) Fail() error { return errors.New("boom") }
+
+func (a *testErrorApp) Pair() error { return fmt.Errorf("pair: %w", testCodedError{}) }
+
+func (a *testErrorApp) Add(x int) int { return x + 1 }
+
+func (a *testErrorApp) Crash() string {
+	var items []string
+	return items[1]
+}
+
+func TestExecuteMethodErrorCodes(t *testing.T) {
+	rt := New(&testErrorApp{})
+	defer rt.Stop()
+
+	tests := []struct {
+		method string
+		params string
+		code   string
+	}{
+		{"Missing", `[]`, "MethodNotFound"},
+		{"strux.missing.Call", `[]`, "MethodNotFound"},
+		{"Add", `[]`, "InvalidParams"},
+		{"Add", `["x"]`, "InvalidParams"},
+		{"Fail", `[]`, "AppError"},
+		{"Pair", `[]`, "NotPaired"},
+		{"Crash", `[]`, "Internal"},
+	}
+	for _, tt := range tests {
+		_, err := rt.executeMethod(context.Background(), tt.method, json.RawMessage(tt.params), nil)
+		if err == nil {
+			t.Fatalf("%s: expected an error", tt.method)
+		}
+		if code := errorCode(err); code != tt.code {
+			t.Fatalf("%s(%s): expected code %s, got %s (%v)", tt.method, tt.params, tt.code, code, err)
+		}
+	}
+
+	// A panic is reported with its value and keeps the connection usable
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+	decoder := json.NewDecoder(client)
+	for _, msg := range []string{`{"id":"1","method":"Crash","params":[]}`, `{"id":"2","method":"Add","params":[1]}`} {
+		if _, err := client.Write([]byte(msg + "\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var resp Response
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if resp.ID == "1" && (resp.Code != "Internal" || !strings.Contains(resp.Error, "index out of range")) {
+			t.Fatalf("expected an Internal error with the panic value, got %+v", resp)
+		}
+		if resp.ID == "2" && resp.Result != float64(2) {
+			t.Fatalf("expected the connection to keep working after a panic, got %+v", resp)
+		}
+	}
+
+	if _, err := rt.getField("Missing"); errorCode(err) != "FieldNotFound" {
+		t.Fatalf("expected FieldNotFound for unknown field, got %v", err)
+	}
+}
+
+type testStreamApp struct{}
+
+func (a *testStreamApp) Report(size int) (io.Reader, error) {
+	return strings.NewReader(strings.Repeat("x", size)), nil
+}
+
+func TestExecuteMethodStreamsReaders(t *testing.T) {
+	rt := New(&testStreamApp{})
+	defer rt.Stop()
+
+	size := streamChunkSize*2 + 10
+	result, err := rt.executeMethod(context.Background(), "Report", json.RawMessage(fmt.Sprintf("[%d]", size)), nil)
+	if err != nil {
+		t.Fatalf("executeMethod failed: %v", err)
+	}
+	handle, ok := result.(StreamHandle)
+	if !ok {
+		t.Fatalf("expected a StreamHandle, got %T", result)
+	}
+
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	if !rt.handleStreamEvent(server, EventMessage{Type: "event", Event: streamStartEvent, Data: handle.Stream}) {
+		t.Fatal("expected __streamStart to be handled")
+	}
+
+	decoder := json.NewDecoder(client)
+	var received []byte
+	for seq := 0; ; seq++ {
+		var msg struct {
+			Event string      `json:"event"`
+			Data  StreamFrame `json:"data"`
+		}
+		if err := decoder.Decode(&msg); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if msg.Event != streamFrameEvent || msg.Data.ID != handle.Stream || msg.Data.Seq != seq {
+			t.Fatalf("unexpected frame: %+v", msg)
+		}
+		chunk, err := base64.StdEncoding.DecodeString(msg.Data.Data)
+		if err != nil {
+			t.Fatalf("DecodeString failed: %v", err)
+		}
+		received = append(received, chunk...)
+		if msg.Data.Done {
+			if msg.Data.Error != "" {
+				t.Fatalf("unexpected stream error: %s", msg.Data.Error)
+			}
+			break
+		}
+	}
+	if len(received) != size {
+		t.Fatalf("expected %d bytes, got %d", size, len(received))
+	}
+
+	// A stream can only be started once
+	if rt.streams.take(handle.Stream) != nil {
+		t.Fatal("expected the stream to be consumed")
+	}
+}
+
+type testChannelApp struct {
+	stopped chan struct{}
+}
+
+func (a *testChannelApp) Count(n int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= n; i++ {
+			ch <- i
+		}
+	}()
+	return ch
+}
+
+func (a *testChannelApp) Forever(ctx context.Context) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(a.stopped)
+		for {
+			select {
+			case ch <- 1:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func TestExecuteMethodStreamsChannels(t *testing.T) {
+	rt := New(&testChannelApp{})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+
+	if err := json.NewEncoder(client).Encode(Message{ID: "4", Method: "Count", Params: json.RawMessage(`[3]`)}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	decoder := json.NewDecoder(client)
+	for i := 1; i <= 3; i++ {
+		var resp Response
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if resp.ID != "4" || !resp.Stream || resp.Done || resp.Result != float64(i) {
+			t.Fatalf("value %d: unexpected response %+v", i, resp)
+		}
+	}
+
+	var resp Response
+	if err := decoder.Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if resp.ID != "4" || resp.Stream || !resp.Done || resp.Error != "" {
+		t.Fatalf("unexpected final response %+v", resp)
+	}
+}
+
+func TestDisconnectStopsChannelDrain(t *testing.T) {
+	app := &testChannelApp{stopped: make(chan struct{})}
+	rt := New(app)
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	go rt.handleConnection(server)
+
+	if err := json.NewEncoder(client).Encode(Message{ID: "1", Method: "Forever", Params: json.RawMessage(`[]`)}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	var resp Response
+	if err := json.NewDecoder(client).Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !resp.Stream {
+		t.Fatalf("expected a streamed value, got %+v", resp)
+	}
+
+	client.Close()
+
+	select {
+	case <-app.stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("method context was not canceled when the connection closed")
+	}
+}
+
+type testSessionApp struct{}
+
+func (a *testSessionApp) Login(s *Session, user string) {
+	s.Set("user", user)
+}
+
+func (a *testSessionApp) WhoAmI(s *Session) string {
+	user, _ := s.Get("user")
+	name, _ := user.(string)
+	return name
+}
+
+func TestSessionsAreScopedToConnections(t *testing.T) {
+	rt := New(&testSessionApp{})
+	defer rt.Stop()
+
+	// Each connection gets its own session
+	call := func(conn net.Conn, decoder *json.Decoder, id, method, params string) Response {
+		t.Helper()
+		msg := fmt.Sprintf(`{"id":%q,"method":%q,"params":%s}`+"\n", id, method, params)
+		if _, err := conn.Write([]byte(msg)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var resp Response
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		return resp
+	}
+
+	connect := func() (net.Conn, *json.Decoder) {
+		server, client := net.Pipe()
+		go rt.handleConnection(server)
+		return client, json.NewDecoder(client)
+	}
+
+	first, firstDecoder := connect()
+	defer first.Close()
+	second, secondDecoder := connect()
+	defer second.Close()
+
+	if resp := call(first, firstDecoder, "1", "Login", `["ada"]`); resp.Error != "" {
+		t.Fatalf("Login failed: %s", resp.Error)
+	}
+	if resp := call(first, firstDecoder, "2", "WhoAmI", `[]`); resp.Result != "ada" {
+		t.Fatalf("expected the first connection to be ada, got %v", resp.Result)
+	}
+	if resp := call(second, secondDecoder, "1", "WhoAmI", `[]`); resp.Result != "" {
+		t.Fatalf("expected the second connection to have no user, got %v", resp.Result)
+	}
+
+	// The session parameter is not part of the frontend signature
+	for _, method := range rt.GetMethodInfo() {
+		if method.Name == "WhoAmI" && method.ParamCount != 0 {
+			t.Fatalf("expected WhoAmI to take no frontend params, got %d", method.ParamCount)
+		}
+	}
+}
+
+type testSignatureApp struct{}
+
+func (a *testSignatureApp) Tag(ctx context.Context, names []string, counts map[string]int) ([]int, error) {
+	return nil, nil
+}
+
+func TestMethodInfoReportsTypeNames(t *testing.T) {
+	rt := New(&testSignatureApp{})
+	defer rt.Stop()
+
+	info := rt.GetMethodInfo()
+	if len(info) != 1 {
+		t.Fatalf("expected one method, got %+v", info)
+	}
+	want := MethodInfo{
+		Name:            "Tag",
+		ParamCount:      2,
+		ParamTypes:      []string{"slice", "map"},
+		ParamTypeNames:  []string{"[]string", "map[string]int"},
+		ReturnTypeNames: []string{"[]int", "error"},
+	}
+	if !reflect.DeepEqual(info[0], want) {
+		t.Fatalf("expected %+v, got %+v", want, info[0])
+	}
+}
+
+func TestTraceFileRecordsTraffic(t *testing.T) {
+	tracePath := filepath.Join(t.TempDir(), "trace.jsonl")
+	rt := NewWithOptions(&testSessionApp{}, RuntimeOptions{TraceFile: tracePath, TraceRedact: []string{"Login"}})
+	defer rt.Stop()
+	if err := rt.openTrace(); err != nil {
+		t.Fatalf("openTrace failed: %v", err)
+	}
+
+	server, client := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		rt.handleConnection(server)
+		close(done)
+	}()
+	decoder := json.NewDecoder(client)
+	for _, msg := range []string{`{"id":"1","method":"Login","params":["ada"]}`, `{"id":"2","method":"WhoAmI","params":[]}`} {
+		if _, err := client.Write([]byte(msg + "\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var resp Response
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+	}
+	client.Close()
+	<-done
+
+	data, err := os.ReadFile(tracePath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var entries []TraceEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry TraceEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid trace line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 2 messages and 2 responses, got %d entries", len(entries))
+	}
+	expected := []struct{ dir, data string }{
+		{"in", `{"id":"1","method":"Login","params":"[redacted]"}`},
+		{"out", `{"id":"1"}`},
+		{"in", `{"id":"2","method":"WhoAmI","params":[]}`},
+		{"out", `{"id":"2","result":"ada"}`},
+	}
+	for i, entry := range entries {
+		if entry.Dir != expected[i].dir || string(entry.Data) != expected[i].data || entry.Time.IsZero() {
+			t.Fatalf("entry %d: expected %s %s, got %s %s", i, expected[i].dir, expected[i].data, entry.Dir, entry.Data)
+		}
+	}
+}
+
+func TestSessionCloseRunsCallbacks(t *testing.T) {
+	session := newSession()
+	var order []string
+	session.OnClose(func() { order = append(order, "first") })
+	session.OnClose(func() { order = append(order, "second") })
+	session.Set("user", "ada")
+
+	session.close()
+	session.close()
+
+	if strings.Join(order, ",") != "second,first" {
+		t.Fatalf("expected callbacks to run once in reverse order, got %v", order)
+	}
+	if _, ok := session.Get("user"); ok {
+		t.Fatal("expected values to be discarded on close")
+	}
+
+	ran := false
+	session.OnClose(func() { ran = true })
+	if !ran {
+		t.Fatal("expected OnClose on a closed session to run immediately")
+	}
+}
+
+type testTitleApp struct {
+	Title string
+}
+
+func TestSetTitleFieldUpdatesWindowTitle(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "control.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- string(data)
+		conn.Write([]byte("OK\n"))
+	}()
+
+	rt := New(&testTitleApp{Title: "Lobby"})
+	defer rt.Stop()
+	rt.cage = &api.CageControl{SocketPath: socketPath}
+
+	var out bytes.Buffer
+	msg := Message{ID: "1", Method: "__setField", Params: json.RawMessage(`["Title", "Front Desk"]`)}
+	if err := rt.handleMessage(msg, json.NewEncoder(&out), nil); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got != "SET_TITLE Front Desk" {
+			t.Fatalf("unexpected control command %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("window title was not sent to Cage")
+	}
+}
+
+type testVolumeApp struct {
+	Volume int
+}
+
+func TestSetFieldBroadcastsChange(t *testing.T) {
+	rt := New(&testVolumeApp{Volume: 30})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+
+	decoder := json.NewDecoder(client)
+	if _, err := client.Write([]byte(`{"id":"1","method":"__connections","params":[]}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var resp Response
+	if err := decoder.Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	// Set the field from another client
+	go func() {
+		var out bytes.Buffer
+		rt.handleMessage(Message{ID: "2", Method: "__setField", Params: json.RawMessage(`["Volume", 42]`)}, json.NewEncoder(&out), nil)
+	}()
+
+	var notification map[string]json.RawMessage
+	if err := decoder.Decode(&notification); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if string(notification["method"]) != `"__event"` || string(notification["params"]) != `{"event":"__fieldChanged","data":{"field":"Volume","value":42,"oldValue":30}}` {
+		t.Fatalf("unexpected notification method=%s params=%s", notification["method"], notification["params"])
+	}
+}
+
+type testAudioSettings struct {
+	Volume int
+	Muted  bool
+}
+
+type testFieldsApp struct {
+	Title string
+	Audio testAudioSettings
+}
+
+func TestGetFieldsReadsSeveralFields(t *testing.T) {
+	rt := New(&testFieldsApp{Title: "Lobby", Audio: testAudioSettings{Volume: 30}})
+	defer rt.Stop()
+
+	read := func(params string) Response {
+		t.Helper()
+		var out bytes.Buffer
+		if err := rt.handleMessage(Message{ID: "1", Method: "__getFields", Params: json.RawMessage(params)}, json.NewEncoder(&out), nil); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+		var resp Response
+		if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := read(`[["Title", "Audio.Volume", "Missing", "Audio.Missing"]]`)
+	if resp.Error != "" {
+		t.Fatalf("expected per-field errors only, got %q", resp.Error)
+	}
+	result, _ := json.Marshal(resp.Result)
+	var fields FieldsResult
+	json.Unmarshal(result, &fields)
+	if want := map[string]interface{}{"Title": "Lobby", "Audio.Volume": float64(30)}; !reflect.DeepEqual(fields.Values, want) {
+		t.Fatalf("expected values %v, got %v", want, fields.Values)
+	}
+	if len(fields.Errors) != 2 || fields.Errors["Missing"] == "" || fields.Errors["Audio.Missing"] == "" {
+		t.Fatalf("expected errors for the two unknown fields, got %v", fields.Errors)
+	}
+
+	// An empty list reads every bound field
+	resp = read(`[]`)
+	result, _ = json.Marshal(resp.Result)
+	fields = FieldsResult{}
+	json.Unmarshal(result, &fields)
+	if want := map[string]interface{}{"Title": "Lobby", "Audio.Volume": float64(30), "Audio.Muted": false}; !reflect.DeepEqual(fields.Values, want) || len(fields.Errors) != 0 {
+		t.Fatalf("expected every field %v, got %v (errors %v)", want, fields.Values, fields.Errors)
+	}
+
+	if resp := read(`["Title"]`); resp.Code != string(CodeInvalidParams) {
+		t.Fatalf("expected InvalidParams when the names are not a list, got %+v", resp)
+	}
+}
+
+type testSubscribeApp struct {
+	Volume int
+}
+
+func (a *testSubscribeApp) Louder() { a.Volume += 10 }
+
+func TestSubscribeFieldPushesChanges(t *testing.T) {
+	rt := New(&testSubscribeApp{Volume: 30})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	go rt.handleConnection(server)
+
+	decoder := json.NewDecoder(client)
+	if _, err := client.Write([]byte(`{"type":"handshake","channel":"events"}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var ack map[string]interface{}
+	if err := decoder.Decode(&ack); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	expectChange := func(want string) {
+		t.Helper()
+		var event map[string]json.RawMessage
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if string(event["event"]) != `"__fieldChanged"` || string(event["data"]) != want {
+			t.Fatalf("unexpected event %s with data %s", event["event"], event["data"])
+		}
+	}
+
+	// Subscribing pushes the current value straight away
+	if _, err := client.Write([]byte(`{"type":"event","event":"__subscribeField","data":"Volume"}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expectChange(`{"field":"Volume","value":30}`)
+
+	// A method call that changes the field notifies the subscriber
+	go func() {
+		var out bytes.Buffer
+		rt.handleMessage(Message{ID: "1", Method: "Louder", Params: json.RawMessage(`[]`)}, json.NewEncoder(&out), nil)
+	}()
+	expectChange(`{"field":"Volume","value":40}`)
+
+	// Disconnecting drops the subscription
+	client.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rt.fieldSubs.mu.Lock()
+		remaining := len(rt.fieldSubs.conns) + len(rt.fieldSubs.last)
+		rt.fieldSubs.mu.Unlock()
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the subscription to be removed on disconnect")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type testGroupApp struct {
+	Title  string `strux:"group=settings"`
+	Volume int    `strux:"group=settings"`
+	Muted  bool   `strux:"group=settings"`
+	Status string
+}
+
+func TestFieldGroupAccessors(t *testing.T) {
+	app := testGroupApp{Title: "Lobby", Volume: 30, Status: "idle"}
+	rt := New(&app)
+	defer rt.Stop()
+
+	result, err := rt.executeMethod(context.Background(), "GetSettings", json.RawMessage(`[]`), nil)
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	want := map[string]interface{}{"Title": "Lobby", "Volume": 30, "Muted": false}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("expected %v, got %v", want, result)
+	}
+
+	if _, err := rt.executeMethod(context.Background(), "SetSettings", json.RawMessage(`[{"Volume":55,"Muted":true}]`), nil); err != nil {
+		t.Fatalf("SetSettings failed: %v", err)
+	}
+	if app.Volume != 55 || !app.Muted || app.Title != "Lobby" {
+		t.Fatalf("expected Volume=55 Muted=true Title=Lobby, got %+v", app)
+	}
+
+	// One bad value leaves every field untouched
+	err = rt.SetGroup("settings", map[string]interface{}{"Volume": 10, "Muted": "loud"})
+	if errorCode(err) != "InvalidParams" {
+		t.Fatalf("expected InvalidParams for a bad value, got %v", err)
+	}
+	if app.Volume != 55 || !app.Muted {
+		t.Fatalf("expected a failed SetGroup to change nothing, got %+v", app)
+	}
+
+	if err := rt.SetGroup("settings", map[string]interface{}{"Status": "busy"}); errorCode(err) != "FieldNotFound" {
+		t.Fatalf("expected FieldNotFound for a field outside the group, got %v", err)
+	}
+	if _, err := rt.GetGroup("audio"); errorCode(err) != "FieldNotFound" {
+		t.Fatalf("expected FieldNotFound for an unknown group, got %v", err)
+	}
+}
+
+func TestSetGroupBroadcastsChange(t *testing.T) {
+	rt := New(&testGroupApp{Volume: 30})
+	defer rt.Stop()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go rt.handleConnection(server)
+
+	decoder := json.NewDecoder(client)
+	if _, err := client.Write([]byte(`{"id":"1","method":"__connections","params":[]}` + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var resp Response
+	if err := decoder.Decode(&resp); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	go rt.SetGroup("settings", map[string]interface{}{"Volume": 42})
+
+	var notification map[string]json.RawMessage
+	if err := decoder.Decode(&notification); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if string(notification["method"]) != `"__event"` || string(notification["params"]) != `{"event":"__fieldChanged","data":{"field":"Volume","value":42,"oldValue":30}}` {
+		t.Fatalf("unexpected notification method=%s params=%s", notification["method"], notification["params"])
+	}
+}
+
+type testInflightApp struct{}
+
+func (a *testInflightApp) Wait(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCancelInflightCall(t *testing.T) {
+	rt := New(&testInflightApp{})
+	defer rt.Stop()
+
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- rt.handleMessage(Message{ID: "7", Method: "Wait", Params: json.RawMessage(`[]`)}, json.NewEncoder(&out), newSession())
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rt.InflightCalls()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("call never showed up as in flight")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if calls := rt.InflightCalls(); calls[0].ID != "7" || calls[0].Method != "Wait" {
+		t.Fatalf("unexpected in-flight calls: %+v", calls)
+	}
+
+	var cancelOut bytes.Buffer
+	msg := Message{ID: "8", Method: "__cancel", Params: json.RawMessage(`["7"]`)}
+	if err := rt.handleMessage(msg, json.NewEncoder(&cancelOut), nil); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	if !strings.Contains(cancelOut.String(), `"result":1`) {
+		t.Fatalf("expected one call to be canceled, got %s", cancelOut.String())
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("canceled call did not return")
+	}
+	if !strings.Contains(out.String(), "context canceled") {
+		t.Fatalf("expected a cancellation error, got %s", out.String())
+	}
+	if calls := rt.InflightCalls(); len(calls) != 0 {
+		t.Fatalf("expected no in-flight calls, got %+v", calls)
+	}
+}
+
+func TestDisconnectCancelsInflightCall(t *testing.T) {
+	rt := New(&testInflightApp{})
+	defer rt.Stop()
+
+	client, server := net.Pipe()
+	go rt.handleConnection(server)
+
+	if err := json.NewEncoder(client).Encode(Message{ID: "1", Method: "Wait", Params: json.RawMessage(`[]`)}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rt.InflightCalls()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("call never showed up as in flight")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	client.Close()
+
+	deadline = time.Now().Add(2 * time.Second)
+	for len(rt.InflightCalls()) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("call was not canceled when the connection closed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type testTimeoutApp struct {
+	release chan struct{}
+}
+
+func (a *testTimeoutApp) Hang() string {
+	<-a.release
+	return "late"
+}
+
+func (a *testTimeoutApp) Deadline(ctx context.Context) bool {
+	_, ok := ctx.Deadline()
+	return ok
+}
+
+func TestCallTimeout(t *testing.T) {
+	app := &testTimeoutApp{release: make(chan struct{})}
+	defer close(app.release)
+	rt := NewWithOptions(app, RuntimeOptions{
+		CallTimeout:    50 * time.Millisecond,
+		MethodTimeouts: map[string]time.Duration{"Deadline": 0},
+	})
+	defer rt.Stop()
+
+	_, err := rt.executeMethod(context.Background(), "Hang", json.RawMessage(`[]`), nil)
+	if err == nil || errorCode(err) != string(CodeTimeout) {
+		t.Fatalf("expected a Timeout error, got %v", err)
+	}
+
+	// A zero override disables the timeout, so no deadline is set
+	result, err := rt.executeMethod(context.Background(), "Deadline", json.RawMessage(`[]`), nil)
+	if err != nil {
+		t.Fatalf("Deadline failed: %v", err)
+	}
+	if result != false {
+		t.Fatalf("expected no deadline, got %v", result)
+	}
+}
@@ -82,7 +82,12 @@ func resolveHTTPAddr() string {
 // It serves static files from /strux/frontend when available, otherwise ./frontend.
 // This function blocks on the HTTP server — call it from main().
 func Start(app interface{}) error {
-	rt, err := Init(app)
+	return StartWithOptions(app, RuntimeOptions{})
+}
+
+// StartWithOptions is Start with a configured Runtime.
+func StartWithOptions(app interface{}, opts RuntimeOptions) error {
+	rt, err := InitWithOptions(app, opts)
 	if err != nil {
 		return err
 	}
@@ -96,7 +101,12 @@ func Start(app interface{}) error {
 // without blocking. Use this instead of Start when you need access to the
 // Runtime for events (Emit/On/Off). Call rt.Serve() to start the HTTP server.
 func Init(app interface{}) (*Runtime, error) {
-	rt := New(app)
+	return InitWithOptions(app, RuntimeOptions{})
+}
+
+// InitWithOptions is Init with a configured Runtime.
+func InitWithOptions(app interface{}, opts RuntimeOptions) (*Runtime, error) {
+	rt := NewWithOptions(app, opts)
 	if err := rt.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start IPC server: %w", err)
 	}
@@ -0,0 +1,145 @@
+package runtime
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Session holds state scoped to a single frontend connection, such as an
+// authenticated identity or a set of subscriptions. A session is created when
+// the connection is accepted and discarded when it closes, so nothing stored in
+// it leaks to other clients.
+//
+// App methods receive the session of the connection that called them by
+// declaring *runtime.Session as their first parameter:
+//
+//	func (a *App) Login(s *runtime.Session, token string) error {
+//		s.Set("user", lookupUser(token))
+//		return nil
+//	}
+//
+// The session parameter is supplied by the runtime and is not part of the
+// method's frontend signature.
+type Session struct {
+	id uint64
+
+	// ctx is canceled as soon as the connection is lost, while a call may
+	// still be running; the session itself is closed once that call returns
+	ctx        context.Context
+	disconnect context.CancelFunc
+
+	mu      sync.Mutex
+	values  map[string]interface{}
+	onClose []func()
+	closed  bool
+}
+
+var (
+	// sessionType and contextType are the reflect types of injected parameters
+	sessionType = reflect.TypeOf((*Session)(nil))
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+	lastSessionID atomic.Uint64
+)
+
+// newSession creates an empty session with the next session ID
+func newSession() *Session {
+	ctx, disconnect := context.WithCancel(context.Background())
+	return &Session{
+		id:         lastSessionID.Add(1),
+		ctx:        ctx,
+		disconnect: disconnect,
+		values:     make(map[string]interface{}),
+	}
+}
+
+// ID returns a number identifying the session, unique within the process.
+func (s *Session) ID() uint64 {
+	return s.id
+}
+
+// Get returns the value stored under key, if any.
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Set stores a value under key, replacing any previous value.
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// OnClose registers a function to run when the connection closes, e.g. to
+// release resources tied to the session. If the session is already closed, fn
+// runs immediately.
+func (s *Session) OnClose(fn func()) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		fn()
+		return
+	}
+	s.onClose = append(s.onClose, fn)
+	s.mu.Unlock()
+}
+
+// close discards the session's values and runs its OnClose callbacks in
+// reverse registration order. Only the first call has any effect.
+func (s *Session) close() {
+	s.disconnect()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	callbacks := s.onClose
+	s.onClose = nil
+	s.values = make(map[string]interface{})
+	s.mu.Unlock()
+
+	for i := len(callbacks) - 1; i >= 0; i-- {
+		callbacks[i]()
+	}
+}
+
+// injectedParams returns how many leading parameters of a method are supplied
+// by the runtime rather than the frontend: an optional context.Context
+// followed by an optional *Session.
+func injectedParams(methodType reflect.Type) int {
+	n := 0
+	if n < methodType.NumIn() && methodType.In(n) == contextType {
+		n++
+	}
+	if n < methodType.NumIn() && methodType.In(n) == sessionType {
+		n++
+	}
+	return n
+}
+
+// injectedArgs returns the values of a method's injected parameters
+func injectedArgs(methodType reflect.Type, ctx context.Context, session *Session) []reflect.Value {
+	var args []reflect.Value
+	for i := 0; i < injectedParams(methodType); i++ {
+		if methodType.In(i) == contextType {
+			args = append(args, reflect.ValueOf(&ctx).Elem())
+		} else {
+			args = append(args, reflect.ValueOf(session))
+		}
+	}
+	return args
+}
@@ -0,0 +1,218 @@
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Stream protocol (on the events channel):
+//
+//	method result:  {"stream":"3"}
+//	JS -> Go:       {"type":"event","event":"__streamStart","data":"3"}
+//	Go -> JS:       {"type":"event","event":"__stream","data":{"id":"3","seq":0,"data":"<base64>"}}
+//	Go -> JS:       {"type":"event","event":"__stream","data":{"id":"3","seq":1,"done":true}}
+//
+// A method returning io.Reader resolves with a StreamHandle instead of a value.
+// Nothing is read until the frontend asks for the stream, so frames can't
+// arrive before it knows the ID. The last frame has done set, and error set if
+// reading failed.
+const (
+	streamStartEvent = "__streamStart"
+	streamFrameEvent = "__stream"
+
+	// streamChunkSize is the number of bytes read into each frame
+	streamChunkSize = 32 * 1024
+
+	// streamStartTimeout discards streams the frontend never starts
+	streamStartTimeout = 30 * time.Second
+)
+
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+// StreamHandle is the result of a method that returns io.Reader
+type StreamHandle struct {
+	Stream string `json:"stream"`
+}
+
+// StreamFrame is the payload of a __stream event
+type StreamFrame struct {
+	ID    string `json:"id"`
+	Seq   int    `json:"seq"`
+	Data  string `json:"data,omitempty"` // base64-encoded chunk
+	Done  bool   `json:"done,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// streamState tracks readers waiting for the frontend to start them
+type streamState struct {
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[string]io.Reader
+}
+
+func newStreamState() *streamState {
+	return &streamState{pending: make(map[string]io.Reader)}
+}
+
+// soleResult returns a method's only non-error result type
+func soleResult(methodType reflect.Type) (reflect.Type, bool) {
+	numOut := methodType.NumOut()
+	if numOut == 2 && methodType.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		numOut = 1
+	}
+	if numOut != 1 {
+		return nil, false
+	}
+	return methodType.Out(0), true
+}
+
+// returnsReader reports whether a method's only non-error result is io.Reader
+func returnsReader(methodType reflect.Type) bool {
+	t, ok := soleResult(methodType)
+	return ok && t == readerType
+}
+
+// returnsChannel reports whether a method's only non-error result is a channel
+// it can be received from, e.g. <-chan Progress
+func returnsChannel(methodType reflect.Type) bool {
+	t, ok := soleResult(methodType)
+	return ok && t.Kind() == reflect.Chan && t.ChanDir()&reflect.RecvDir != 0
+}
+
+// openStream registers a reader and returns the handle sent to the frontend.
+// Readers that are never started are closed (if they are io.Closers) after
+// streamStartTimeout.
+func (rt *Runtime) openStream(reader io.Reader) StreamHandle {
+	s := rt.streams
+	s.mu.Lock()
+	s.nextID++
+	id := strconv.FormatUint(s.nextID, 10)
+	s.pending[id] = reader
+	s.mu.Unlock()
+
+	time.AfterFunc(streamStartTimeout, func() {
+		if reader := s.take(id); reader != nil {
+			fmt.Printf("Strux Runtime: Stream %s was never started, discarding\n", id)
+			closeReader(reader)
+		}
+	})
+
+	return StreamHandle{Stream: id}
+}
+
+// take removes and returns a pending reader, or nil if there is none
+func (s *streamState) take(id string) io.Reader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reader := s.pending[id]
+	delete(s.pending, id)
+	return reader
+}
+
+// handleStreamEvent starts a pending stream on the events connection that asked
+// for it. Returns false if the event is not a stream event.
+func (rt *Runtime) handleStreamEvent(conn net.Conn, msg EventMessage) bool {
+	if msg.Event != streamStartEvent {
+		return false
+	}
+	id, _ := msg.Data.(string)
+	reader := rt.streams.take(id)
+	if reader == nil {
+		writeEvent(conn, streamFrameEvent, StreamFrame{ID: id, Done: true, Error: "stream not found"})
+		return true
+	}
+	go pumpStream(conn, id, reader)
+	return true
+}
+
+// pumpStream sends a reader's contents as __stream frames until EOF
+func pumpStream(conn net.Conn, id string, reader io.Reader) {
+	defer closeReader(reader)
+
+	buf := make([]byte, streamChunkSize)
+	for seq := 0; ; seq++ {
+		n, err := io.ReadFull(reader, buf)
+		frame := StreamFrame{ID: id, Seq: seq}
+		if n > 0 {
+			frame.Data = base64.StdEncoding.EncodeToString(buf[:n])
+		}
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			frame.Done = true
+		default:
+			frame.Done = true
+			frame.Error = err.Error()
+			fmt.Printf("Strux Runtime: Stream %s failed: %v\n", id, err)
+		}
+		if writeErr := writeEvent(conn, streamFrameEvent, frame); writeErr != nil || frame.Done {
+			return
+		}
+	}
+}
+
+// closeReader closes a stream's reader if it is an io.Closer
+func closeReader(reader io.Reader) {
+	if closer, ok := reader.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// Channel results (on the connection that made the call):
+//
+//	Go -> JS: {"id":"5","result":{"percent":10},"stream":true}
+//	Go -> JS: {"id":"5","result":{"percent":20},"stream":true}
+//	Go -> JS: {"id":"5","done":true}
+//
+// A method returning a receive channel has each value sent as its own
+// response to the call, until the method closes the channel. The final
+// response has done set, and error set if the call was canceled, timed out or
+// the connection closed before the channel was closed.
+
+// channelStream is the result of a method that returns a receive channel
+type channelStream struct {
+	ch     reflect.Value
+	ctx    context.Context    // the method's context, canceled when the drain stops
+	cancel context.CancelFunc // releases the call timeout, if any
+}
+
+// drain writes every value received from the channel as a streamed response
+// to call id, then the final response. The returned error is only set when a
+// response could not be written.
+func (s *channelStream) drain(id string, encoder *json.Encoder) error {
+	if s.cancel != nil {
+		defer s.cancel()
+	}
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: s.ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.ctx.Done())},
+	}
+	for {
+		chosen, value, ok := reflect.Select(cases)
+		if chosen == 1 {
+			err := s.ctx.Err()
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = codedErrorf(CodeTimeout, "stream did not finish in time")
+			}
+			resp := Response{ID: id, Done: true}
+			setResponseError(&resp, err)
+			return encoder.Encode(resp)
+		}
+		if !ok {
+			return encoder.Encode(Response{ID: id, Done: true})
+		}
+		if err := encoder.Encode(Response{ID: id, Result: value.Interface(), Stream: true}); err != nil {
+			return err
+		}
+	}
+}
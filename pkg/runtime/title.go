@@ -0,0 +1,48 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/strux-dev/strux/pkg/runtime/api"
+)
+
+// titleField is the top-level app field mirrored to the compositor window title
+const titleField = "Title"
+
+// hasTitleField reports whether the app has a string Title field
+func (rt *Runtime) hasTitleField() bool {
+	if rt.tree == nil {
+		return false
+	}
+	idx, ok := rt.tree.fields[titleField]
+	return ok && rt.tree.typ.Field(idx).Type.Kind() == reflect.String
+}
+
+// syncTitle sends the app's Title field to Cage so the window title follows
+// it. Called at startup and whenever the frontend sets Title. Cage isn't
+// running in dev mode, so an unavailable control socket is ignored.
+func (rt *Runtime) syncTitle() {
+	if !rt.hasTitleField() {
+		return
+	}
+
+	// Serialize updates so the last value read is the last one sent
+	rt.titleMu.Lock()
+	defer rt.titleMu.Unlock()
+
+	value, err := rt.getField(titleField)
+	if err != nil {
+		return
+	}
+	title := reflect.ValueOf(value).String()
+
+	cage := rt.cage
+	if cage == nil {
+		cage = api.NewCageControl()
+	}
+	if err := cage.SetTitle(title); err != nil && !errors.Is(err, api.ErrCageControlUnavailable) {
+		fmt.Printf("Strux Runtime: Failed to set window title: %v\n", err)
+	}
+}
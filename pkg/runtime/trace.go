@@ -0,0 +1,153 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Trace file format: one JSON object per line, in the order the runtime saw
+// them. "in" entries hold the Message as received, "out" entries the Response
+// as written:
+//
+//	{"time":"2026-01-02T15:04:05.123Z","session":3,"dir":"in","data":{"id":"1","method":"Greet","params":["ada"]}}
+//	{"time":"2026-01-02T15:04:05.124Z","session":3,"dir":"out","data":{"id":"1","result":"Hello ada"}}
+//
+// Replaying a trace means sending the "in" entries of a session, in order, on
+// one connection.
+const (
+	traceIn  = "in"
+	traceOut = "out"
+)
+
+// redactedValue replaces the params and results of redacted methods
+const redactedValue = `"[redacted]"`
+
+// TraceEntry is one line of a trace file
+type TraceEntry struct {
+	Time    time.Time       `json:"time"`
+	Session uint64          `json:"session"`
+	Dir     string          `json:"dir"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// traceLog appends IPC traffic to the trace file
+type traceLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	redact map[string]bool // method paths whose params and results are not recorded
+
+	// redactedIDs holds the request IDs of redacted calls per session, so
+	// their responses are redacted too
+	redactedIDs map[uint64]map[string]bool
+}
+
+// openTrace starts recording IPC traffic to RuntimeOptions.TraceFile, if set
+func (rt *Runtime) openTrace() error {
+	if rt.opts.TraceFile == "" {
+		return nil
+	}
+	file, err := os.OpenFile(rt.opts.TraceFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+
+	redact := make(map[string]bool, len(rt.opts.TraceRedact))
+	for _, method := range rt.opts.TraceRedact {
+		redact[method] = true
+	}
+	rt.trace = &traceLog{file: file, redact: redact, redactedIDs: make(map[uint64]map[string]bool)}
+	fmt.Printf("Strux Runtime: Tracing IPC traffic to %s\n", rt.opts.TraceFile)
+	return nil
+}
+
+// close stops recording and closes the trace file
+func (t *traceLog) close() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.file.Close()
+}
+
+// message records a message received from session
+func (t *traceLog) message(session *Session, msg Message) {
+	if t == nil {
+		return
+	}
+	if t.redact[msg.Method] {
+		msg.Params = json.RawMessage(redactedValue)
+		if msg.ID != "" {
+			t.mu.Lock()
+			if t.redactedIDs[session.ID()] == nil {
+				t.redactedIDs[session.ID()] = make(map[string]bool)
+			}
+			t.redactedIDs[session.ID()][msg.ID] = true
+			t.mu.Unlock()
+		}
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	t.write(session, traceIn, data)
+}
+
+// forgetSession drops the redaction state of a closed connection
+func (t *traceLog) forgetSession(session *Session) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	delete(t.redactedIDs, session.ID())
+	t.mu.Unlock()
+}
+
+// write appends an entry to the trace file. Failures are ignored so tracing
+// never affects the connection being traced.
+func (t *traceLog) write(session *Session, dir string, data []byte) {
+	line, err := json.Marshal(TraceEntry{Time: time.Now().UTC(), Session: session.ID(), Dir: dir, Data: data})
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.file.Write(append(line, '\n'))
+}
+
+// traceResponses records the responses written to one connection. It sits
+// behind the connection's encoder, so it sees each response as one JSON line.
+type traceResponses struct {
+	trace   *traceLog
+	session *Session
+}
+
+func (w traceResponses) Write(p []byte) (int, error) {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	data := p
+	if json.Unmarshal(p, &resp) == nil && resp.ID != "" {
+		w.trace.mu.Lock()
+		redacted := w.trace.redactedIDs[w.session.ID()][resp.ID]
+		delete(w.trace.redactedIDs[w.session.ID()], resp.ID)
+		w.trace.mu.Unlock()
+
+		if redacted {
+			var fields map[string]json.RawMessage
+			if json.Unmarshal(p, &fields) == nil {
+				if _, ok := fields["result"]; ok {
+					fields["result"] = json.RawMessage(redactedValue)
+				}
+				if redactedResp, err := json.Marshal(fields); err == nil {
+					data = redactedResp
+				}
+			}
+		}
+	}
+	w.trace.write(w.session, traceOut, data)
+	return len(p), nil
+}
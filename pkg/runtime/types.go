@@ -76,7 +76,7 @@ func (rt *Runtime) GenerateTypeScript(outputPath string) error {
 
 		// Build parameter list
 		params := []string{}
-		for j := 0; j < methodType.NumIn(); j++ {
+		for j := injectedParams(methodType); j < methodType.NumIn(); j++ {
 			paramType := methodType.In(j)
 			tsType := goTypeToTS(paramType)
 			params = append(params, fmt.Sprintf("arg%d: %s", j, tsType))
@@ -237,6 +237,9 @@ handle_signal(int signal, void *data)
 	case SIGTERM:
 		server_terminate(server);
 		return 0;
+	case SIGCHLD:
+		output_reap_cogs(server);
+		return 0;
 	default:
 		return 0;
 	}
@@ -256,7 +259,8 @@ usage(FILE *file, const char *cage)
 		" -m per-view Assign each new view to the next output in order\n"
 		" -s\t Allow VT switching\n"
 		" -v\t Show the version number and exit\n"
-		" --splash-image=PATH\t Show splash screen from PNG image\n"
+		" --splash-image=PATH\t Show splash screen from PNG or JPEG image\n"
+		" --splash-color=RRGGBB\t Splash background color, shown alone if there is no image\n"
 		" --only-display-image=PATH\t Display only a PNG image and do not launch applications\n"
 		"\n"
 		" Use -- when you want to pass arguments to APPLICATION\n",
@@ -268,6 +272,7 @@ parse_args(struct cg_server *server, int argc, char *argv[])
 {
 	static struct option long_options[] = {
 		{"splash-image", required_argument, NULL, 'S'},
+		{"splash-color", required_argument, NULL, 'C'},
 		{"only-display-image", required_argument, NULL, 'O'},
 		{"input-map", required_argument, NULL, 'I'},
 		{"display-map", required_argument, NULL, 'M'},
@@ -306,6 +311,10 @@ parse_args(struct cg_server *server, int argc, char *argv[])
 				server->splash_image_path = strdup(optarg);
 			}
 			break;
+		case 'C':
+			free(server->splash_color);
+			server->splash_color = strdup(optarg);
+			break;
 		case 'O':
 			free(server->splash_image_path);
 			server->splash_image_path = strdup(optarg);
@@ -362,6 +371,7 @@ main(int argc, char *argv[])
 	struct wl_event_loop *event_loop = wl_display_get_event_loop(server.wl_display);
 	struct wl_event_source *sigint_source = wl_event_loop_add_signal(event_loop, SIGINT, handle_signal, &server);
 	struct wl_event_source *sigterm_source = wl_event_loop_add_signal(event_loop, SIGTERM, handle_signal, &server);
+	struct wl_event_source *sigchld_cog_source = wl_event_loop_add_signal(event_loop, SIGCHLD, handle_signal, &server);
 
 	server.backend = wlr_backend_autocreate(event_loop, &server.session);
 	if (!server.backend) {
@@ -412,9 +422,12 @@ main(int argc, char *argv[])
 
 	server.scene_output_layout = wlr_scene_attach_output_layout(server.scene, server.output_layout);
 
-	// Create splash screen (shows framebuffer splash immediately if configured)
-	if (server.splash_image_path) {
-		server.splash = splash_create(&server, server.splash_image_path);
+	// Create splash screen (shows framebuffer splash immediately if configured).
+	// It is created even without a splash image or color, since it owns the
+	// control socket the client uses for NAVIGATE and SET_TITLE.
+	server.splash = splash_create(&server, server.splash_image_path, server.splash_color);
+	if (!server.splash) {
+		wlr_log(WLR_ERROR, "Unable to create the splash and control socket");
 	}
 
 	struct wlr_compositor *compositor = wlr_compositor_create(server.wl_display, 6, server.renderer);
@@ -684,11 +697,14 @@ end:
 
 	wl_event_source_remove(sigint_source);
 	wl_event_source_remove(sigterm_source);
+	wl_event_source_remove(sigchld_cog_source);
 	if (sigchld_source) {
 		wl_event_source_remove(sigchld_source);
 	}
 	splash_destroy(server.splash);
 	free(server.splash_image_path);
+	free(server.splash_color);
+	free(server.title_override);
 	free(server.input_map_path);
 	free(server.display_map_path);
 	if (server.output_event_fd >= 0) {
@@ -17,6 +17,7 @@
 #include <stdlib.h>
 #include <string.h>
 #include <sys/wait.h>
+#include <time.h>
 #include <unistd.h>
 #include <wayland-server-core.h>
 #include <wlr/backend.h>
@@ -341,6 +342,8 @@ output_state_apply_configured_transform(struct cg_output *output, struct wlr_out
 
 #define COG_NOT_CONFIGURED_URL "file:///strux/.not-configured.html"
 #define STRUX_RUN_COG_SCRIPT "/strux/strux-run-cog.sh"
+#define DEFAULT_WEB_EXTENSIONS_DIR "/usr/lib/wpe-web-extensions"
+#define STRUX_COG_EXIT_FILE "/tmp/strux-cog-exit"
 
 /* Spawn a Cog browser instance for the given output by calling
  * the user-modifiable strux-run-cog.sh script.
@@ -377,7 +380,9 @@ spawn_cog_for_output(struct cg_server *server, struct cg_output *output)
 				execlp("wlr-randr", "wlr-randr",
 				       "--output", output->wlr_output->name,
 				       "--mode", resolution, NULL);
-				_exit(0);
+				wlr_log_errno(WLR_ERROR, "Failed to exec wlr-randr, output %s keeps its default mode",
+					      output->wlr_output->name);
+				_exit(127);
 			} else if (randr_pid > 0) {
 				int status;
 				waitpid(randr_pid, &status, 0);
@@ -394,8 +399,14 @@ spawn_cog_for_output(struct cg_server *server, struct cg_output *output)
 		/* Fallback if script doesn't exist */
 		wlr_log_errno(WLR_ERROR, "Failed to exec %s, falling back to direct cog launch",
 			      STRUX_RUN_COG_SCRIPT);
+		const char *ext_dir = getenv("WPE_WEB_EXTENSION_PATH");
+		if (!ext_dir || !*ext_dir) {
+			ext_dir = DEFAULT_WEB_EXTENSIONS_DIR;
+		}
+		char ext_arg[512];
+		snprintf(ext_arg, sizeof(ext_arg), "--web-extensions-dir=%s", ext_dir);
 		execlp("cog", "cog",
-		       "--web-extensions-dir=/usr/lib/wpe-web-extensions",
+		       ext_arg,
 		       "--platform=wl",
 		       "--enable-developer-extras=1",
 		       cog_url, NULL);
@@ -428,6 +439,79 @@ kill_cog_for_output(struct cg_output *output)
 	}
 }
 
+/* Record an unexpected Cog exit for strux.system.LastCogExit(). The file is
+ * written next to the final path and renamed so readers never see half of it. */
+static void
+record_cog_exit(struct cg_output *output, pid_t pid, int status)
+{
+	int exit_code = 0;
+	int sig = 0;
+	if (WIFEXITED(status)) {
+		exit_code = WEXITSTATUS(status);
+	} else if (WIFSIGNALED(status)) {
+		/* Mimic Bash and other shells for the exit status */
+		sig = WTERMSIG(status);
+		exit_code = 128 + sig;
+	}
+
+	if (sig) {
+		wlr_log(WLR_ERROR, "Cog for output %s (PID %d) exited unexpectedly, killed by signal %d",
+			output->wlr_output->name, pid, sig);
+	} else {
+		wlr_log(WLR_ERROR, "Cog for output %s (PID %d) exited unexpectedly with status %d",
+			output->wlr_output->name, pid, exit_code);
+	}
+
+	FILE *file = fopen(STRUX_COG_EXIT_FILE ".tmp", "w");
+	if (!file) {
+		wlr_log_errno(WLR_ERROR, "Failed to record Cog exit");
+		return;
+	}
+	fprintf(file, "output=%s\npid=%d\nexit_code=%d\nsignal=%d\ntime=%lld\n",
+		output->wlr_output->name, pid, exit_code, sig, (long long)time(NULL));
+	fclose(file);
+	rename(STRUX_COG_EXIT_FILE ".tmp", STRUX_COG_EXIT_FILE);
+}
+
+/* Reap Cog instances that exited on their own. Cog processes stopped by
+ * Cage are reaped where they are killed, so only unexpected exits (crashes,
+ * WebKit aborts) are seen here. */
+void
+output_reap_cogs(struct cg_server *server)
+{
+	struct cg_output *output;
+	wl_list_for_each(output, &server->outputs, link) {
+		if (output->cog_pid <= 0) {
+			continue;
+		}
+		int status;
+		pid_t pid = waitpid(output->cog_pid, &status, WNOHANG);
+		if (pid == output->cog_pid) {
+			output->cog_pid = 0;
+			record_cog_exit(output, pid, status);
+		}
+	}
+}
+
+/* Restart the Cog instance for an output so it loads the URL currently in the
+ * display map. Used by the NAVIGATE control command. Returns false when Cage
+ * does not manage Cog itself (no per-view mode or no display map). */
+bool
+output_reload_cog(struct cg_output *output)
+{
+	struct cg_server *server = output->server;
+
+	if (server->only_display_image ||
+	    server->output_mode != CAGE_MULTI_OUTPUT_MODE_PER_VIEW ||
+	    !server->display_map_path) {
+		return false;
+	}
+
+	kill_cog_for_output(output);
+	output->cog_pid = spawn_cog_for_output(server, output);
+	return true;
+}
+
 static void
 output_destroy(struct cg_output *output)
 {
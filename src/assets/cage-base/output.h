@@ -1,6 +1,7 @@
 #ifndef CG_OUTPUT_H
 #define CG_OUTPUT_H
 
+#include <stdbool.h>
 #include <sys/types.h>
 #include <wayland-server-core.h>
 #include <wlr/types/wlr_output.h>
@@ -29,5 +30,7 @@ void handle_output_manager_test(struct wl_listener *listener, void *data);
 void handle_output_layout_change(struct wl_listener *listener, void *data);
 void handle_new_output(struct wl_listener *listener, void *data);
 void output_set_window_title(struct cg_output *output, const char *title);
+bool output_reload_cog(struct cg_output *output);
+void output_reap_cogs(struct cg_server *server);
 
 #endif
@@ -1094,7 +1094,7 @@ seat_set_focus(struct cg_seat *seat, struct cg_view *view)
 	}
 
 	view_activate(view, true);
-	char *title = view_get_title(view);
+	char *title = server->title_override ? strdup(server->title_override) : view_get_title(view);
 	struct cg_output *output;
 	wl_list_for_each (output, &server->outputs, link) {
 		output_set_window_title(output, title);
@@ -73,8 +73,13 @@ struct cg_server {
 	// Strux splash screen
 	struct cg_splash *splash;
 	char *splash_image_path;
+	char *splash_color;
 	bool only_display_image;
 
+	// Window title set over the control socket (SET_TITLE); replaces the
+	// focused view's own title when non-NULL
+	char *title_override;
+
 	// Input device to output mapping file path
 	char *input_map_path;
 
@@ -3,8 +3,9 @@
  *
  * Provides splash screen with:
  * - Framebuffer rendering during early boot
- * - Wayland scene rendering (black background + centered image)
- * - Control socket for strux.boot.HideSplash()
+ * - Wayland scene rendering (solid background + centered image)
+ * - Control socket for strux.boot.HideSplash(), app title updates and
+ *   reloading Cog after the display map changes
  */
 
 #define _POSIX_C_SOURCE 200809L
@@ -20,7 +21,9 @@
 #include <errno.h>
 #include <fcntl.h>
 #include <png.h>
+#include <setjmp.h>
 #include <stdio.h>
+#include <strings.h>
 #include <stdlib.h>
 #include <string.h>
 #include <sys/mman.h>
@@ -30,6 +33,7 @@
 #include <unistd.h>
 
 #include <drm_fourcc.h>
+#include <jpeglib.h>
 #include <wlr/interfaces/wlr_buffer.h>
 #include <wlr/types/wlr_buffer.h>
 #include <wlr/types/wlr_scene.h>
@@ -118,15 +122,15 @@ static struct data_buffer *data_buffer_create(int width, int height, uint32_t fo
 	return buffer;
 }
 
-/* ===== PNG Loading ===== */
+/* ===== Image Loading ===== */
 
-struct png_image {
+struct splash_image {
 	int width;
 	int height;
 	uint8_t *data; // RGBA format
 };
 
-static struct png_image *load_png(const char *path)
+static struct splash_image *load_png(const char *path)
 {
 	FILE *fp = fopen(path, "rb");
 	if (!fp) {
@@ -184,7 +188,7 @@ static struct png_image *load_png(const char *path)
 
 	png_read_update_info(png, info);
 
-	struct png_image *img = calloc(1, sizeof(struct png_image));
+	struct splash_image *img = calloc(1, sizeof(struct splash_image));
 	if (!img) {
 		png_destroy_read_struct(&png, &info, NULL);
 		fclose(fp);
@@ -216,7 +220,7 @@ static struct png_image *load_png(const char *path)
 	return img;
 }
 
-static void free_png_image(struct png_image *img)
+static void free_splash_image(struct splash_image *img)
 {
 	if (img) {
 		free(img->data);
@@ -224,6 +228,111 @@ static void free_png_image(struct png_image *img)
 	}
 }
 
+// libjpeg calls error_exit on fatal errors and expects it not to return
+struct jpeg_error_context {
+	struct jpeg_error_mgr base;
+	jmp_buf jump;
+};
+
+static void jpeg_error_exit(j_common_ptr cinfo)
+{
+	struct jpeg_error_context *ctx = (struct jpeg_error_context *)cinfo->err;
+	char message[JMSG_LENGTH_MAX];
+	(*cinfo->err->format_message)(cinfo, message);
+	wlr_log(WLR_ERROR, "Failed to decode JPEG splash image: %s", message);
+	longjmp(ctx->jump, 1);
+}
+
+static struct splash_image *load_jpeg(const char *path)
+{
+	FILE *fp = fopen(path, "rb");
+	if (!fp) {
+		wlr_log(WLR_ERROR, "Failed to open splash image: %s", path);
+		return NULL;
+	}
+
+	struct jpeg_decompress_struct cinfo;
+	struct jpeg_error_context jerr;
+	struct splash_image *volatile img = NULL;
+	uint8_t *volatile row = NULL;
+
+	cinfo.err = jpeg_std_error(&jerr.base);
+	jerr.base.error_exit = jpeg_error_exit;
+	if (setjmp(jerr.jump)) {
+		jpeg_destroy_decompress(&cinfo);
+		fclose(fp);
+		free(row);
+		free_splash_image(img);
+		return NULL;
+	}
+
+	jpeg_create_decompress(&cinfo);
+	jpeg_stdio_src(&cinfo, fp);
+	jpeg_read_header(&cinfo, TRUE);
+	cinfo.out_color_space = JCS_RGB;
+	jpeg_start_decompress(&cinfo);
+
+	int width = cinfo.output_width;
+	int height = cinfo.output_height;
+
+	img = calloc(1, sizeof(struct splash_image));
+	row = malloc(width * 3);
+	if (!img || !row) {
+		longjmp(jerr.jump, 1);
+	}
+	img->width = width;
+	img->height = height;
+	img->data = malloc(width * height * 4);
+	if (!img->data) {
+		longjmp(jerr.jump, 1);
+	}
+
+	// Expand RGB rows to RGBA
+	while (cinfo.output_scanline < cinfo.output_height) {
+		int y = cinfo.output_scanline;
+		JSAMPROW rows[1] = {row};
+		jpeg_read_scanlines(&cinfo, rows, 1);
+		uint8_t *dst = img->data + y * width * 4;
+		for (int x = 0; x < width; x++) {
+			dst[x * 4 + 0] = row[x * 3 + 0];
+			dst[x * 4 + 1] = row[x * 3 + 1];
+			dst[x * 4 + 2] = row[x * 3 + 2];
+			dst[x * 4 + 3] = 0xFF;
+		}
+	}
+
+	jpeg_finish_decompress(&cinfo);
+	jpeg_destroy_decompress(&cinfo);
+	fclose(fp);
+	free(row);
+
+	wlr_log(WLR_INFO, "Loaded splash image: %dx%d", width, height);
+	return img;
+}
+
+// Load a PNG or JPEG splash image, picking the decoder by file extension
+static struct splash_image *load_splash_image(const char *path)
+{
+	const char *ext = strrchr(path, '.');
+	if (ext && (strcasecmp(ext, ".jpg") == 0 || strcasecmp(ext, ".jpeg") == 0)) {
+		return load_jpeg(path);
+	}
+	return load_png(path);
+}
+
+// Parse a 6-digit hex color (an optional leading '#' is allowed) into 0xRRGGBB
+static bool parse_hex_color(const char *value, uint32_t *rgb)
+{
+	if (*value == '#') {
+		value++;
+	}
+	if (strlen(value) != 6 || strspn(value, "0123456789abcdefABCDEF") != 6) {
+		return false;
+	}
+	*rgb = (uint32_t)strtoul(value, NULL, 16);
+	return true;
+}
+
 /* ===== Framebuffer Operations ===== */
 
 static bool get_fb_resolution(int *width, int *height)
@@ -247,23 +356,21 @@ static bool get_fb_resolution(int *width, int *height)
 	return true;
 }
 
-static bool show_framebuffer_splash(const char *image_path)
+static bool show_framebuffer_splash(const char *image_path, uint32_t background)
 {
-	struct png_image *img = load_png(image_path);
-	if (!img) {
-		return false;
-	}
+	// An image that fails to load still leaves the background color
+	struct splash_image *img = image_path ? load_splash_image(image_path) : NULL;
 
 	int fb_width, fb_height;
 	if (!get_fb_resolution(&fb_width, &fb_height)) {
-		free_png_image(img);
+		free_splash_image(img);
 		return false;
 	}
 
 	int fb_fd = open(FB_DEVICE, O_RDWR);
 	if (fb_fd < 0) {
 		wlr_log(WLR_ERROR, "Failed to open framebuffer: %s", FB_DEVICE);
-		free_png_image(img);
+		free_splash_image(img);
 		return false;
 	}
 
@@ -272,12 +379,22 @@ static bool show_framebuffer_splash(const char *image_path)
 	if (fb_mem == MAP_FAILED) {
 		wlr_log(WLR_ERROR, "Failed to mmap framebuffer");
 		close(fb_fd);
-		free_png_image(img);
+		free_splash_image(img);
 		return false;
 	}
 
-	// Clear to black
-	memset(fb_mem, 0, fb_size);
+	// Fill with the background color (the framebuffer is BGRA)
+	uint32_t *fb_pixels = (uint32_t *)fb_mem;
+	for (size_t i = 0; i < fb_size / 4; i++) {
+		fb_pixels[i] = 0xFF000000 | background;
+	}
+
+	if (!img) {
+		munmap(fb_mem, fb_size);
+		close(fb_fd);
+		wlr_log(WLR_INFO, "Framebuffer splash displayed (background color only)");
+		return true;
+	}
 
 	enum splash_transform transform = SPLASH_TRANSFORM_NORMAL;
 	const char *transform_value = getenv("STRUX_OUTPUT_TRANSFORM");
@@ -344,7 +461,7 @@ static bool show_framebuffer_splash(const char *image_path)
 
 	munmap(fb_mem, fb_size);
 	close(fb_fd);
-	free_png_image(img);
+	free_splash_image(img);
 
 	wlr_log(WLR_INFO, "Framebuffer splash displayed");
 	return true;
@@ -380,6 +497,38 @@ struct client_context {
 	int fd;
 };
 
+// Replace the window title of every output with the app's title. The title
+// also overrides the one set by focused views from now on.
+static void set_window_title(struct cg_server *server, const char *title)
+{
+	wlr_log(WLR_INFO, "Received SET_TITLE command: %s", title);
+
+	free(server->title_override);
+	server->title_override = strdup(title);
+
+	struct cg_output *output;
+	wl_list_for_each (output, &server->outputs, link) {
+		output_set_window_title(output, title);
+	}
+}
+
+// Restart Cog on every output so each one loads the URL now in the display
+// map. The client rewrites the map first, e.g. when the dev server moves.
+// Returns false if Cage does not manage the Cog instances to restart.
+static bool navigate_outputs(struct cg_server *server)
+{
+	wlr_log(WLR_INFO, "Received NAVIGATE command");
+
+	bool reloaded = true;
+	struct cg_output *output;
+	wl_list_for_each (output, &server->outputs, link) {
+		if (!output_reload_cog(output)) {
+			reloaded = false;
+		}
+	}
+	return reloaded;
+}
+
 static int handle_control_message(int fd, uint32_t mask, void *data)
 {
 	struct client_context *ctx = data;
@@ -396,10 +545,23 @@ static int handle_control_message(int fd, uint32_t mask, void *data)
 
 	buffer[n] = '\0';
 
+	// Acknowledge each command so the client knows it has taken effect
+	const char *reply = "ERR unknown command\n";
 	if (strcmp(buffer, "HIDE_SPLASH") == 0) {
 		wlr_log(WLR_INFO, "Received HIDE_SPLASH command");
 		splash_hide(ctx->splash);
+		reply = "OK\n";
+	} else if (strncmp(buffer, "SET_TITLE ", 10) == 0) {
+		set_window_title(ctx->splash->server, buffer + 10);
+		reply = "OK\n";
+	} else if (strcmp(buffer, "NAVIGATE") == 0) {
+		if (navigate_outputs(ctx->splash->server)) {
+			reply = "OK\n";
+		} else {
+			reply = "ERR Cog is not managed by Cage (needs per-view mode and a display map)\n";
+		}
 	}
+	send(fd, reply, strlen(reply), MSG_NOSIGNAL);
 
 	// Remove event source and cleanup after handling message
 	wl_event_source_remove(ctx->source);
@@ -473,7 +635,7 @@ static bool setup_control_socket(struct cg_splash *splash)
 
 /* ===== Wayland Scene Splash ===== */
 
-struct cg_splash *splash_create(struct cg_server *server, const char *image_path)
+struct cg_splash *splash_create(struct cg_server *server, const char *image_path, const char *color)
 {
 	struct cg_splash *splash = calloc(1, sizeof(struct cg_splash));
 	if (!splash) {
@@ -484,18 +646,28 @@ struct cg_splash *splash_create(struct cg_server *server, const char *image_path
 	splash->control_fd = -1;
 	splash->visible = false;
 
-	if (image_path) {
-		splash->image_path = strdup(image_path);
+	if (color) {
+		if (parse_hex_color(color, &splash->background_rgb)) {
+			splash->has_background = true;
+		} else {
+			wlr_log(WLR_ERROR, "Ignoring invalid splash color '%s', expected RRGGBB", color);
+		}
+	}
 
+	if (image_path || splash->has_background) {
 		// Show framebuffer splash immediately
-		show_framebuffer_splash(image_path);
+		show_framebuffer_splash(image_path, splash->background_rgb);
+	}
+
+	if (image_path) {
+		splash->image_path = strdup(image_path);
 
 		// Pre-load image dimensions
-		struct png_image *img = load_png(image_path);
+		struct splash_image *img = load_splash_image(image_path);
 		if (img) {
 			splash->image_width = img->width;
 			splash->image_height = img->height;
-			free_png_image(img);
+			free_splash_image(img);
 		}
 	}
 
@@ -506,44 +678,11 @@ struct cg_splash *splash_create(struct cg_server *server, const char *image_path
 	return splash;
 }
 
-void splash_show_wayland(struct cg_splash *splash)
+// Load the splash image and center it over the background. On failure the
+// splash falls back to the background alone.
+static void add_splash_image(struct cg_splash *splash, int screen_width, int screen_height)
 {
-	if (!splash || !splash->image_path || splash->visible) {
-		return;
-	}
-
-	// Clear framebuffer now that Wayland is taking over
-	clear_framebuffer();
-
-	// Create scene tree for splash (will be raised to top)
-	splash->tree = wlr_scene_tree_create(&splash->server->scene->tree);
-	if (!splash->tree) {
-		wlr_log(WLR_ERROR, "Failed to create splash scene tree");
-		return;
-	}
-
-	// Get screen dimensions from first enabled output
-	int screen_width = 1280;
-	int screen_height = 800;
-
-	struct cg_output *output;
-	wl_list_for_each(output, &splash->server->outputs, link) {
-		if (output->wlr_output->enabled) {
-			get_output_logical_size(output->wlr_output, &screen_width, &screen_height);
-			break;
-		}
-	}
-
-	// Create black background covering entire screen
-	float black[4] = {0.0f, 0.0f, 0.0f, 1.0f};
-	splash->background = wlr_scene_rect_create(splash->tree, screen_width, screen_height, black);
-	if (!splash->background) {
-		wlr_log(WLR_ERROR, "Failed to create splash background");
-		return;
-	}
-
-	// Load and create image buffer
-	struct png_image *img = load_png(splash->image_path);
+	struct splash_image *img = load_splash_image(splash->image_path);
 	if (!img) {
 		wlr_log(WLR_ERROR, "Failed to load splash image for Wayland");
 		return;
@@ -553,7 +692,7 @@ void splash_show_wayland(struct cg_splash *splash)
 	struct data_buffer *buffer = data_buffer_create(img->width, img->height, DRM_FORMAT_ARGB8888);
 	if (!buffer) {
 		wlr_log(WLR_ERROR, "Failed to allocate splash buffer");
-		free_png_image(img);
+		free_splash_image(img);
 		return;
 	}
 
@@ -576,13 +715,59 @@ void splash_show_wayland(struct cg_splash *splash)
 
 	if (!splash->image) {
 		wlr_log(WLR_ERROR, "Failed to create splash scene buffer");
-		free_png_image(img);
+		free_splash_image(img);
 		return;
 	}
 
 	center_splash_image(splash, screen_width, screen_height);
 
-	free_png_image(img);
+	free_splash_image(img);
+}
+
+void splash_show_wayland(struct cg_splash *splash)
+{
+	if (!splash || (!splash->image_path && !splash->has_background) || splash->visible) {
+		return;
+	}
+
+	// Clear framebuffer now that Wayland is taking over
+	clear_framebuffer();
+
+	// Create scene tree for splash (will be raised to top)
+	splash->tree = wlr_scene_tree_create(&splash->server->scene->tree);
+	if (!splash->tree) {
+		wlr_log(WLR_ERROR, "Failed to create splash scene tree");
+		return;
+	}
+
+	// Get screen dimensions from first enabled output
+	int screen_width = 1280;
+	int screen_height = 800;
+
+	struct cg_output *output;
+	wl_list_for_each(output, &splash->server->outputs, link) {
+		if (output->wlr_output->enabled) {
+			get_output_logical_size(output->wlr_output, &screen_width, &screen_height);
+			break;
+		}
+	}
+
+	// Create background covering entire screen (black unless a color is set)
+	float background[4] = {
+		((splash->background_rgb >> 16) & 0xFF) / 255.0f,
+		((splash->background_rgb >> 8) & 0xFF) / 255.0f,
+		(splash->background_rgb & 0xFF) / 255.0f,
+		1.0f,
+	};
+	splash->background = wlr_scene_rect_create(splash->tree, screen_width, screen_height, background);
+	if (!splash->background) {
+		wlr_log(WLR_ERROR, "Failed to create splash background");
+		return;
+	}
+
+	if (splash->image_path) {
+		add_splash_image(splash, screen_width, screen_height);
+	}
 
 	// Raise splash tree to top
 	wlr_scene_node_raise_to_top(&splash->tree->node);
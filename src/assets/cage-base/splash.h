@@ -2,6 +2,7 @@
 #define CG_SPLASH_H
 
 #include <stdbool.h>
+#include <stdint.h>
 #include <wayland-server-core.h>
 
 struct cg_server;
@@ -17,20 +18,28 @@ struct cg_splash {
 	bool visible;
 	char *image_path;
 
-	// Image dimensions (loaded from PNG)
+	// Image dimensions (loaded from PNG or JPEG)
 	int image_width;
 	int image_height;
 
-	// Control socket for strux.boot.HideSplash()
+	// Background color as 0xRRGGBB, black unless --splash-color is given. A
+	// background color alone is enough to show a splash.
+	uint32_t background_rgb;
+	bool has_background;
+
+	// Control socket for strux.boot.HideSplash(), SET_TITLE and NAVIGATE;
+	// set up even when no splash is shown
 	int control_fd;
 	struct wl_event_source *control_source;
 };
 
 /**
- * Create the splash screen system.
+ * Create the splash screen system and the control socket.
  * Shows framebuffer splash immediately, sets up Wayland splash for later.
+ * image_path and color (RRGGBB) are both optional; without either no splash
+ * is shown, but the control socket is still created.
  */
-struct cg_splash *splash_create(struct cg_server *server, const char *image_path);
+struct cg_splash *splash_create(struct cg_server *server, const char *image_path, const char *color);
 
 /**
  * Transition from framebuffer to Wayland splash.
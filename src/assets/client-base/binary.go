@@ -1,43 +1,190 @@
 //
 // Strux Client - Binary Handler
 //
-// Handles binary updates for the main application.
+// Handles binary updates for the main application, and for the Strux client
+// itself (the Cage launcher and dev server connection) so the management layer
+// can be updated independently of the app.
 // When a new binary is received from the dev server, it:
 // 1. Calculates checksum to verify integrity
 // 2. Compares with current binary to avoid unnecessary updates
-// 3. Writes the new binary to /strux/main
-// 4. Reboots the system to apply changes
+// 3. Refuses to install a binary older than the current one unless forced
+// 4. Writes the new binary to /strux/main (or /strux/client)
+// 5. Reboots the system to apply changes after a short grace period, during
+//    which the reboot can still be cancelled by the server
 //
 
 package main
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"debug/buildinfo"
 	"encoding/hex"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const binaryPath = "/strux/main"
 const binaryTempPath = "/strux/main.new"
 
+// clientBinaryPath is the Strux client binary, started by strux.sh at boot
+const clientBinaryPath = "/strux/client"
+const clientBinaryTempPath = "/strux/client.new"
+
+// buildTimestampSymbol is the ldflags -X target used by strux-build-app.sh to
+// stamp the build time into the application binary
+const buildTimestampSymbol = "main.BuildTimestamp"
+
+// defaultRebootDelay is how long to wait after a binary update before
+// rebooting, giving the frontend time to show an "updating" message
+const defaultRebootDelay = 2 * time.Second
+
 // BinaryUpdateResult contains the result of a binary update operation
 type BinaryUpdateResult struct {
-	Status           string // "skipped", "updated", "error"
-	Message          string // Human-readable message
-	CurrentChecksum  string // Checksum of current binary on disk (before update)
-	ReceivedChecksum string // Checksum of received binary
+	Status           string        // "skipped", "updated", "busy", "error"
+	Message          string        // Human-readable message
+	CurrentChecksum  string        // Checksum of current binary on disk (before update)
+	ReceivedChecksum string        // Checksum of received binary
+	Reason           string        // Machine-readable skip reason, e.g. "DOWNGRADE"
+	WriteDuration    time.Duration // Writing the temp file and renaming it into place
+	VerifyDuration   time.Duration // Reading back the temp file and comparing checksums
+	RebootScheduled  bool          // A reboot was scheduled after the update
 }
 
 // BinaryHandler handles binary updates
 type BinaryHandler struct {
-	logger *Logger
+	logger   *Logger
+	path     string       // Installed binary path
+	tempPath string       // Temp file the new binary is written to before rename
+	basePath string       // Binary in a read-only /strux, current until an update is installed at path
+	reboot   func() error // Reboots the system once an update is installed
+
+	updateMu sync.Mutex // Held while an update is being installed
+
+	mu           sync.Mutex
+	rebootDelay  time.Duration
+	cancelReboot chan struct{} // Non-nil while a reboot is pending
 }
 
 // BinaryHandlerInstance is the global binary handler
-var BinaryHandlerInstance = &BinaryHandler{
-	logger: NewLogger("BinaryHandler"),
+var BinaryHandlerInstance = NewBinaryHandler()
+
+// ClientBinaryHandlerInstance updates the Strux client binary
+var ClientBinaryHandlerInstance = NewClientBinaryHandler()
+
+// NewBinaryHandler creates a binary handler for the installed app binary
+func NewBinaryHandler() *BinaryHandler {
+	b := &BinaryHandler{
+		logger:      NewLogger("BinaryHandler"),
+		path:        binaryPath,
+		tempPath:    binaryTempPath,
+		rebootDelay: defaultRebootDelay,
+	}
+	b.reboot = b.Reboot
+	return b
+}
+
+// NewClientBinaryHandler creates a binary handler for the Strux client binary.
+// The running client keeps its old executable until the reboot that follows
+// the update, like the app.
+func NewClientBinaryHandler() *BinaryHandler {
+	b := NewBinaryHandler()
+	b.logger = NewLogger("ClientBinaryHandler")
+	b.path = clientBinaryPath
+	b.tempPath = clientBinaryTempPath
+	return b
+}
+
+// Path returns the installed binary path this handler updates
+func (b *BinaryHandler) Path() string {
+	return b.path
+}
+
+// UseWritableDir installs updates into dir instead of next to the binary, for
+// images where /strux is read-only. The original binary is compared against
+// until an update has been installed there.
+func (b *BinaryHandler) UseWritableDir(dir string) {
+	if b.basePath == "" {
+		b.basePath = b.path
+	}
+	b.path = filepath.Join(dir, filepath.Base(b.basePath))
+	b.tempPath = filepath.Join(dir, filepath.Base(b.tempPath))
+}
+
+// currentPath returns the binary the device runs: the installed binary, or
+// the original in a read-only /strux if no update has been installed yet
+func (b *BinaryHandler) currentPath() string {
+	if b.basePath != "" && !fileExists(b.path) {
+		return b.basePath
+	}
+	return b.path
+}
+
+// SetRebootDelay sets the grace period between a binary update and the reboot
+func (b *BinaryHandler) SetRebootDelay(delay time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rebootDelay = delay
+}
+
+// CancelPendingReboot cancels a reboot scheduled by a binary update.
+// Returns false if no reboot was pending.
+func (b *BinaryHandler) CancelPendingReboot() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cancelReboot == nil {
+		return false
+	}
+
+	close(b.cancelReboot)
+	b.cancelReboot = nil
+	b.logger.Info("Pending reboot cancelled")
+	return true
+}
+
+// scheduleReboot reboots the system after the configured grace period unless
+// CancelPendingReboot is called first
+func (b *BinaryHandler) scheduleReboot() {
+	b.mu.Lock()
+	if b.cancelReboot != nil {
+		// A reboot is already pending, it will pick up the new binary
+		b.mu.Unlock()
+		return
+	}
+	cancel := make(chan struct{})
+	b.cancelReboot = cancel
+	delay := b.rebootDelay
+	b.mu.Unlock()
+
+	b.logger.Info("Rebooting in %s...", delay)
+
+	go func() {
+		select {
+		case <-time.After(delay):
+		case <-cancel:
+			return
+		}
+
+		b.mu.Lock()
+		if b.cancelReboot != cancel {
+			// Cancelled while the timer fired
+			b.mu.Unlock()
+			return
+		}
+		b.cancelReboot = nil
+		b.mu.Unlock()
+
+		if err := b.reboot(); err != nil {
+			b.logger.Error("Reboot failed: %v", err)
+		}
+	}()
 }
 
 // CalculateChecksum calculates the SHA-256 checksum of data
@@ -48,12 +195,13 @@ func (b *BinaryHandler) CalculateChecksum(data []byte) string {
 
 // GetCurrentChecksum returns the checksum of the current binary
 func (b *BinaryHandler) GetCurrentChecksum() (string, error) {
-	if !fileExists(binaryPath) {
-		b.logger.Info("No existing binary at %s", binaryPath)
+	path := b.currentPath()
+	if !fileExists(path) {
+		b.logger.Info("No existing binary at %s", path)
 		return "", nil
 	}
 
-	data, err := os.ReadFile(binaryPath)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to read binary: %w", err)
 	}
@@ -61,8 +209,64 @@ func (b *BinaryHandler) GetCurrentChecksum() (string, error) {
 	return b.CalculateChecksum(data), nil
 }
 
-// HandleUpdate handles a binary update and returns a result struct
-func (b *BinaryHandler) HandleUpdate(data []byte) BinaryUpdateResult {
+// ReadBuildTimestamp extracts the build timestamp stamped into a Go binary via
+// ldflags. Returns 0 if the binary carries no build info or no timestamp.
+func (b *BinaryHandler) ReadBuildTimestamp(data []byte) int64 {
+	info, err := buildinfo.Read(bytes.NewReader(data))
+	if err != nil {
+		return 0
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key != "-ldflags" {
+			continue
+		}
+
+		fields := strings.Fields(setting.Value)
+		for i, field := range fields {
+			// Accept both "-X main.BuildTimestamp=N" and "-X=main.BuildTimestamp=N"
+			value := strings.TrimPrefix(field, "-X=")
+			if field == "-X" && i+1 < len(fields) {
+				value = fields[i+1]
+			}
+
+			if ts, ok := strings.CutPrefix(value, buildTimestampSymbol+"="); ok {
+				parsed, err := strconv.ParseInt(strings.Trim(ts, `"'`), 10, 64)
+				if err == nil {
+					return parsed
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+// GetCurrentBuildTimestamp returns the build timestamp of the current binary
+func (b *BinaryHandler) GetCurrentBuildTimestamp() int64 {
+	data, err := os.ReadFile(b.currentPath())
+	if err != nil {
+		return 0
+	}
+
+	return b.ReadBuildTimestamp(data)
+}
+
+// HandleUpdate handles a binary update and returns a result struct.
+// buildTimestamp is the build time reported by the server (0 to read it from
+// the binary itself). Unless force is set, binaries older than the one on disk
+// are rejected with reason "DOWNGRADE".
+func (b *BinaryHandler) HandleUpdate(data []byte, buildTimestamp int64, force bool) BinaryUpdateResult {
+	// Only one update may write the temp file and rename it at a time
+	if !b.updateMu.TryLock() {
+		b.logger.Warn("Binary update already in progress, rejecting new update")
+		return BinaryUpdateResult{
+			Status:  "busy",
+			Message: "Another binary update is in progress",
+		}
+	}
+	defer b.updateMu.Unlock()
+
 	b.logger.Info("Received binary update (%d bytes)", len(data))
 
 	// Calculate checksum of received binary
@@ -87,17 +291,33 @@ func (b *BinaryHandler) HandleUpdate(data []byte) BinaryUpdateResult {
 		return result
 	}
 
+	// Reject downgrades unless explicitly forced. If either side carries no
+	// build timestamp there is nothing to compare, so the update proceeds.
+	if buildTimestamp == 0 {
+		buildTimestamp = b.ReadBuildTimestamp(data)
+	}
+	if currentTimestamp := b.GetCurrentBuildTimestamp(); !force && buildTimestamp > 0 && currentTimestamp > 0 && buildTimestamp < currentTimestamp {
+		b.logger.Warn("Received binary (built %d) is older than current binary (built %d), skipping update", buildTimestamp, currentTimestamp)
+		result.Status = "skipped"
+		result.Reason = "DOWNGRADE"
+		result.Message = fmt.Sprintf("Binary built at %d is older than current binary built at %d", buildTimestamp, currentTimestamp)
+		return result
+	}
+
 	// Write the new binary to a temporary file first
 	// This avoids "text file busy" error when the binary is currently running
-	b.logger.Info("Writing binary to %s...", binaryTempPath)
-	if err := os.WriteFile(binaryTempPath, data, 0755); err != nil {
+	b.logger.Info("Writing binary to %s...", b.tempPath)
+	writeStart := time.Now()
+	if err := os.WriteFile(b.tempPath, data, 0755); err != nil {
 		result.Status = "error"
 		result.Message = fmt.Sprintf("Failed to write binary: %v", err)
 		return result
 	}
+	result.WriteDuration = time.Since(writeStart)
 
 	// Verify the written temp file
-	tempData, err := os.ReadFile(binaryTempPath)
+	verifyStart := time.Now()
+	tempData, err := os.ReadFile(b.tempPath)
 	if err != nil {
 		result.Status = "error"
 		result.Message = fmt.Sprintf("Failed to read temp binary for verification: %v", err)
@@ -106,30 +326,30 @@ func (b *BinaryHandler) HandleUpdate(data []byte) BinaryUpdateResult {
 
 	writtenChecksum := b.CalculateChecksum(tempData)
 	if writtenChecksum != receivedChecksum {
-		os.Remove(binaryTempPath) // Clean up temp file
+		os.Remove(b.tempPath) // Clean up temp file
 		result.Status = "error"
 		result.Message = fmt.Sprintf("Checksum mismatch: expected %s, got %s", receivedChecksum, writtenChecksum)
 		return result
 	}
+	result.VerifyDuration = time.Since(verifyStart)
 
 	// Rename temp file to actual binary path (atomic operation, works even if target is running)
-	b.logger.Info("Replacing binary at %s...", binaryPath)
-	if err := os.Rename(binaryTempPath, binaryPath); err != nil {
+	b.logger.Info("Replacing binary at %s...", b.path)
+	renameStart := time.Now()
+	if err := os.Rename(b.tempPath, b.path); err != nil {
 		result.Status = "error"
 		result.Message = fmt.Sprintf("Failed to rename binary: %v", err)
 		return result
 	}
+	result.WriteDuration += time.Since(renameStart)
 
 	result.Status = "updated"
 	b.logger.Info("Binary updated successfully, rebooting system...")
 	result.Message = "Binary updated, rebooting..."
 
-	// Reboot the system (async, so we can still return)
-	go func() {
-		if err := b.Reboot(); err != nil {
-			b.logger.Error("Reboot failed: %v", err)
-		}
-	}()
+	// Reboot the system after the grace period (async, so the ack is sent first)
+	b.scheduleReboot()
+	result.RebootScheduled = true
 
 	return result
 }
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestBinaryHandler(t *testing.T) *BinaryHandler {
+	t.Helper()
+	dir := t.TempDir()
+
+	b := NewBinaryHandler()
+	b.path = filepath.Join(dir, "main")
+	b.tempPath = filepath.Join(dir, "main.new")
+	b.rebootDelay = 0
+	b.reboot = func() error { return nil }
+	return b
+}
+
+func TestHandleUpdateRejectsWhileInProgress(t *testing.T) {
+	b := newTestBinaryHandler(t)
+
+	// Simulate an update that is still being installed
+	b.updateMu.Lock()
+	result := b.HandleUpdate([]byte("second"), 0, false)
+	b.updateMu.Unlock()
+
+	if result.Status != "busy" {
+		t.Fatalf("expected busy status, got %q (%s)", result.Status, result.Message)
+	}
+	if fileExists(b.tempPath) || fileExists(b.path) {
+		t.Fatalf("busy update must not touch the binary files")
+	}
+
+	result = b.HandleUpdate([]byte("third"), 0, false)
+	if result.Status != "updated" {
+		t.Fatalf("expected updated status after previous update finished, got %q (%s)", result.Status, result.Message)
+	}
+}
+
+func TestHandleUpdateConcurrent(t *testing.T) {
+	b := newTestBinaryHandler(t)
+
+	const updates = 16
+	payloads := make([][]byte, updates)
+	for i := range payloads {
+		payloads[i] = []byte(fmt.Sprintf("binary-%d", i))
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	results := make([]BinaryUpdateResult, updates)
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i] = b.HandleUpdate(payloads[i], 0, false)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	installed := 0
+	for i, result := range results {
+		switch result.Status {
+		case "updated":
+			installed++
+		case "busy", "skipped":
+		default:
+			t.Fatalf("update %d: unexpected status %q (%s)", i, result.Status, result.Message)
+		}
+	}
+	if installed == 0 {
+		t.Fatalf("expected at least one update to be installed")
+	}
+
+	// The installed binary must be exactly one of the payloads, never a mix
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		t.Fatalf("failed to read installed binary: %v", err)
+	}
+	matched := false
+	for _, payload := range payloads {
+		if string(data) == string(payload) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		t.Fatalf("installed binary %q does not match any payload", data)
+	}
+	if fileExists(b.tempPath) {
+		t.Fatalf("temp binary %s should not be left behind", b.tempPath)
+	}
+}
+
+func TestClientBinaryHandlerIsIndependent(t *testing.T) {
+	client := NewClientBinaryHandler()
+	if client.Path() != clientBinaryPath || client.tempPath != clientBinaryTempPath {
+		t.Fatalf("unexpected client binary paths %s, %s", client.Path(), client.tempPath)
+	}
+
+	app := newTestBinaryHandler(t)
+	dir := t.TempDir()
+	client.path = filepath.Join(dir, "client")
+	client.tempPath = filepath.Join(dir, "client.new")
+	client.rebootDelay = time.Hour
+	client.reboot = func() error { return nil }
+
+	// An app update in progress doesn't block a client update
+	app.updateMu.Lock()
+	defer app.updateMu.Unlock()
+	result := client.HandleUpdate([]byte("client"), 0, false)
+	if result.Status != "updated" || !result.RebootScheduled {
+		t.Fatalf("expected the client update to install, got %q (%s)", result.Status, result.Message)
+	}
+	if data, err := os.ReadFile(client.path); err != nil || string(data) != "client" {
+		t.Fatalf("expected the client binary at %s, got %q (err %v)", client.path, data, err)
+	}
+	if fileExists(app.path) {
+		t.Fatal("client update must not touch the app binary")
+	}
+	if !client.CancelPendingReboot() {
+		t.Fatal("expected a pending reboot after the client update")
+	}
+}
+
+func TestUseWritableDirKeepsOriginalBinary(t *testing.T) {
+	b := newTestBinaryHandler(t)
+	if err := os.WriteFile(b.path, []byte("original"), 0755); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	originalPath := b.path
+	writableDir := t.TempDir()
+	b.UseWritableDir(writableDir)
+
+	if b.Path() != filepath.Join(writableDir, "main") || b.tempPath != filepath.Join(writableDir, "main.new") {
+		t.Fatalf("unexpected paths %s, %s", b.Path(), b.tempPath)
+	}
+	checksum, err := b.GetCurrentChecksum()
+	if err != nil {
+		t.Fatalf("GetCurrentChecksum failed: %v", err)
+	}
+	if checksum != b.CalculateChecksum([]byte("original")) {
+		t.Fatal("expected the original binary to be current before an update")
+	}
+
+	result := b.HandleUpdate([]byte("updated"), 0, false)
+	if result.Status != "updated" {
+		t.Fatalf("expected updated status, got %q (%s)", result.Status, result.Message)
+	}
+	if data, err := os.ReadFile(originalPath); err != nil || string(data) != "original" {
+		t.Fatalf("expected the original binary to be untouched, got %q (%v)", data, err)
+	}
+	if checksum, _ := b.GetCurrentChecksum(); checksum != b.CalculateChecksum([]byte("updated")) {
+		t.Fatal("expected the installed update to be current")
+	}
+}
+
+// installStampedBinary builds a tiny Go program stamped with buildTimestamp,
+// the way strux-build-app.sh stamps apps, and installs it as the current binary
+func installStampedBinary(t *testing.T, b *BinaryHandler, buildTimestamp int64) []byte {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "go.mod"), []byte("module stamped\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n\nvar BuildTimestamp string\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	cmd := exec.Command(goTool, "build", "-o", b.path, "-ldflags", fmt.Sprintf("-X %s=%d", buildTimestampSymbol, buildTimestamp), ".")
+	cmd.Dir = src
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, out)
+	}
+
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if got := b.GetCurrentBuildTimestamp(); got != buildTimestamp {
+		t.Fatalf("expected the installed binary to carry timestamp %d, got %d", buildTimestamp, got)
+	}
+	return data
+}
+
+func TestHandleUpdateRejectsDowngrade(t *testing.T) {
+	b := newTestBinaryHandler(t)
+	current := installStampedBinary(t, b, 200)
+
+	result := b.HandleUpdate([]byte("older"), 100, false)
+	if result.Status != "skipped" || result.Reason != "DOWNGRADE" {
+		t.Fatalf("expected a DOWNGRADE skip, got %q/%q (%s)", result.Status, result.Reason, result.Message)
+	}
+	if result.RebootScheduled {
+		t.Fatal("a rejected downgrade must not schedule a reboot")
+	}
+	if data, _ := os.ReadFile(b.path); string(data) != string(current) {
+		t.Fatal("a rejected downgrade must leave the current binary in place")
+	}
+	if fileExists(b.tempPath) {
+		t.Fatal("a rejected downgrade must not write the temp binary")
+	}
+}
+
+func TestHandleUpdateForcedDowngrade(t *testing.T) {
+	b := newTestBinaryHandler(t)
+	installStampedBinary(t, b, 200)
+
+	result := b.HandleUpdate([]byte("older"), 100, true)
+	if result.Status != "updated" || !result.RebootScheduled {
+		t.Fatalf("expected a forced downgrade to install, got %q (%s)", result.Status, result.Message)
+	}
+	if data, _ := os.ReadFile(b.path); string(data) != "older" {
+		t.Fatalf("expected the older binary to be installed, got %d bytes", len(data))
+	}
+}
+
+func TestHandleUpdateEqualVersions(t *testing.T) {
+	b := newTestBinaryHandler(t)
+	current := installStampedBinary(t, b, 200)
+
+	// The same binary again is skipped as identical, not as a downgrade
+	result := b.HandleUpdate(current, 0, false)
+	if result.Status != "skipped" || result.Reason != "" {
+		t.Fatalf("expected an identical binary to be skipped without a reason, got %q/%q (%s)", result.Status, result.Reason, result.Message)
+	}
+
+	// A rebuild with the same timestamp is not older, so it installs
+	result = b.HandleUpdate([]byte("rebuilt"), 200, false)
+	if result.Status != "updated" {
+		t.Fatalf("expected a binary with an equal timestamp to install, got %q/%q (%s)", result.Status, result.Reason, result.Message)
+	}
+}
+
+func TestCancelPendingReboot(t *testing.T) {
+	b := newTestBinaryHandler(t)
+	b.rebootDelay = 50 * time.Millisecond
+	rebooted := make(chan struct{}, 1)
+	b.reboot = func() error {
+		rebooted <- struct{}{}
+		return nil
+	}
+
+	if result := b.HandleUpdate([]byte("first"), 0, false); !result.RebootScheduled {
+		t.Fatalf("expected a reboot to be scheduled, got %q (%s)", result.Status, result.Message)
+	}
+	if !b.CancelPendingReboot() {
+		t.Fatal("expected the pending reboot to be cancelled")
+	}
+	select {
+	case <-rebooted:
+		t.Fatal("a cancelled reboot must not run")
+	case <-time.After(4 * b.rebootDelay):
+	}
+
+	// Nothing is pending any more
+	if b.CancelPendingReboot() {
+		t.Fatal("expected a second cancel to be a no-op")
+	}
+
+	// The next update schedules a fresh reboot
+	if result := b.HandleUpdate([]byte("second"), 0, false); !result.RebootScheduled {
+		t.Fatalf("expected a reboot to be scheduled, got %q (%s)", result.Status, result.Message)
+	}
+	select {
+	case <-rebooted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the reboot to run after the delay")
+	}
+}
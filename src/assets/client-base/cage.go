@@ -9,6 +9,7 @@
 package main
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
@@ -17,10 +18,38 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// localFrontendURL is where the backend serves the bundled frontend
+const localFrontendURL = "http://localhost:8080"
+
+// offlinePagePath is the bundled page shown when none of the configured
+// production URLs is reachable
+const offlinePagePath = "/strux/frontend/offline.html"
+
+// defaultSplashImages are the splash image candidates tried in order when the
+// display config lists none. {resolution} is replaced with the display
+// resolution, so a device variant can ship a logo sized for its panel.
+var defaultSplashImages = []string{
+	"/strux/logo-{resolution}.png",
+	"/strux/logo-{resolution}.jpg",
+	"/strux/logo.png",
+	"/strux/logo.jpg",
+}
+
+// defaultWebExtensionsDir is where Strux images install the WPE extension
+const defaultWebExtensionsDir = "/usr/lib/wpe-web-extensions"
+
+// cageControlSocket is the control socket the Strux Cage build listens on
+const cageControlSocket = "/tmp/strux-cage-control.sock"
+
+// cageControlTimeout bounds a control command round trip
+const cageControlTimeout = 5 * time.Second
+
 // ErrBackendNotReady is returned when the backend doesn't start in time
 var ErrBackendNotReady = errors.New("backend not ready")
 
@@ -32,12 +61,21 @@ type LaunchOptions struct {
 	OnlyDisplayImage string
 	// Resolution is the display resolution for single-monitor mode (e.g., "1920x1080")
 	Resolution string
-	// SplashImage is the path to the splash image (optional)
+	// SplashImage is the path to the splash image, PNG or JPEG (optional)
 	SplashImage string
+	// SplashColor is the splash background color as RRGGBB, shown alone when
+	// there is no splash image (optional, defaults to black)
+	SplashColor string
 	// Inspector holds the WebKit Inspector configuration (optional, for dev mode)
 	Inspector *InspectorConfig
 	// DisplayConfig holds multi-monitor display configuration (optional)
 	DisplayConfig *DisplayConfig
+	// WebExtensionsDir is the directory Cog loads WPE web extensions from
+	// (optional, defaults to /usr/lib/wpe-web-extensions)
+	WebExtensionsDir string
+	// WebKitEnv are extra KEY=VALUE environment variables for Cog and its
+	// WebKit processes, such as memory tuning (optional)
+	WebKitEnv []string
 }
 
 // CageLauncher manages the Cage compositor process
@@ -46,6 +84,14 @@ type CageLauncher struct {
 	done    chan error
 	logger  *Logger
 	logFile *os.File
+
+	// launchOpts are the options of the last launch, reused to rewrite the
+	// display map when navigating
+	launchOpts LaunchOptions
+
+	// running and pid mirror the Cage process state for health reporting
+	running atomic.Bool
+	pid     atomic.Int64
 }
 
 // CageLauncherInstance is the global Cage launcher
@@ -195,7 +241,11 @@ func (c *CageLauncher) hasDefaultRoute() bool {
 // WaitForDevServer waits for the dev server (Vite) to be reachable at the specified URL
 func (c *CageLauncher) WaitForDevServer(url string, timeout time.Duration) bool {
 	c.logger.Info("Waiting for dev server at %s (timeout: %v)...", url, timeout)
+	return c.waitForURL(url, timeout)
+}
 
+// waitForURL polls url until it answers with a 2xx status or timeout passes
+func (c *CageLauncher) waitForURL(url string, timeout time.Duration) bool {
 	client := &http.Client{Timeout: 2 * time.Second}
 	deadline := time.Now().Add(timeout)
 	attempt := 0
@@ -205,23 +255,43 @@ func (c *CageLauncher) WaitForDevServer(url string, timeout time.Duration) bool
 		resp, err := client.Get(url)
 		if err != nil {
 			if attempt%10 == 1 { // Log every 10th attempt (every 5 seconds)
-				c.logger.Info("Dev server not reachable yet (attempt %d): %v", attempt, err)
+				c.logger.Info("%s not reachable yet (attempt %d): %v", url, attempt, err)
 			}
 		} else {
 			resp.Body.Close()
 			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-				c.logger.Info("Dev server is reachable! (status: %d, after %d attempts)", resp.StatusCode, attempt)
+				c.logger.Info("%s is reachable! (status: %d, after %d attempts)", url, resp.StatusCode, attempt)
 				return true
 			}
-			c.logger.Warn("Dev server returned status %d (attempt %d)", resp.StatusCode, attempt)
+			c.logger.Warn("%s returned status %d (attempt %d)", url, resp.StatusCode, attempt)
 		}
 		time.Sleep(500 * time.Millisecond)
 	}
 
-	c.logger.Error("Dev server did not become reachable within %v (after %d attempts)", timeout, attempt)
+	c.logger.Error("%s did not become reachable within %v (after %d attempts)", url, timeout, attempt)
 	return false
 }
 
+// ProductionURL picks the URL production mode loads: the first of urls that
+// is reachable, tried in order, or else the bundled frontend served by the
+// backend. The bundled frontend's offline page is used when it has one, so
+// apps can tell the user the remote UI is unavailable.
+func (c *CageLauncher) ProductionURL(urls []string, timeout time.Duration) string {
+	for _, candidate := range urls {
+		c.logger.Info("Checking production URL %s (timeout: %v)...", candidate, timeout)
+		if c.waitForURL(candidate, timeout) {
+			return candidate
+		}
+		c.logger.Warn("Production URL %s is unreachable, trying the next one", candidate)
+	}
+
+	if len(urls) > 0 && fileExists(offlinePagePath) {
+		c.logger.Warn("No production URL is reachable, showing the offline page")
+		return localFrontendURL + "/" + filepath.Base(offlinePagePath)
+	}
+	return localFrontendURL
+}
+
 func withLaunchToken(rawURL, token string) string {
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
@@ -296,9 +366,84 @@ func displayConfigDefaultTransform(config *DisplayConfig) string {
 	return ""
 }
 
+// displayConfigWebExtensionsDir returns the configured web extensions
+// directory, or "" for the default
+func displayConfigWebExtensionsDir(config *DisplayConfig) string {
+	if config == nil {
+		return ""
+	}
+	return config.WebExtensionsDir
+}
+
+// splashSettings returns the splash image to show, the first configured
+// candidate that exists (or "" for none), and the fallback background color
+func splashSettings(config *DisplayConfig, resolution string) (string, string) {
+	candidates := defaultSplashImages
+	color := ""
+	if config != nil {
+		if len(config.SplashImages) > 0 {
+			candidates = config.SplashImages
+		}
+		color = config.SplashColor
+	}
+	return findSplashImage(candidates, resolution), color
+}
+
+// findSplashImage returns the first candidate that exists after substituting
+// {resolution}, or "" if none does
+func findSplashImage(candidates []string, resolution string) string {
+	for _, candidate := range candidates {
+		path := strings.ReplaceAll(candidate, "{resolution}", resolution)
+		if fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// displayConfigWebKitEnv returns the environment variables for the WebKit
+// tuning in the display config
+func displayConfigWebKitEnv(config *DisplayConfig) []string {
+	if config == nil || config.WebKit == nil {
+		return nil
+	}
+
+	var env []string
+	if config.WebKit.JSRAMSizeMB > 0 {
+		env = append(env, fmt.Sprintf("JSC_forceRAMSize=%d", config.WebKit.JSRAMSizeMB*1024*1024))
+	}
+	for _, item := range config.WebKit.Env {
+		if strings.Contains(item, "=") {
+			env = append(env, item)
+		}
+	}
+	return env
+}
+
+// displayConfigHasResolution reports whether any monitor sets a resolution
+func displayConfigHasResolution(config *DisplayConfig) bool {
+	if config == nil {
+		return false
+	}
+
+	for _, monitor := range config.Monitors {
+		if monitor.Resolution != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LastLaunch returns the options of the last call to Launch
+func (c *CageLauncher) LastLaunch() LaunchOptions {
+	return c.launchOpts
+}
+
 // Launch starts Cage compositor with Cog browser
 func (c *CageLauncher) Launch(opts LaunchOptions) error {
 	c.logger.Info("Launching Cage and Cog with URL: %s", opts.CogURL)
+	c.launchOpts = opts
 
 	// Note: Network readiness is checked before calling Launch() in dev mode
 	// This ensures both Cog and WebKit Inspector can use the network properly
@@ -320,21 +465,38 @@ func (c *CageLauncher) Launch(opts LaunchOptions) error {
 		args = append(args, fmt.Sprintf("--display-map=%s", displayMapPath))
 	}
 
+	// Cage applies configured resolutions with wlr-randr before starting Cog.
+	// Without it Cog still starts, at the compositor's default mode.
+	if displayConfigHasResolution(opts.DisplayConfig) {
+		if _, err := exec.LookPath("wlr-randr"); err != nil {
+			c.logger.Warn("wlr-randr not found in PATH: configured display resolutions will not be applied, outputs keep the compositor's default mode")
+		}
+	}
+
 	// Add splash image if provided
 	if opts.OnlyDisplayImage != "" {
 		args = append(args, fmt.Sprintf("--only-display-image=%s", opts.OnlyDisplayImage))
 	} else if opts.SplashImage != "" {
 		args = append(args, fmt.Sprintf("--splash-image=%s", opts.SplashImage))
 	}
+	if opts.OnlyDisplayImage == "" && opts.SplashColor != "" {
+		args = append(args, fmt.Sprintf("--splash-color=%s", opts.SplashColor))
+	}
 
 	// No primary client command — Cage manages Cog lifecycle directly
 
 	// Create the command
 	c.process = exec.Command("cage", args...)
 
+	// Cage and strux-run-cog.sh pass this on to Cog as --web-extensions-dir
+	webExtensionsDir := opts.WebExtensionsDir
+	if webExtensionsDir == "" {
+		webExtensionsDir = defaultWebExtensionsDir
+	}
+
 	// Set environment variables required for Cage and WebKit
 	cageEnv := append(os.Environ(),
-		"WPE_WEB_EXTENSION_PATH=/usr/lib/wpe-web-extensions",
+		"WPE_WEB_EXTENSION_PATH="+webExtensionsDir,
 		"SEATD_SOCK=/run/seatd.sock",
 		"WEBKIT_DISABLE_SANDBOX_THIS_IS_DANGEROUS=1",
 		"WEBKIT_FORCE_SANDBOX=0",
@@ -358,6 +520,10 @@ func (c *CageLauncher) Launch(opts LaunchOptions) error {
 		c.logger.Info("Loaded %d custom Cage environment variables", len(extraEnv))
 		cageEnv = append(cageEnv, extraEnv...)
 	}
+	if len(opts.WebKitEnv) > 0 {
+		c.logger.Info("Applying WebKit environment: %s", strings.Join(opts.WebKitEnv, " "))
+		cageEnv = append(cageEnv, opts.WebKitEnv...)
+	}
 	c.process.Env = cageEnv
 
 	// Write WebKit Inspector config for per-Cog port assignment (dev mode)
@@ -380,9 +546,10 @@ func (c *CageLauncher) Launch(opts LaunchOptions) error {
 		os.Remove("/tmp/strux-inspector-counter")
 	}
 
-	// Open log file
+	// Open log file. Append mode lets strux.system.DiskCleanup truncate it
+	// in place without the next write landing at the old offset.
 	var err error
-	c.logFile, err = os.Create("/tmp/strux-cage.log")
+	c.logFile, err = os.OpenFile("/tmp/strux-cage.log", os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0644)
 	if err != nil {
 		c.logger.Warn("Could not create log file: %v", err)
 	}
@@ -408,8 +575,11 @@ func (c *CageLauncher) Launch(opts LaunchOptions) error {
 	done := make(chan error, 1)
 	c.done = done
 	process := c.process
+	c.pid.Store(int64(process.Process.Pid))
+	c.running.Store(true)
 	go func() {
 		err := process.Wait()
+		c.running.Store(false)
 		if err != nil {
 			c.logger.Error("Cage exited with error: %v", err)
 		} else {
@@ -421,6 +591,74 @@ func (c *CageLauncher) Launch(opts LaunchOptions) error {
 	return nil
 }
 
+// Navigate points Cog on every output at cogURL without restarting Cage. It
+// rewrites the display map with the new base URL and asks Cage to restart the
+// Cog instances, which load their URL from the map.
+func (c *CageLauncher) Navigate(cogURL string) error {
+	if !c.running.Load() {
+		return errors.New("cage is not running")
+	}
+
+	opts := c.launchOpts
+	opts.CogURL = cogURL
+	if err := c.writeDisplayMap(opts); err != nil {
+		return fmt.Errorf("failed to write display map: %w", err)
+	}
+	c.launchOpts = opts
+
+	c.logger.Info("Navigating Cog to %s", cogURL)
+	return c.restartCog()
+}
+
+// Reload restarts the Cog instances on every output at their current URL,
+// without restarting Cage
+func (c *CageLauncher) Reload() error {
+	c.logger.Info("Reloading Cog")
+	return c.restartCog()
+}
+
+// restartCog asks Cage to restart the Cog instances, which load their URL
+// from the display map
+func (c *CageLauncher) restartCog() error {
+	if !c.running.Load() {
+		return errors.New("cage is not running")
+	}
+	return sendCageCommand(cageControlSocket, "NAVIGATE", cageControlTimeout)
+}
+
+// sendCageCommand writes a command to Cage's control socket and waits for
+// the compositor to acknowledge it
+func sendCageCommand(socketPath, command string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("unix", socketPath, timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Cage control socket: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return fmt.Errorf("failed to send %s: %w", command, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("no reply to %s: %w", command, err)
+	}
+	if reply = strings.TrimSpace(reply); reply != "OK" {
+		return fmt.Errorf("cage rejected %s: %s", command, strings.TrimPrefix(reply, "ERR "))
+	}
+	return nil
+}
+
+// Status reports whether the Cage process (and the Cog instances it manages)
+// is running, along with its PID
+func (c *CageLauncher) Status() (bool, int) {
+	if !c.running.Load() {
+		return false, 0
+	}
+	return true, int(c.pid.Load())
+}
+
 // Cleanup terminates the Cage process
 func (c *CageLauncher) Cleanup() {
 	if c.process != nil && c.process.Process != nil {
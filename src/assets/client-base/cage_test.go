@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProductionURLFallsThroughUnreachableURLs(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+
+	cage := &CageLauncher{logger: NewLogger("Test")}
+
+	if got := cage.ProductionURL([]string{down.URL, up.URL}, 600*time.Millisecond); got != up.URL {
+		t.Fatalf("expected the first reachable URL %s, got %s", up.URL, got)
+	}
+	if got := cage.ProductionURL([]string{down.URL}, 600*time.Millisecond); got != localFrontendURL {
+		t.Fatalf("expected the bundled frontend when nothing is reachable, got %s", got)
+	}
+	if got := cage.ProductionURL(nil, time.Second); got != localFrontendURL {
+		t.Fatalf("expected the bundled frontend without production URLs, got %s", got)
+	}
+}
+
+func TestSendCageCommand(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "control.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 256)
+			n, _ := conn.Read(buf)
+			received <- string(buf[:n])
+			if string(buf[:n]) == "NAVIGATE" {
+				conn.Write([]byte("OK\n"))
+			} else {
+				conn.Write([]byte("ERR unknown command\n"))
+			}
+			conn.Close()
+		}
+	}()
+
+	if err := sendCageCommand(socketPath, "NAVIGATE", time.Second); err != nil {
+		t.Fatalf("sendCageCommand failed: %v", err)
+	}
+	if got := <-received; got != "NAVIGATE" {
+		t.Fatalf("expected the command without a newline, got %q", got)
+	}
+	if err := sendCageCommand(socketPath, "BOGUS", time.Second); err == nil {
+		t.Fatal("expected an error for a rejected command")
+	}
+}
+
+func TestFindSplashImage(t *testing.T) {
+	dir := t.TempDir()
+	themed := filepath.Join(dir, "logo-800x480.jpg")
+	generic := filepath.Join(dir, "logo.png")
+	for _, path := range []string{themed, generic} {
+		if err := os.WriteFile(path, []byte("image"), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	candidates := []string{
+		filepath.Join(dir, "logo-{resolution}.png"),
+		filepath.Join(dir, "logo-{resolution}.jpg"),
+		generic,
+	}
+	if got := findSplashImage(candidates, "800x480"); got != themed {
+		t.Fatalf("expected the image for the resolution, got %q", got)
+	}
+	if got := findSplashImage(candidates, "1920x1080"); got != generic {
+		t.Fatalf("expected the generic image, got %q", got)
+	}
+	if got := findSplashImage(candidates[:2], "1920x1080"); got != "" {
+		t.Fatalf("expected no image, got %q", got)
+	}
+}
+
+func TestDisplayConfigWebKitEnv(t *testing.T) {
+	if env := displayConfigWebKitEnv(&DisplayConfig{}); env != nil {
+		t.Fatalf("expected no environment without WebKit settings, got %v", env)
+	}
+
+	config := &DisplayConfig{WebKit: &WebKitConfig{
+		JSRAMSizeMB: 256,
+		Env:         []string{"JSC_useJIT=false", "malformed"},
+	}}
+	want := []string{"JSC_forceRAMSize=268435456", "JSC_useJIT=false"}
+	if env := displayConfigWebKitEnv(config); !reflect.DeepEqual(env, want) {
+		t.Fatalf("expected %v, got %v", want, env)
+	}
+}
@@ -4,6 +4,13 @@
 // Handles loading and parsing of the dev client configuration file.
 // The config file is placed at /strux/.dev-env.json during dev builds.
 //
+// The config may define named profiles (e.g. "home", "office", "ci") that
+// override the top-level settings. STRUX_PROFILE selects one at boot, falling
+// back to defaultProfile and then to the top-level settings alone.
+//
+// STRUX_MODE overrides how the client picks between dev and production mode
+// (see BootMode).
+//
 
 package main
 
@@ -11,8 +18,42 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// profileEnvVar selects a named profile from the dev config
+const profileEnvVar = "STRUX_PROFILE"
+
+// modeEnvVar forces dev or production mode
+const modeEnvVar = "STRUX_MODE"
+
+// BootMode selects between dev and production mode at boot
+type BootMode string
+
+const (
+	// BootModeAuto uses dev mode when the dev config exists, falling back to
+	// production if the dev server can't be reached
+	BootModeAuto BootMode = "auto"
+	// BootModeDev requires dev mode; failing to start it is fatal
+	BootModeDev BootMode = "dev"
+	// BootModeProduction always uses production mode, ignoring the dev config
+	BootModeProduction BootMode = "production"
 )
 
+// ParseBootMode parses a STRUX_MODE value. An empty value means auto.
+func ParseBootMode(value string) (BootMode, error) {
+	switch mode := BootMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return BootModeAuto, nil
+	case BootModeAuto, BootModeDev, BootModeProduction:
+		return mode, nil
+	default:
+		return BootModeAuto, fmt.Errorf("invalid %s %q (expected dev, production or auto)", modeEnvVar, value)
+	}
+}
+
 // Host represents a dev server host
 type Host struct {
 	Host string `json:"host"`
@@ -39,6 +80,17 @@ func (u USBConfig) IsEnabled() bool {
 	return u.Enabled == nil || *u.Enabled
 }
 
+// LogsConfig controls what happens to log streams when the dev server
+// connection drops
+type LogsConfig struct {
+	// BufferOnDisconnect keeps log streams running while disconnected and
+	// sends the lines buffered in the meantime on reconnect
+	BufferOnDisconnect bool `json:"bufferOnDisconnect,omitempty"`
+	// BufferLines bounds the buffer; the oldest lines are dropped beyond it.
+	// Defaults to 1000 when unset.
+	BufferLines int `json:"bufferLines,omitempty"`
+}
+
 // Config holds the dev client configuration
 type Config struct {
 	// ClientKey is the authentication key for the dev server
@@ -47,6 +99,10 @@ type Config struct {
 	// UseMDNS enables mDNS discovery for finding the dev server
 	UseMDNS bool `json:"useMDNS"`
 
+	// MDNSEagerFirst stops mDNS discovery at the first host found instead of
+	// waiting the full browse window to collect every dev server
+	MDNSEagerFirst bool `json:"mdnsEagerFirst,omitempty"`
+
 	// FallbackHosts are hosts to try if mDNS discovery fails
 	FallbackHosts []Host `json:"fallbackHosts"`
 
@@ -55,6 +111,31 @@ type Config struct {
 
 	// USB holds USB debug Ethernet settings
 	USB USBConfig `json:"usb"`
+
+	// Logs holds log streaming settings
+	Logs LogsConfig `json:"logs"`
+
+	// RebootDelayMs is the grace period before rebooting after a binary
+	// update. Defaults to 2000 when unset; 0 reboots immediately.
+	RebootDelayMs *int `json:"rebootDelayMs"`
+
+	// Profiles are named overrides of the settings above. Only the keys
+	// present in a profile replace the top-level values.
+	Profiles map[string]json.RawMessage `json:"profiles,omitempty"`
+
+	// DefaultProfile is used when STRUX_PROFILE is unset or names an unknown profile
+	DefaultProfile string `json:"defaultProfile,omitempty"`
+
+	// Profile is the name of the profile that was applied, if any
+	Profile string `json:"-"`
+}
+
+// RebootDelay returns the configured reboot grace period
+func (c *Config) RebootDelay() time.Duration {
+	if c.RebootDelayMs == nil || *c.RebootDelayMs < 0 {
+		return defaultRebootDelay
+	}
+	return time.Duration(*c.RebootDelayMs) * time.Millisecond
 }
 
 // DisplayMonitor represents a single monitor's display configuration
@@ -73,6 +154,33 @@ type DisplayMonitor struct {
 type DisplayConfig struct {
 	// Monitors is the list of monitor configurations
 	Monitors []DisplayMonitor `json:"monitors"`
+	// URLs are the URLs production mode tries in order before falling back
+	// to the bundled frontend. Empty means the bundled frontend is loaded.
+	URLs []string `json:"urls,omitempty"`
+	// WebExtensionsDir overrides where Cog loads WPE web extensions from,
+	// for images whose WebKit layout differs from the default
+	WebExtensionsDir string `json:"webExtensionsDir,omitempty"`
+	// SplashImages are the splash image paths tried in order, with
+	// {resolution} replaced by the display resolution. Empty means the
+	// default logo paths.
+	SplashImages []string `json:"splashImages,omitempty"`
+	// SplashColor is the splash background color as RRGGBB, also shown
+	// alone when none of the splash images exists
+	SplashColor string `json:"splashColor,omitempty"`
+	// WebKit holds memory tuning for Cog's WebKit processes (optional)
+	WebKit *WebKitConfig `json:"webkit,omitempty"`
+}
+
+// WebKitConfig tunes the WebKit processes behind Cog, mainly to keep them
+// from being OOM-killed on memory-constrained devices
+type WebKitConfig struct {
+	// JSRAMSizeMB makes JavaScriptCore size its heap and garbage collection
+	// as if the device had this much RAM (JSC_forceRAMSize). 0 leaves the
+	// detected size.
+	JSRAMSizeMB int `json:"jsRamSizeMB,omitempty"`
+	// Env are extra KEY=VALUE environment variables for Cog and WebKit,
+	// applied after the BSP's Cage environment
+	Env []string `json:"env,omitempty"`
 }
 
 // LoadDisplayConfig loads the display configuration from the specified path
@@ -102,11 +210,56 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	if err := applyProfile(&config, os.Getenv(profileEnvVar)); err != nil {
+		return nil, err
+	}
+
 	normalizeConfig(&config)
 
 	return &config, nil
 }
 
+// applyProfile overlays the requested profile onto the top-level settings. An
+// unknown requested profile falls back to DefaultProfile; an unknown
+// DefaultProfile is an error since it can only come from a broken config.
+func applyProfile(config *Config, requested string) error {
+	name := requested
+	if _, ok := config.Profiles[name]; name != "" && !ok {
+		NewLogger("Config").Warn("Profile %q not found (available: %v), using default", name, profileNames(config))
+		name = ""
+	}
+	if name == "" {
+		name = config.DefaultProfile
+	}
+	if name == "" {
+		return nil
+	}
+
+	raw, ok := config.Profiles[name]
+	if !ok {
+		return fmt.Errorf("default profile %q not found in config", name)
+	}
+
+	// Unmarshalling onto the existing config only replaces keys the profile sets
+	profiles, defaultProfile := config.Profiles, config.DefaultProfile
+	if err := json.Unmarshal(raw, config); err != nil {
+		return fmt.Errorf("failed to parse profile %q: %w", name, err)
+	}
+	config.Profiles, config.DefaultProfile = profiles, defaultProfile
+	config.Profile = name
+	return nil
+}
+
+// profileNames returns the sorted names of the profiles in the config
+func profileNames(config *Config) []string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func normalizeConfig(config *Config) {
 	if config.USB.Subnet == "" {
 		config.USB.Subnet = defaultUSBSubnet
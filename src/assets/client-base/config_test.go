@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigAppliesProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".dev-env.json")
+	config := `{
+	"clientKey": "base-key",
+	"useMDNS": true,
+	"fallbackHosts": [{"host": "10.0.0.2", "port": 8000}],
+	"defaultProfile": "home",
+	"profiles": {
+		"home": {"fallbackHosts": [{"host": "192.168.1.20", "port": 8000}]},
+		"ci": {"useMDNS": false, "fallbackHosts": [{"host": "ci.local", "port": 9000}]}
+	}
+}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	t.Setenv(profileEnvVar, "ci")
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if loaded.Profile != "ci" || loaded.UseMDNS || loaded.FallbackHosts[0].Host != "ci.local" {
+		t.Fatalf("expected ci profile to be applied, got %+v", loaded)
+	}
+	if loaded.ClientKey != "base-key" {
+		t.Fatalf("expected unset profile keys to keep top-level values, got %q", loaded.ClientKey)
+	}
+
+	t.Setenv(profileEnvVar, "missing")
+	loaded, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if loaded.Profile != "home" || loaded.FallbackHosts[0].Host != "192.168.1.20" {
+		t.Fatalf("expected fallback to default profile, got %+v", loaded)
+	}
+}
+
+func TestParseBootMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    BootMode
+		wantErr bool
+	}{
+		{"", BootModeAuto, false},
+		{"auto", BootModeAuto, false},
+		{"dev", BootModeDev, false},
+		{" Production ", BootModeProduction, false},
+		{"prod", BootModeAuto, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBootMode(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseBootMode(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("ParseBootMode(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
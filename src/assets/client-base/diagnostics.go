@@ -0,0 +1,187 @@
+//
+// Strux Client - Boot Diagnostics
+//
+// Accumulates a structured record of the boot sequence (discovery, connect
+// attempts, readiness waits, final mode) and dumps it as a single JSON blob
+// once boot completes. Makes field debugging possible without having to
+// piece together interleaved log lines. Once the app is up, a shorter ready
+// summary records what the device ended up running.
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ConnectAttempt records the result of connecting to a single dev server host
+type ConnectAttempt struct {
+	Host       string `json:"host"`
+	Port       int    `json:"port"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// BootDiagnostics holds the structured record of the boot sequence
+type BootDiagnostics struct {
+	mu sync.Mutex
+
+	StartedAt       time.Time        `json:"startedAt"`
+	ClientVersion   string           `json:"clientVersion"`
+	DevConfig       bool             `json:"devConfig"`
+	USBEnabled      bool             `json:"usbEnabled"`
+	HostsFound      []Host           `json:"hostsFound"`
+	ConnectAttempts []ConnectAttempt `json:"connectAttempts"`
+	NetworkReadyMs  *int64           `json:"networkReadyMs,omitempty"`
+	BackendReadyMs  *int64           `json:"backendReadyMs,omitempty"`
+	FinalMode       string           `json:"finalMode"`                // "dev", "production", or "failed" (forced dev mode could not start)
+	FallbackReason  string           `json:"fallbackReason,omitempty"` // Why dev mode fell back to production
+	TotalMs         int64            `json:"totalMs"`
+
+	dumped bool
+	ready  bool
+}
+
+// ReadySummary answers "what did this device actually do at boot" in one
+// record. It is logged, and sent to the dev server in dev mode, once the app
+// has been launched.
+type ReadySummary struct {
+	Mode          string `json:"mode"`                    // "dev" or "production"
+	Host          string `json:"host,omitempty"`          // dev server host:port, in dev mode
+	Resolution    string `json:"resolution"`              // fallback resolution for outputs without a monitor config
+	SplashImage   string `json:"splashImage,omitempty"`   // empty when only a color (or nothing) was shown
+	SplashColor   string `json:"splashColor,omitempty"`   // RRGGBB
+	BackendURL    string `json:"backendUrl"`              // the URL Cog loaded
+	Inspector     bool   `json:"inspector"`               // whether the WebKit Inspector is enabled
+	InspectorPort int    `json:"inspectorPort,omitempty"` // base port, when enabled
+	BootMs        int64  `json:"bootMs"`                  // time from client start to ready
+}
+
+// BootDiagnosticsInstance is the global boot diagnostics record
+var BootDiagnosticsInstance = &BootDiagnostics{
+	StartedAt:     time.Now(),
+	ClientVersion: Version,
+}
+
+// SetDevConfig records whether a dev config was found and if USB networking is enabled
+func (d *BootDiagnostics) SetDevConfig(devConfig, usbEnabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.DevConfig = devConfig
+	d.USBEnabled = usbEnabled
+}
+
+// SetHostsFound records the hosts returned by discovery
+func (d *BootDiagnostics) SetHostsFound(hosts []Host) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.HostsFound = append([]Host(nil), hosts...)
+}
+
+// RecordConnectAttempt records the outcome of a connection attempt to a host
+func (d *BootDiagnostics) RecordConnectAttempt(host Host, duration time.Duration, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	attempt := ConnectAttempt{
+		Host:       host.Host,
+		Port:       host.Port,
+		Success:    err == nil,
+		DurationMs: duration.Milliseconds(),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	d.ConnectAttempts = append(d.ConnectAttempts, attempt)
+}
+
+// RecordNetworkReady records how long it took for the dev server/network to become reachable
+func (d *BootDiagnostics) RecordNetworkReady(duration time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ms := duration.Milliseconds()
+	d.NetworkReadyMs = &ms
+}
+
+// RecordBackendReady records how long it took for the app backend to become ready
+func (d *BootDiagnostics) RecordBackendReady(duration time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ms := duration.Milliseconds()
+	d.BackendReadyMs = &ms
+}
+
+// Status returns the final boot mode (empty until boot completes) and whether
+// the app backend has become ready
+func (d *BootDiagnostics) Status() (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.FinalMode, d.BackendReadyMs != nil
+}
+
+// Finish records the final boot mode and dumps the diagnostics to the log and
+// serial console. Only the first call has any effect.
+func (d *BootDiagnostics) Finish(mode, fallbackReason string) {
+	d.mu.Lock()
+	if d.dumped {
+		d.mu.Unlock()
+		return
+	}
+	d.dumped = true
+	d.FinalMode = mode
+	d.FallbackReason = fallbackReason
+	d.TotalMs = time.Since(d.StartedAt).Milliseconds()
+
+	data, err := json.Marshal(d)
+	d.mu.Unlock()
+
+	logger := NewLogger("BootDiagnostics")
+	if err != nil {
+		logger.Error("Failed to encode boot diagnostics: %v", err)
+		return
+	}
+
+	// Logger writes to both stdout (journal) and the serial console
+	logger.Info("%s", string(data))
+}
+
+// Ready logs the startup summary for a launch with opts and returns it, with
+// false if a summary was already logged. host is the connected dev server, nil
+// in production.
+func (d *BootDiagnostics) Ready(mode string, host *Host, opts LaunchOptions) (ReadySummary, bool) {
+	d.mu.Lock()
+	if d.ready {
+		d.mu.Unlock()
+		return ReadySummary{}, false
+	}
+	d.ready = true
+	summary := ReadySummary{
+		Mode:        mode,
+		Resolution:  opts.Resolution,
+		SplashImage: opts.SplashImage,
+		SplashColor: opts.SplashColor,
+		BackendURL:  opts.CogURL,
+		BootMs:      time.Since(d.StartedAt).Milliseconds(),
+	}
+	d.mu.Unlock()
+
+	if host != nil {
+		summary.Host = fmt.Sprintf("%s:%d", host.Host, host.Port)
+	}
+	if opts.Inspector != nil && opts.Inspector.Enabled {
+		summary.Inspector = true
+		summary.InspectorPort = opts.Inspector.Port
+	}
+
+	logger := NewLogger("Ready")
+	if data, err := json.Marshal(summary); err != nil {
+		logger.Error("Failed to encode startup summary: %v", err)
+	} else {
+		logger.Info("%s", string(data))
+	}
+	return summary, true
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadySummaryIsReportedOnce(t *testing.T) {
+	diag := &BootDiagnostics{StartedAt: time.Now().Add(-3 * time.Second)}
+	opts := LaunchOptions{
+		CogURL:      "http://192.168.1.20:5173",
+		Resolution:  "1280x800",
+		SplashImage: "/strux/logo-1280x800.png",
+		Inspector:   &InspectorConfig{Enabled: true, Port: 9222},
+	}
+
+	summary, ok := diag.Ready("dev", &Host{Host: "192.168.1.20", Port: 8000}, opts)
+	if !ok {
+		t.Fatal("expected the first summary to be reported")
+	}
+	if summary.Mode != "dev" || summary.Host != "192.168.1.20:8000" || summary.Resolution != "1280x800" ||
+		summary.SplashImage != "/strux/logo-1280x800.png" || summary.BackendURL != opts.CogURL ||
+		!summary.Inspector || summary.InspectorPort != 9222 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if summary.BootMs < 3000 {
+		t.Fatalf("expected boot time from client start, got %dms", summary.BootMs)
+	}
+
+	if _, ok := diag.Ready("production", nil, opts); ok {
+		t.Fatal("expected only one summary to be reported")
+	}
+}
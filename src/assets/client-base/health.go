@@ -0,0 +1,132 @@
+//
+// Strux Client - Health Report
+//
+// Periodically writes a snapshot of client-side subsystem status (boot mode,
+// backend readiness, network, Cage/Cog process, dev server connection, log
+// streams) to /run/strux/client-health.json. The Go runtime reads it to serve
+// strux.system.Health() to the frontend.
+//
+
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	healthReportPath     = "/run/strux/client-health.json"
+	healthReportInterval = 5 * time.Second
+)
+
+// CogHealth describes the Cage/Cog process state
+type CogHealth struct {
+	Running bool `json:"running"`
+	PID     int  `json:"pid,omitempty"`
+}
+
+// DevServerHealth describes the dev server WebSocket connection
+type DevServerHealth struct {
+	Connected bool   `json:"connected"`
+	Host      string `json:"host,omitempty"`
+	Port      int    `json:"port,omitempty"`
+	RTTMs     int64  `json:"rttMs,omitempty"` // Round-trip time of the last WebSocket ping
+}
+
+// ClientHealth is the snapshot written to the health report file
+type ClientHealth struct {
+	Mode         string           `json:"mode"` // "dev", "production", or "" while booting
+	BackendReady bool             `json:"backendReady"`
+	NetworkReady bool             `json:"networkReady"`
+	Cog          CogHealth        `json:"cog"`
+	DevServer    *DevServerHealth `json:"devServer,omitempty"` // Only set in dev mode
+	LogStreams   []string         `json:"logStreams"`
+	UpdatedAt    string           `json:"updatedAt"`
+}
+
+// HealthReporter gathers subsystem status and writes it periodically
+type HealthReporter struct {
+	mu      sync.Mutex
+	socket  *SocketClient
+	started bool
+	logger  *Logger
+}
+
+// HealthReporterInstance is the global health reporter
+var HealthReporterInstance = &HealthReporter{
+	logger: NewLogger("Health"),
+}
+
+// SetSocket attaches the dev server socket so its connection and log streams are reported
+func (h *HealthReporter) SetSocket(socket *SocketClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.socket = socket
+}
+
+// Start begins writing the health report. Calling it more than once has no effect.
+func (h *HealthReporter) Start() {
+	h.mu.Lock()
+	if h.started {
+		h.mu.Unlock()
+		return
+	}
+	h.started = true
+	h.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(healthReportInterval)
+		defer ticker.Stop()
+		for {
+			if err := h.write(); err != nil {
+				h.logger.Warn("Failed to write health report: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Snapshot gathers the current status of every subsystem
+func (h *HealthReporter) Snapshot() ClientHealth {
+	mode, backendReady := BootDiagnosticsInstance.Status()
+	cage := CageLauncherInstance
+	running, pid := cage.Status()
+
+	health := ClientHealth{
+		Mode:         mode,
+		BackendReady: backendReady,
+		NetworkReady: cage.hasGlobalIPv4() && cage.hasDefaultRoute(),
+		Cog:          CogHealth{Running: running, PID: pid},
+		LogStreams:   []string{},
+		UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+
+	h.mu.Lock()
+	socket := h.socket
+	h.mu.Unlock()
+
+	if socket != nil {
+		host := socket.GetHost()
+		health.DevServer = &DevServerHealth{
+			Connected: socket.IsConnected(),
+			Host:      host.Host,
+			Port:      host.Port,
+			RTTMs:     socket.LastRTT().Milliseconds(),
+		}
+		health.LogStreams = socket.logStreams.GetActiveStreams()
+		sort.Strings(health.LogStreams)
+	}
+
+	return health
+}
+
+func (h *HealthReporter) write() error {
+	data, err := json.MarshalIndent(h.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return writeStateFile(healthReportPath, data, 0644)
+}
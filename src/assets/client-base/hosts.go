@@ -101,9 +101,25 @@ func DiscoverHosts(config *Config) []Host {
 	}()
 
 	// Collect discovered services - mDNS hosts are prioritized over fallback hosts
-	logger.Info("Waiting 5 seconds for mDNS discovery...")
+	if config.MDNSEagerFirst {
+		logger.Info("Waiting up to 5 seconds for the first mDNS result...")
+	} else {
+		logger.Info("Waiting 5 seconds for mDNS discovery...")
+	}
 	mdnsHosts := make([]Host, 0)
 
+	// If mDNS found hosts, use those first, then fallback hosts
+	discovered := func() []Host {
+		hosts := make([]Host, 0, len(mdnsHosts)+len(config.FallbackHosts))
+		hosts = append(hosts, mdnsHosts...)
+		if len(config.FallbackHosts) > 0 {
+			logger.Info("Adding %d fallback host(s) after %d mDNS host(s)", len(config.FallbackHosts), len(mdnsHosts))
+			hosts = append(hosts, config.FallbackHosts...)
+		}
+		logger.Info("Discovery complete: %d host(s) found", len(hosts))
+		return hosts
+	}
+
 	for {
 		select {
 		case entry := <-entries:
@@ -118,17 +134,15 @@ func DiscoverHosts(config *Config) []Host {
 					logger.Info("Found mDNS service: %s:%d", host.Host, host.Port)
 					break
 				}
+
+				// Stop browsing as soon as one usable host is known
+				if config.MDNSEagerFirst && len(mdnsHosts) > 0 {
+					cancel()
+					return discovered()
+				}
 			}
 		case <-ctx.Done():
-			// If mDNS found hosts, use those first, then fallback hosts
-			hosts := make([]Host, 0, len(mdnsHosts)+len(config.FallbackHosts))
-			hosts = append(hosts, mdnsHosts...)
-			if len(config.FallbackHosts) > 0 {
-				logger.Info("Adding %d fallback host(s) after %d mDNS host(s)", len(config.FallbackHosts), len(mdnsHosts))
-				hosts = append(hosts, config.FallbackHosts...)
-			}
-			logger.Info("Discovery complete: %d host(s) found", len(hosts))
-			return hosts
+			return discovered()
 		}
 	}
 }
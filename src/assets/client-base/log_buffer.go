@@ -0,0 +1,63 @@
+//
+// Strux Client - Disconnected Log Buffer
+//
+// When log buffering is enabled in the dev config, log streams keep running
+// while the dev server connection is down and their lines are held here
+// (bounded, oldest dropped first) until the connection comes back. Short
+// network blips then cost a little memory instead of a gap in the logs.
+//
+
+package main
+
+import "sync"
+
+// defaultLogBufferLines bounds the buffer when the config doesn't set a size
+const defaultLogBufferLines = 1000
+
+// LogBuffer is a bounded FIFO of log lines waiting to be sent
+type LogBuffer struct {
+	mu       sync.Mutex
+	capacity int
+	lines    []LogLinePayload
+	next     int // Index the next line is written to once the buffer is full
+	dropped  int // Lines dropped because the buffer was full
+}
+
+// NewLogBuffer creates a buffer holding at most capacity lines
+func NewLogBuffer(capacity int) *LogBuffer {
+	if capacity <= 0 {
+		capacity = defaultLogBufferLines
+	}
+	return &LogBuffer{capacity: capacity}
+}
+
+// Add buffers a line, dropping the oldest once the buffer is full
+func (b *LogBuffer) Add(line LogLinePayload) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.lines) < b.capacity {
+		b.lines = append(b.lines, line)
+		return
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % b.capacity
+	b.dropped++
+}
+
+// Drain returns the buffered lines, oldest first, and how many were dropped,
+// then empties the buffer
+func (b *LogBuffer) Drain() ([]LogLinePayload, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := make([]LogLinePayload, 0, len(b.lines))
+	lines = append(lines, b.lines[b.next:]...)
+	lines = append(lines, b.lines[:b.next]...)
+	dropped := b.dropped
+
+	b.lines = nil
+	b.next = 0
+	b.dropped = 0
+	return lines, dropped
+}
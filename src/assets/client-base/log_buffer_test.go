@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLogBufferDropsOldestLines(t *testing.T) {
+	buffer := NewLogBuffer(3)
+	for i := 0; i < 5; i++ {
+		buffer.Add(LogLinePayload{Type: "app", Line: fmt.Sprintf("line %d", i)})
+	}
+
+	lines, dropped := buffer.Drain()
+	if dropped != 2 {
+		t.Fatalf("expected 2 dropped lines, got %d", dropped)
+	}
+	if len(lines) != 3 || lines[0].Line != "line 2" || lines[2].Line != "line 4" {
+		t.Fatalf("unexpected lines: %+v", lines)
+	}
+
+	if lines, dropped := buffer.Drain(); len(lines) != 0 || dropped != 0 {
+		t.Fatalf("expected an empty buffer after Drain, got %d lines, %d dropped", len(lines), dropped)
+	}
+}
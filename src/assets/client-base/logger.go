@@ -3,7 +3,8 @@
 //
 // Simple colored logger for the Strux client.
 // Uses ANSI escape codes for terminal colors.
-// Also writes to serial console for debugging in QEMU.
+// Also writes to serial console for debugging in QEMU, and keeps recent
+// lines in memory for strux.system.RecentLogs().
 //
 
 package main
@@ -12,7 +13,9 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -51,6 +54,10 @@ func getSerialConsole() *os.File {
 	return serialConsole
 }
 
+// logMu serializes log output so lines from concurrent goroutines don't
+// interleave on stdout or the serial console
+var logMu sync.Mutex
+
 type Logger struct {
 	service string
 }
@@ -66,13 +73,23 @@ func (l *Logger) log(level, color, msg string, args ...interface{}) {
 		color, level, colorReset,
 		l.service, formatted)
 
+	// Write plain text without colors for cleaner serial output
+	plainLine := fmt.Sprintf("[STRUX] [%s] [%s] %s\n", level, l.service, formatted)
+
+	RecentLogsInstance.Append(time.Now().Format(time.RFC3339) + " " + strings.TrimSuffix(plainLine, "\n"))
+
+	// Open the serial console before taking the lock so the first log call
+	// doesn't hold it while probing devices
+	serial := getSerialConsole()
+
+	logMu.Lock()
+	defer logMu.Unlock()
+
 	// Write to stdout (captured by systemd journal)
-	fmt.Print(logLine)
+	os.Stdout.WriteString(logLine)
 
 	// Also write to serial console for QEMU debugging
-	if serial := getSerialConsole(); serial != nil {
-		// Write plain text without colors for cleaner serial output
-		plainLine := fmt.Sprintf("[STRUX] [%s] [%s] %s\n", level, l.service, formatted)
+	if serial != nil {
 		serial.WriteString(plainLine)
 	}
 }
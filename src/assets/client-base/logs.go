@@ -13,6 +13,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"time"
 )
@@ -31,6 +32,36 @@ const (
 // Lines to replay when starting journalctl follow (recent history before live tail).
 const journalHistoryLines = 800
 
+// Default journalctl output format (-o) for command streams.
+const defaultJournalFormat = "short-precise"
+
+// journalFormats are the journalctl output formats a stream may request.
+var journalFormats = map[string]bool{
+	"short":             true,
+	"short-precise":     true,
+	"short-iso":         true,
+	"short-iso-precise": true,
+	"short-monotonic":   true,
+	"short-unix":        true,
+	"with-unit":         true,
+	"verbose":           true,
+	"json":              true,
+	"json-pretty":       true,
+	"cat":               true,
+}
+
+// ValidateJournalFormat returns the journalctl output format to use, or an
+// error if the format is not allowed. An empty format selects the default.
+func ValidateJournalFormat(format string) (string, error) {
+	if format == "" {
+		return defaultJournalFormat, nil
+	}
+	if !journalFormats[format] {
+		return "", fmt.Errorf("unsupported output format %q", format)
+	}
+	return format, nil
+}
+
 // Max bytes of each file-backed log to send on connect before tailing new lines only.
 const maxFileHistoryBytes = 512 * 1024
 
@@ -47,23 +78,63 @@ type LogStream struct {
 	mu         sync.Mutex
 }
 
+// machineIDPath holds the systemd machine ID used to identify the device
+const machineIDPath = "/etc/machine-id"
+
+// DeviceIdentity identifies the device that log lines come from
+type DeviceIdentity struct {
+	MachineID string
+	Hostname  string
+}
+
 // LogStreamer manages log streams
 type LogStreamer struct {
-	streams map[string]*LogStream
-	mu      sync.Mutex
-	logger  *Logger
+	streams  map[string]*LogStream
+	mu       sync.Mutex
+	logger   *Logger
+	identity DeviceIdentity
 }
 
 // NewLogStreamer creates a new log streamer
 func NewLogStreamer() *LogStreamer {
 	return &LogStreamer{
-		streams: make(map[string]*LogStream),
-		logger:  NewLogger("LogStreamer"),
+		streams:  make(map[string]*LogStream),
+		logger:   NewLogger("LogStreamer"),
+		identity: readDeviceIdentity(),
 	}
 }
 
+// readDeviceIdentity reads the machine ID and hostname once at startup.
+// Either may be empty if unavailable.
+func readDeviceIdentity() DeviceIdentity {
+	var identity DeviceIdentity
+	if data, err := os.ReadFile(machineIDPath); err == nil {
+		identity.MachineID = strings.TrimSpace(string(data))
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		identity.Hostname = hostname
+	}
+	return identity
+}
+
+// Identity returns the device identity attached to streamed log lines
+func (l *LogStreamer) Identity() DeviceIdentity {
+	return l.identity
+}
+
 // StartJournalctlStream starts streaming all journalctl logs
 func (l *LogStreamer) StartJournalctlStream(streamID string, callback LogCallback) error {
+	return l.StartJournalctlStreamWithFormat(streamID, defaultJournalFormat, callback)
+}
+
+// StartJournalctlStreamWithFormat starts streaming all journalctl logs using
+// the given output format (see ValidateJournalFormat)
+func (l *LogStreamer) StartJournalctlStreamWithFormat(streamID, format string, callback LogCallback) error {
+	format, err := ValidateJournalFormat(format)
+	if err != nil {
+		return err
+	}
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -74,7 +145,7 @@ func (l *LogStreamer) StartJournalctlStream(streamID string, callback LogCallbac
 	l.logger.Info("Starting journalctl stream: %s", streamID)
 
 	// -n + -f: print recent history then follow (plain -f only shows new entries after start)
-	cmd := exec.Command("journalctl", "-n", fmt.Sprintf("%d", journalHistoryLines), "-f", "--no-pager", "-o", "short-precise")
+	cmd := exec.Command("journalctl", "-n", fmt.Sprintf("%d", journalHistoryLines), "-f", "--no-pager", "-o", format)
 
 	// Create the stream
 	stream := &LogStream{
@@ -96,6 +167,17 @@ func (l *LogStreamer) StartJournalctlStream(streamID string, callback LogCallbac
 
 // StartServiceStream starts streaming logs for a specific systemd service
 func (l *LogStreamer) StartServiceStream(streamID, serviceName string, callback LogCallback) error {
+	return l.StartServiceStreamWithFormat(streamID, serviceName, defaultJournalFormat, callback)
+}
+
+// StartServiceStreamWithFormat starts streaming logs for a specific systemd
+// service using the given output format (see ValidateJournalFormat)
+func (l *LogStreamer) StartServiceStreamWithFormat(streamID, serviceName, format string, callback LogCallback) error {
+	format, err := ValidateJournalFormat(format)
+	if err != nil {
+		return err
+	}
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -105,7 +187,7 @@ func (l *LogStreamer) StartServiceStream(streamID, serviceName string, callback
 
 	l.logger.Info("Starting service stream: %s for %s", streamID, serviceName)
 
-	cmd := exec.Command("journalctl", "-n", fmt.Sprintf("%d", journalHistoryLines), "-f", "--no-pager", "-u", serviceName, "-o", "short-precise")
+	cmd := exec.Command("journalctl", "-n", fmt.Sprintf("%d", journalHistoryLines), "-f", "--no-pager", "-u", serviceName, "-o", format)
 
 	// Create the stream
 	stream := &LogStream{
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestValidateJournalFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"", defaultJournalFormat, false},
+		{"short", "short", false},
+		{"short-iso-precise", "short-iso-precise", false},
+		{"json", "json", false},
+		{"cat", "cat", false},
+		// Anything outside the allowlist is rejected, including near misses
+		{"export", "", true},
+		{"JSON", "", true},
+		{" short", "", true},
+		{"json --since=today", "", true},
+		{"-o", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ValidateJournalFormat(tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ValidateJournalFormat(%q): expected error %v, got %v", tt.format, tt.wantErr, err)
+		}
+		if got != tt.want {
+			t.Fatalf("ValidateJournalFormat(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
@@ -32,14 +32,41 @@ func main() {
 	logger.Info("Starting Strux Client (v%s)...", Version)
 
 	markCurrentBootGood(logger)
+	detectStorageLayout(logger)
+
+	diag := BootDiagnosticsInstance
+	HealthReporterInstance.Start()
+	RecentLogsInstance.Start()
+
+	mode, err := ParseBootMode(os.Getenv(modeEnvVar))
+	if err != nil {
+		logger.Warn("%v, using auto", err)
+	}
+	if mode != BootModeAuto {
+		logger.Info("Boot mode forced to %s by %s", mode, modeEnvVar)
+	}
+
+	// Forced dev mode must not silently turn into production
+	failDevMode := func(reason string) {
+		logger.Error("Dev mode is required by %s but failed: %s", modeEnvVar, reason)
+		diag.Finish("failed", reason)
+		os.Exit(1)
+	}
+
+	if mode == BootModeDev && !fileExists("/strux/.dev-env.json") {
+		failDevMode("no dev config at /strux/.dev-env.json")
+	}
 
 	// Check if dev mode config file exists
-	if !fileExists("/strux/.dev-env.json") {
+	if mode == BootModeProduction || !fileExists("/strux/.dev-env.json") {
 		logger.Info("Production mode: Launching Cage and Cog")
 		if err := launchProduction(); err != nil {
 			logger.Error("Failed to launch production mode: %v", err)
+			diag.Finish("production", "launch failed: "+err.Error())
 			os.Exit(1)
 		}
+		diag.Finish("production", "")
+		diag.Ready("production", nil, CageLauncherInstance.LastLaunch())
 		waitForShutdown()
 		return
 	}
@@ -50,12 +77,19 @@ func main() {
 	config, err := LoadConfig("/strux/.dev-env.json")
 	if err != nil {
 		logger.Error("Error reading config: %v", err)
+		if mode == BootModeDev {
+			failDevMode("invalid dev config: " + err.Error())
+		}
 		logger.Warn("Running in production mode")
-		launchProduction()
+		launchAndReportProduction("invalid dev config: " + err.Error())
 		waitForShutdown()
 		return
 	}
 
+	if config.Profile != "" {
+		logger.Info("Using dev config profile: %s", config.Profile)
+	}
+
 	cage := CageLauncherInstance
 	displayConfig, _ := loadDisplaySettings()
 	devStatusCageStarted := false
@@ -76,6 +110,23 @@ func main() {
 			usbManager.Cleanup(usbNetConfig)
 		}
 	}()
+
+	// fallBackToProduction abandons dev mode and runs the app in production
+	// mode until shutdown. When dev mode is forced, the client exits instead.
+	fallBackToProduction := func(reason string) {
+		if devStatusCageStarted {
+			cage.Cleanup()
+		}
+		if mode == BootModeDev {
+			if usbDevEnabled {
+				usbManager.Cleanup(usbNetConfig)
+			}
+			failDevMode(reason)
+		}
+		logger.Warn("Falling back to production mode")
+		launchAndReportProduction(reason)
+		waitForShutdown()
+	}
 	if config.USB.IsEnabled() {
 		logger.Info("Configuring USB debug Ethernet...")
 		var err error
@@ -90,30 +141,35 @@ func main() {
 	} else {
 		logger.Info("USB debug Ethernet disabled by config")
 	}
+	diag.SetDevConfig(true, usbDevEnabled)
 
 	// Discover hosts
 	logger.Info("Discovering dev server hosts...")
 	hosts := DiscoverHosts(config)
+	diag.SetHostsFound(hosts)
 
 	if len(hosts) == 0 {
 		logger.Error("No hosts found")
-		logger.Warn("Falling back to production mode")
-		if devStatusCageStarted {
-			cage.Cleanup()
-		}
-		launchProduction()
-		waitForShutdown()
+		fallBackToProduction("no hosts found")
 		return
 	}
 
 	// Attempt to connect via WebSocket
 	logger.Info("Attempting to connect to dev server via WebSocket...")
 	socket := NewSocketClient(config.ClientKey)
+	socket.SetLogBuffering(config.Logs)
+	socket.SetHosts(hosts)
+	BinaryHandlerInstance.SetRebootDelay(config.RebootDelay())
+	ClientBinaryHandlerInstance.SetRebootDelay(config.RebootDelay())
+	HealthReporterInstance.SetSocket(socket)
 
 	connected := false
 	var connectedHost Host
 	for _, host := range hosts {
-		if err := socket.Connect(host); err == nil {
+		attemptStart := time.Now()
+		err := socket.Connect(host)
+		diag.RecordConnectAttempt(host, time.Since(attemptStart), err)
+		if err == nil {
 			connected = true
 			connectedHost = host
 			break
@@ -123,24 +179,20 @@ func main() {
 
 	if !connected {
 		logger.Error("Failed to connect to any dev server")
-		logger.Warn("Falling back to production mode")
-		if devStatusCageStarted {
-			cage.Cleanup()
-		}
-		launchProduction()
-		waitForShutdown()
+		fallBackToProduction("failed to connect to any dev server")
 		return
 	}
 
 	logger.Info("WebSocket connected to %s:%d", connectedHost.Host, connectedHost.Port)
 
 	// Determine Cog URL - use discovered host but port 5173 (Vite dev server)
-	cogURL := "http://" + connectedHost.Host + ":5173"
+	cogURL := devServerURL(connectedHost)
 	logger.Info("Using dev server URL: %s", cogURL)
 
 	// Try to connect to dev server immediately (with short timeout)
 	// If it fails, then wait for network readiness and retry
 	logger.Info("Attempting to connect to dev server immediately...")
+	networkStart := time.Now()
 	devServerReady := cage.WaitForDevServer(cogURL, 30*time.Second)
 
 	if !devServerReady {
@@ -148,13 +200,9 @@ func main() {
 			logger.Info("USB dev server not immediately reachable, retrying without requiring a default route...")
 			time.Sleep(1 * time.Second)
 			if !waitForUSBDevServer(cage, cogURL, 30*time.Second) {
-				logger.Error("USB dev server not reachable, falling back to production mode")
+				logger.Error("USB dev server not reachable")
 				socket.Disconnect()
-				if devStatusCageStarted {
-					cage.Cleanup()
-				}
-				launchProduction()
-				waitForShutdown()
+				fallBackToProduction("USB dev server not reachable")
 				return
 			}
 		} else {
@@ -162,13 +210,9 @@ func main() {
 			// Cog needs network to load the URL, and WebKit Inspector needs it to bind to 0.0.0.0
 			logger.Info("Dev server not immediately reachable, waiting for network interface to be ready...")
 			if !cage.WaitForNetworkReady(30 * time.Second) {
-				logger.Error("Network interface not ready, falling back to production mode")
+				logger.Error("Network interface not ready")
 				socket.Disconnect()
-				if devStatusCageStarted {
-					cage.Cleanup()
-				}
-				launchProduction()
-				waitForShutdown()
+				fallBackToProduction("network interface not ready")
 				return
 			}
 
@@ -179,18 +223,16 @@ func main() {
 			// Now retry connecting to dev server
 			logger.Info("Retrying connection to dev server...")
 			if !cage.WaitForDevServer(cogURL, 30*time.Second) {
-				logger.Error("Dev server not reachable after network ready, falling back to production mode")
+				logger.Error("Dev server not reachable after network ready")
 				socket.Disconnect()
-				if devStatusCageStarted {
-					cage.Cleanup()
-				}
-				launchProduction()
-				waitForShutdown()
+				fallBackToProduction("dev server not reachable after network ready")
 				return
 			}
 		}
 	}
 
+	diag.RecordNetworkReady(time.Since(networkStart))
+
 	// Ensure network is ready for WebKit Inspector (if enabled)
 	// This is critical for binding to 0.0.0.0
 	if config.Inspector.Enabled {
@@ -210,19 +252,25 @@ func main() {
 
 	if devStatusCageStarted {
 		cage.Cleanup()
+		devStatusCageStarted = false
 	}
 
 	// Launch Cage and Cog with inspector if enabled
 	if err := launchDevMode(cogURL, &config.Inspector); err != nil {
 		logger.Error("Failed to launch dev mode: %v", err)
 		socket.Disconnect()
-		launchProduction()
+		fallBackToProduction("failed to launch dev mode: " + err.Error())
+		return
 	}
 
 	logger.Info("Dev client connected and ready")
+	diag.Finish("dev", "")
 
 	// Report device info (IP + inspector ports + outputs) to the dev server
 	sendDeviceInfo(socket, &config.Inspector, displayConfig)
+	if summary, ok := diag.Ready("dev", &connectedHost, cage.LastLaunch()); ok {
+		socket.SendReady(summary)
+	}
 
 	// Re-send device info on reconnect and when server explicitly requests it
 	resendInfo := func() {
@@ -231,6 +279,20 @@ func main() {
 	socket.onReconnect = resendInfo
 	socket.onDeviceInfoReq = resendInfo
 
+	// Follow the dev server when a reconnect lands on a different host
+	socket.onHostChanged = func(host Host) {
+		if err := CageLauncherInstance.Navigate(devServerURL(host)); err != nil {
+			logger.Error("Failed to navigate Cog to the new dev server: %v", err)
+		}
+	}
+
+	// Reload Cog when the dev server asks (debounced by the socket client)
+	socket.onReload = func() {
+		if err := CageLauncherInstance.Reload(); err != nil {
+			logger.Error("Failed to reload Cog: %v", err)
+		}
+	}
+
 	// Wait for shutdown signal
 	waitForShutdown()
 
@@ -239,6 +301,11 @@ func main() {
 	CageLauncherInstance.Cleanup()
 }
 
+// devServerURL returns the Vite dev server URL on a dev server host
+func devServerURL(host Host) string {
+	return "http://" + host.Host + ":5173"
+}
+
 func writeDevConnectImage() error {
 	return os.WriteFile(devConnectImagePath, devConnectImage, 0644)
 }
@@ -250,6 +317,8 @@ func launchDevConnectionStatus(displayConfig *DisplayConfig) error {
 	return CageLauncherInstance.Launch(LaunchOptions{
 		OnlyDisplayImage: devConnectImagePath,
 		DisplayConfig:    displayConfig,
+		WebExtensionsDir: displayConfigWebExtensionsDir(displayConfig),
+		WebKitEnv:        displayConfigWebKitEnv(displayConfig),
 	})
 }
 
@@ -289,63 +358,91 @@ func markCurrentBootGood(logger *Logger) {
 	}
 }
 
+// productionURLTimeout is how long each configured production URL gets to
+// respond before the next one is tried
+const productionURLTimeout = 10 * time.Second
+
 // launchProduction launches Cage with production settings
 func launchProduction() error {
 	logger := NewLogger("Production")
 
 	displayConfig, resolution := loadDisplaySettings()
 
-	// Check for splash image
-	splashImage := ""
-	if fileExists("/strux/logo.png") {
-		splashImage = "/strux/logo.png"
-	}
+	// Pick the first splash image that exists for this display
+	splashImage, splashColor := splashSettings(displayConfig, resolution)
 
 	// Wait for backend to be ready
 	cage := CageLauncherInstance
+	backendStart := time.Now()
 	if !cage.WaitForBackend(60 * time.Second) {
 		return ErrBackendNotReady
 	}
+	BootDiagnosticsInstance.RecordBackendReady(time.Since(backendStart))
+
+	cogURL := localFrontendURL
+	if displayConfig != nil && len(displayConfig.URLs) > 0 {
+		// Remote URLs need the network; if it never comes up they are
+		// skipped as unreachable
+		cage.WaitForNetworkReady(30 * time.Second)
+		cogURL = cage.ProductionURL(displayConfig.URLs, productionURLTimeout)
+	}
 
-	logger.Info("Launching with resolution: %s", resolution)
+	logger.Info("Launching %s with resolution: %s", cogURL, resolution)
 
-	// Launch Cage with backend URL (no inspector in production)
+	// Launch Cage with the chosen URL (no inspector in production)
 	return cage.Launch(LaunchOptions{
-		CogURL:        "http://localhost:8080",
-		Resolution:    resolution,
-		SplashImage:   splashImage,
-		Inspector:     nil,
-		DisplayConfig: displayConfig,
+		CogURL:           cogURL,
+		Resolution:       resolution,
+		SplashImage:      splashImage,
+		SplashColor:      splashColor,
+		Inspector:        nil,
+		DisplayConfig:    displayConfig,
+		WebExtensionsDir: displayConfigWebExtensionsDir(displayConfig),
+		WebKitEnv:        displayConfigWebKitEnv(displayConfig),
 	})
 }
 
+// launchAndReportProduction launches production mode after dev mode was
+// abandoned for reason, and records the outcome
+func launchAndReportProduction(reason string) {
+	err := launchProduction()
+	BootDiagnosticsInstance.Finish("production", reason)
+	if err != nil {
+		NewLogger("Production").Error("Failed to launch production mode: %v", err)
+		return
+	}
+	BootDiagnosticsInstance.Ready("production", nil, CageLauncherInstance.LastLaunch())
+}
+
 // launchDevMode launches Cage in dev mode with the specified URL
 func launchDevMode(cogURL string, inspector *InspectorConfig) error {
 	logger := NewLogger("DevMode")
 
 	displayConfig, resolution := loadDisplaySettings()
 
-	// Check for splash image
-	splashImage := ""
-	if fileExists("/strux/logo.png") {
-		splashImage = "/strux/logo.png"
-	}
+	// Pick the first splash image that exists for this display
+	splashImage, splashColor := splashSettings(displayConfig, resolution)
 
 	// Wait for backend
 	cage := CageLauncherInstance
+	backendStart := time.Now()
 	if !cage.WaitForBackend(60 * time.Second) {
 		return ErrBackendNotReady
 	}
+	BootDiagnosticsInstance.RecordBackendReady(time.Since(backendStart))
 
 	logger.Info("Launching with resolution: %s", resolution)
 
 	// Launch Cage with inspector if enabled
 	return cage.Launch(LaunchOptions{
-		CogURL:        cogURL,
-		Resolution:    resolution,
-		SplashImage:   splashImage,
-		Inspector:     inspector,
-		DisplayConfig: displayConfig,
+		CogURL:           cogURL,
+		Resolution:       resolution,
+		SplashImage:      splashImage,
+		SplashColor:      splashColor,
+		Inspector:        inspector,
+		DisplayConfig:    displayConfig,
+		WebExtensionsDir: displayConfigWebExtensionsDir(displayConfig),
+		WebKitEnv:        displayConfigWebKitEnv(displayConfig),
 	})
 }
 
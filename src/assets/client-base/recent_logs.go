@@ -0,0 +1,97 @@
+//
+// Strux Client - Recent Logs
+//
+// Keeps the last few hundred client log lines in memory and mirrors them to
+// /run/strux/client-logs.json, so the Go runtime can serve
+// strux.system.RecentLogs() to an in-app diagnostics panel without journald.
+//
+
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	recentLogsPath          = "/run/strux/client-logs.json"
+	recentLogsCapacity      = 500
+	recentLogsWriteInterval = 2 * time.Second
+)
+
+// RecentLogs is a bounded ring buffer of formatted log lines
+type RecentLogs struct {
+	mu      sync.Mutex
+	lines   []string
+	next    int  // Index the next line is written to once the buffer is full
+	dirty   bool // Lines were added since the last write
+	started bool
+}
+
+// RecentLogsInstance is the global recent log buffer fed by Logger
+var RecentLogsInstance = &RecentLogs{
+	lines: make([]string, 0, recentLogsCapacity),
+}
+
+// Append adds a line, dropping the oldest once the buffer is full
+func (r *RecentLogs) Append(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.lines) < recentLogsCapacity {
+		r.lines = append(r.lines, line)
+	} else {
+		r.lines[r.next] = line
+		r.next = (r.next + 1) % recentLogsCapacity
+	}
+	r.dirty = true
+}
+
+// Lines returns the buffered lines, oldest first
+func (r *RecentLogs) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lines := make([]string, 0, len(r.lines))
+	lines = append(lines, r.lines[r.next:]...)
+	lines = append(lines, r.lines[:r.next]...)
+	return lines
+}
+
+// Start begins mirroring the buffer to the recent logs file. Calling it more
+// than once has no effect.
+func (r *RecentLogs) Start() {
+	r.mu.Lock()
+	if r.started {
+		r.mu.Unlock()
+		return
+	}
+	r.started = true
+	r.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(recentLogsWriteInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			r.write()
+		}
+	}()
+}
+
+func (r *RecentLogs) write() {
+	r.mu.Lock()
+	dirty := r.dirty
+	r.dirty = false
+	r.mu.Unlock()
+	if !dirty {
+		return
+	}
+
+	data, err := json.Marshal(r.Lines())
+	if err != nil {
+		return
+	}
+	// Failures aren't logged: logging here would mark the buffer dirty again
+	_ = writeStateFile(recentLogsPath, append(data, '\n'), 0644)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecentLogsKeepsNewestLines(t *testing.T) {
+	logs := &RecentLogs{}
+	total := recentLogsCapacity + 25
+	for i := 0; i < total; i++ {
+		logs.Append(fmt.Sprintf("line %d", i))
+	}
+
+	lines := logs.Lines()
+	if len(lines) != recentLogsCapacity {
+		t.Fatalf("expected %d lines, got %d", recentLogsCapacity, len(lines))
+	}
+	if lines[0] != "line 25" {
+		t.Fatalf("expected oldest kept line to be %q, got %q", "line 25", lines[0])
+	}
+	if want := fmt.Sprintf("line %d", total-1); lines[len(lines)-1] != want {
+		t.Fatalf("expected newest line %q, got %q", want, lines[len(lines)-1])
+	}
+}
@@ -7,9 +7,13 @@
 // Message types (aligned with ndev/types.ts):
 //
 // Server → Client:
-//   - "binary-new"           { data: string }
+//   - "binary-new"           { data: string, buildTimestamp?: number, force?: boolean }
+//   - "binary-reboot-cancel"
+//   - "new-client-binary"    { data: string, buildTimestamp?: number, force?: boolean }
 //   - "component"            { data: string, destPath: string }
 //   - "device-info-requested"
+//   - "start-logs"           { streamId, type, service?, outputFormat? }
+//   - "stop-logs"            { streamId }
 //   - "ssh-start"            { sessionID: string, shell: string }
 //   - "ssh-input"            { sessionID: string, data: string }
 //   - "ssh-exit"             { sessionID: string }
@@ -18,15 +22,20 @@
 //   - "system-update"         { url?: string, path?: string }
 //   - "screen-request"       { outputName, serverHostURL }
 //   - "screen-picture"       { outputName }
+//   - "reload"               (bursts within reloadDebounce reload Cog once)
 //
 // Client → Server:
 //   - "binary-requested"
-//   - "binary-ack"           { status, binary, currentChecksum?, receivedChecksum? }
+//   - "binary-ack"           { status, binary, currentChecksum?, receivedChecksum?, reason? } (binary is /strux/client for new-client-binary)
+//   - "binary-timing"        { status, bytes, decodeMs, writeMs, verifyMs, totalMs, rebootScheduledMs? }
 //   - "component-ack"        { status, message, destPath }
 //   - "system-update-ack"    { status, message, slot?, version? }
 //   - "update-progress"      { status, progress, message?, bytesWritten?, totalBytes?, slot?, version? }
 //   - "device-info"          { ip, inspectorPorts, outputs? }
-//   - "log-line"             { type, line, timestamp }
+//   - "ready"                { mode, host?, resolution, splashImage?, splashColor?, backendUrl, inspector, inspectorPort?, bootMs }
+//   - "log-line"             { type, line, timestamp, machineId?, hostname? }
+//   - "log-stream-error"     { streamId, error }
+//   - "streams-resumed"      { streams: [{ streamId, type }] }
 //   - "ssh-output"           { sessionID, data }
 //   - "ssh-exit-received"    { sessionID, code }
 //   - "screen-picture-received" { outputName, data, width, height }
@@ -42,9 +51,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -68,7 +81,9 @@ func describeExtractedFrontend(frontendPath string) string {
 
 // BinaryPayload represents the payload for binary updates
 type BinaryPayload struct {
-	Data string `json:"data"` // Base64 encoded binary data
+	Data           string `json:"data"`                     // Base64 encoded binary data
+	BuildTimestamp int64  `json:"buildTimestamp,omitempty"` // Unix build time; read from the binary if omitted
+	Force          bool   `json:"force,omitempty"`          // Install even if the binary is older than the current one
 }
 
 // LogLinePayload represents a log line to send to the server
@@ -76,6 +91,40 @@ type LogLinePayload struct {
 	Type      string `json:"type"` // "journalctl", "service", "app", "cage", "screen", "early", "client"
 	Line      string `json:"line"`
 	Timestamp string `json:"timestamp"`
+	MachineID string `json:"machineId,omitempty"` // From /etc/machine-id
+	Hostname  string `json:"hostname,omitempty"`
+}
+
+// StartLogsPayload asks the client to start a log stream
+type StartLogsPayload struct {
+	StreamID     string `json:"streamId"`
+	Type         string `json:"type"` // "journalctl", "service", "app", "cage", "early"
+	Service      string `json:"service,omitempty"`
+	OutputFormat string `json:"outputFormat,omitempty"` // journalctl -o format, default "short-precise"
+}
+
+// StopLogsPayload asks the client to stop a log stream
+type StopLogsPayload struct {
+	StreamID string `json:"streamId"`
+}
+
+// LogStreamErrorPayload reports a log stream that could not be started
+type LogStreamErrorPayload struct {
+	StreamID string `json:"streamId"`
+	Error    string `json:"error"`
+}
+
+// StreamsResumedPayload lists the log streams running after a reconnect, so
+// the server can restart any it still wants (start-logs) and drop the rest
+// (stop-logs)
+type StreamsResumedPayload struct {
+	Streams []ResumedStream `json:"streams"`
+}
+
+// ResumedStream is a log stream that is running after a reconnect
+type ResumedStream struct {
+	StreamID string `json:"streamId"`
+	Type     string `json:"type"`
 }
 
 // SSHStartPayload starts an interactive shell session
@@ -113,10 +162,23 @@ type SSHExitReceivedPayload struct {
 
 // BinaryAckPayload represents the acknowledgment of a binary update
 type BinaryAckPayload struct {
-	Status           string `json:"status"`                     // "skipped", "updated", "error"
+	Status           string `json:"status"`                     // "skipped", "updated", "busy", "error"
 	Binary           string `json:"binary"`                     // Binary name/path
 	CurrentChecksum  string `json:"currentChecksum,omitempty"`  // Checksum of current binary on disk
 	ReceivedChecksum string `json:"receivedChecksum,omitempty"` // Checksum of received binary
+	Reason           string `json:"reason,omitempty"`           // Machine-readable reason for a skip, e.g. "DOWNGRADE"
+}
+
+// BinaryTimingPayload reports the duration of each stage of a binary push,
+// measured from receipt of the binary-new event
+type BinaryTimingPayload struct {
+	Status            string `json:"status"`                      // Same as the binary-ack status
+	Bytes             int    `json:"bytes"`                       // Decoded binary size
+	DecodeMs          int64  `json:"decodeMs"`                    // Base64 decoding
+	WriteMs           int64  `json:"writeMs"`                     // Writing the temp file and renaming it into place
+	VerifyMs          int64  `json:"verifyMs"`                    // Reading back and checksumming the temp file
+	TotalMs           int64  `json:"totalMs"`                     // Receipt until the ack was sent
+	RebootScheduledMs *int64 `json:"rebootScheduledMs,omitempty"` // Receipt until the reboot was scheduled, if one was
 }
 
 // ComponentPayload represents a component file update from the server
@@ -190,11 +252,64 @@ type SocketClient struct {
 	connected       bool
 	hasConnected    bool // true after first successful connection (to detect reconnections)
 	host            Host
+	hosts           []Host // discovered hosts to fall back to when reconnecting
 	logStreams      *LogStreamer
 	exec            *ExecManager
 	screen          *ScreenManager
-	onReconnect     func() // called on reconnection so main.go can re-send device info
-	onDeviceInfoReq func() // called when server requests device info
+	onReconnect     func()     // called on reconnection so main.go can re-send device info
+	onDeviceInfoReq func()     // called when server requests device info
+	onHostChanged   func(Host) // called when a reconnect lands on a different host
+	onReload        func()     // reloads Cog; called once per burst of reload requests
+
+	reloadDelay time.Duration
+	reloadTimer *time.Timer // Non-nil while a reload is pending
+
+	// Re-subscription state. A new connection starts with fresh server-side
+	// state, so hooks re-issue whatever the server needs after a reconnect.
+	resubscribeHooks []func()
+	logSubs          map[string]logSubscription // stream ID -> how to restart it
+	resumeLogSubs    []logSubscription          // streams active when the connection dropped
+
+	// Holds log lines while disconnected; nil unless log buffering is enabled,
+	// in which case log streams keep running across disconnects
+	logBuffer *LogBuffer
+}
+
+// reloadDebounce is how long a reload request waits for more to arrive, so a
+// burst of change notifications from the bundler reloads Cog once
+const reloadDebounce = 200 * time.Millisecond
+
+// requestReload schedules a Cog reload after reloadDelay. Requests arriving
+// while one is pending restart the wait and are coalesced into it.
+func (s *SocketClient) requestReload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reloadTimer != nil {
+		s.reloadTimer.Stop()
+	}
+	s.reloadTimer = time.AfterFunc(s.reloadDelay, s.reload)
+}
+
+// reload runs the pending reload
+func (s *SocketClient) reload() {
+	s.mu.Lock()
+	s.reloadTimer = nil
+	onReload := s.onReload
+	s.mu.Unlock()
+
+	if onReload == nil {
+		s.logger.Warn("Reload requested but no reload handler is set")
+		return
+	}
+	onReload()
+}
+
+// logSubscription describes a log stream so it can be restarted after reconnect
+type logSubscription struct {
+	streamID string
+	logType  string
+	start    func(streamID string, callback LogCallback) error
 }
 
 // NewSocketClient creates a new WebSocket client
@@ -203,8 +318,15 @@ func NewSocketClient(clientKey string) *SocketClient {
 		clientKey:  clientKey,
 		logger:     NewLogger("SocketClient"),
 		logStreams: NewLogStreamer(),
+		logSubs:    make(map[string]logSubscription),
+
+		reloadDelay: reloadDebounce,
 	}
 
+	// Restore log streams and re-request the binary on every reconnect
+	client.OnResubscribe(client.restoreLogStreams)
+	client.OnResubscribe(client.RequestBinary)
+
 	client.exec = NewExecManager(
 		func(sessionID, data string) {
 			client.SendSSHOutput(sessionID, data)
@@ -248,35 +370,61 @@ func (s *SocketClient) Connect(host Host) error {
 		ws.SetQueryParam("key", s.clientKey)
 	}
 
+	// Reconnects fall back to the other discovered hosts
+	var alternatives []string
+	for _, h := range s.hosts {
+		alternatives = append(alternatives, hostWebSocketURL(h))
+	}
+	ws.SetReconnectURLs(alternatives)
+
 	// Set up connection lifecycle callbacks
 	ws.OnConnect(func() {
 		s.mu.Lock()
 		reconnecting := s.hasConnected
+		previous := s.host
+		if current, ok := s.hostForURL(ws.URL()); ok {
+			s.host = current
+		}
+		hostChanged := reconnecting && s.host.Host != previous.Host
 		s.connected = true
 		s.hasConnected = true
 		s.mu.Unlock()
 		s.logger.Info("WebSocket connected")
 
-		// Auto-start log streams on every connect
-		s.startAutoLogStreams()
-
-		// On reconnection, re-request binary
 		if reconnecting {
+			// Re-issue server-side state lost with the old connection
 			s.logger.Info("Re-initializing after reconnection...")
-			s.RequestBinary()
+			s.mu.Lock()
+			hooks := append([]func(){}, s.resubscribeHooks...)
+			s.mu.Unlock()
+			for _, hook := range hooks {
+				hook()
+			}
+		} else {
+			s.startAutoLogStreams()
 		}
 		// Always notify so main.go can (re-)send device info
 		if s.onReconnect != nil {
 			s.onReconnect()
 		}
+		if hostChanged && s.onHostChanged != nil {
+			s.logger.Info("Reconnected to a different host: %s:%d", s.host.Host, s.host.Port)
+			s.onHostChanged(s.GetHost())
+		}
 	})
 
 	ws.OnDisconnect(func() {
 		s.mu.Lock()
 		s.connected = false
+		buffering := s.logBuffer != nil
 		s.mu.Unlock()
 		s.logger.Warn("WebSocket disconnected")
-		s.logStreams.StopAll()
+		if buffering {
+			s.logger.Info("Buffering log lines until the connection is back")
+		} else {
+			s.snapshotLogStreams()
+			s.logStreams.StopAll()
+		}
 		s.screen.StopAll()
 	})
 
@@ -307,6 +455,33 @@ func (s *SocketClient) Connect(host Host) error {
 	return nil
 }
 
+// SetHosts records the discovered dev server hosts. Connections made after
+// this fall back to the other hosts when reconnecting to the current one fails.
+func (s *SocketClient) SetHosts(hosts []Host) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hosts = hosts
+}
+
+// hostWebSocketURL returns the URL of a host's client endpoint
+func hostWebSocketURL(host Host) string {
+	return fmt.Sprintf("ws://%s:%d/client", host.Host, host.Port)
+}
+
+// hostForURL returns the known host a WebSocket URL points at
+func (s *SocketClient) hostForURL(rawURL string) (Host, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Host{}, false
+	}
+	for _, h := range append([]Host{s.host}, s.hosts...) {
+		if u.Host == net.JoinHostPort(h.Host, strconv.Itoa(h.Port)) {
+			return h, true
+		}
+	}
+	return Host{}, false
+}
+
 // setupEventHandlers registers all WebSocket event handlers
 func (s *SocketClient) setupEventHandlers(ws *WSClient) {
 
@@ -317,7 +492,50 @@ func (s *SocketClient) setupEventHandlers(ws *WSClient) {
 			s.logger.Error("Failed to parse binary-new payload: %v", err)
 			return
 		}
-		s.handleBinaryUpdate(binaryPayload)
+		s.handleBinaryUpdate(BinaryHandlerInstance, binaryPayload)
+	})
+
+	// Handle client binary updates (the Strux client itself, not the app)
+	ws.On("new-client-binary", func(payload json.RawMessage) {
+		var binaryPayload BinaryPayload
+		if err := json.Unmarshal(payload, &binaryPayload); err != nil {
+			s.logger.Error("Failed to parse new-client-binary payload: %v", err)
+			return
+		}
+		s.handleBinaryUpdate(ClientBinaryHandlerInstance, binaryPayload)
+	})
+
+	// Handle binary-reboot-cancel event (abort the reboot following an app or
+	// client binary update)
+	ws.On("binary-reboot-cancel", func(payload json.RawMessage) {
+		appCancelled := BinaryHandlerInstance.CancelPendingReboot()
+		clientCancelled := ClientBinaryHandlerInstance.CancelPendingReboot()
+		if !appCancelled && !clientCancelled {
+			s.logger.Info("No pending reboot to cancel")
+		}
+	})
+
+	// Handle start-logs event (server-requested log stream)
+	ws.On("start-logs", func(payload json.RawMessage) {
+		var logsPayload StartLogsPayload
+		if err := json.Unmarshal(payload, &logsPayload); err != nil {
+			s.logger.Error("Failed to parse start-logs payload: %v", err)
+			return
+		}
+		s.handleStartLogs(logsPayload)
+	})
+
+	// Handle stop-logs event
+	ws.On("stop-logs", func(payload json.RawMessage) {
+		var logsPayload StopLogsPayload
+		if err := json.Unmarshal(payload, &logsPayload); err != nil {
+			s.logger.Error("Failed to parse stop-logs payload: %v", err)
+			return
+		}
+		s.logStreams.Stop(logsPayload.StreamID)
+		s.mu.Lock()
+		delete(s.logSubs, logsPayload.StreamID)
+		s.mu.Unlock()
 	})
 
 	// Handle ssh-start event
@@ -412,6 +630,11 @@ func (s *SocketClient) setupEventHandlers(ws *WSClient) {
 		}
 	})
 
+	// Handle reload event (the dev server asking Cog to reload the page)
+	ws.On("reload", func(payload json.RawMessage) {
+		s.requestReload()
+	})
+
 	// Handle device-info-requested from server
 	ws.On("device-info-requested", func(payload json.RawMessage) {
 		s.logger.Info("Server requested device info")
@@ -471,6 +694,18 @@ func (s *SocketClient) GetHost() Host {
 	return s.host
 }
 
+// LastRTT returns the round-trip time of the most recent WebSocket ping, or 0
+// if none has completed yet
+func (s *SocketClient) LastRTT() time.Duration {
+	s.mu.Lock()
+	ws := s.ws
+	s.mu.Unlock()
+	if ws == nil {
+		return 0
+	}
+	return ws.LastRTT()
+}
+
 // RequestBinary requests the current binary from the server
 func (s *SocketClient) RequestBinary() {
 	if s.ws == nil {
@@ -485,34 +720,113 @@ func (s *SocketClient) RequestBinary() {
 	}
 }
 
-// SendLogLine sends a log line to the server
+// SendLogLine sends a log line to the server. With log buffering enabled,
+// lines are buffered while disconnected instead of being dropped.
 func (s *SocketClient) SendLogLine(logType, line string) {
 	if s.ws == nil {
 		return
 	}
 
+	identity := s.logStreams.Identity()
 	payload := LogLinePayload{
 		Type:      logType,
 		Line:      line,
 		Timestamp: time.Now().Format(time.RFC3339),
+		MachineID: identity.MachineID,
+		Hostname:  identity.Hostname,
 	}
 
+	s.mu.Lock()
+	buffer := s.logBuffer
+	connected := s.connected
+	s.mu.Unlock()
+
+	if buffer != nil && !connected {
+		buffer.Add(payload)
+		return
+	}
 	if err := s.ws.Emit("log-line", payload); err != nil {
+		if buffer != nil {
+			buffer.Add(payload)
+			return
+		}
 		s.logger.Error("Failed to send log line: %v", err)
 	}
 }
 
-// SendBinaryAck sends a binary update acknowledgment to the server
-func (s *SocketClient) SendBinaryAck(status, currentChecksum, receivedChecksum string) {
+// SetLogBuffering configures whether log streams survive disconnects. Call
+// before Connect.
+func (s *SocketClient) SetLogBuffering(config LogsConfig) {
+	if !config.BufferOnDisconnect {
+		return
+	}
+
+	s.mu.Lock()
+	s.logBuffer = NewLogBuffer(config.BufferLines)
+	s.mu.Unlock()
+
+	s.OnResubscribe(s.flushLogBuffer)
+}
+
+// flushLogBuffer sends the log lines buffered while disconnected
+func (s *SocketClient) flushLogBuffer() {
+	lines, dropped := s.logBuffer.Drain()
+	if dropped > 0 {
+		s.logger.Warn("Dropped %d log lines while disconnected", dropped)
+		s.SendLogLine("client", fmt.Sprintf("%d log lines were dropped while disconnected from the dev server", dropped))
+	}
+	for _, payload := range lines {
+		if err := s.ws.Emit("log-line", payload); err != nil {
+			s.logger.Error("Failed to send buffered log line: %v", err)
+			return
+		}
+	}
+	if len(lines) > 0 {
+		s.logger.Info("Sent %d log lines buffered while disconnected", len(lines))
+	}
+}
+
+// SendLogStreamError reports a log stream that could not be started
+func (s *SocketClient) SendLogStreamError(streamID, message string) {
+	s.logger.Warn("Log stream %s error: %s", streamID, message)
+	if s.ws == nil {
+		return
+	}
+
+	payload := LogStreamErrorPayload{
+		StreamID: streamID,
+		Error:    message,
+	}
+
+	if err := s.ws.Emit("log-stream-error", payload); err != nil {
+		s.logger.Error("Failed to send log stream error: %v", err)
+	}
+}
+
+// SendReady sends the startup summary to the server
+func (s *SocketClient) SendReady(summary ReadySummary) {
+	if s.ws == nil {
+		return
+	}
+
+	if err := s.ws.Emit("ready", summary); err != nil {
+		s.logger.Error("Failed to send startup summary: %v", err)
+	}
+}
+
+// SendBinaryAck sends a binary update acknowledgment to the server for the
+// binary installed at path
+func (s *SocketClient) SendBinaryAck(path, status, currentChecksum, receivedChecksum, reason string) {
 	if s.ws == nil {
 		return
 	}
 
 	payload := BinaryAckPayload{
 		Status:           status,
-		Binary:           binaryPath,
+		Binary:           path,
 		CurrentChecksum:  currentChecksum,
 		ReceivedChecksum: receivedChecksum,
+		Reason:           reason,
 	}
 
 	if err := s.ws.Emit("binary-ack", payload); err != nil {
@@ -520,6 +834,17 @@ func (s *SocketClient) SendBinaryAck(status, currentChecksum, receivedChecksum s
 	}
 }
 
+// SendBinaryTiming reports how long each stage of a binary push took
+func (s *SocketClient) SendBinaryTiming(payload BinaryTimingPayload) {
+	if s.ws == nil {
+		return
+	}
+
+	if err := s.ws.Emit("binary-timing", payload); err != nil {
+		s.logger.Error("Failed to send binary timing: %v", err)
+	}
+}
+
 // SendSSHOutput streams console output to the server
 func (s *SocketClient) SendSSHOutput(sessionID, data string) {
 	if s.ws == nil {
@@ -552,57 +877,229 @@ func (s *SocketClient) SendSSHExitReceived(sessionID string, code int) {
 	}
 }
 
-// handleBinaryUpdate handles a binary update from the server
-func (s *SocketClient) handleBinaryUpdate(binaryPayload BinaryPayload) {
-	s.logger.Info("Received binary update")
+// handleBinaryUpdate installs a binary update from the server with handler
+func (s *SocketClient) handleBinaryUpdate(handler *BinaryHandler, binaryPayload BinaryPayload) {
+	s.logger.Info("Received binary update for %s", handler.Path())
+	received := time.Now()
 
 	// Decode base64 data
 	decoded, err := base64.StdEncoding.DecodeString(binaryPayload.Data)
 	if err != nil {
 		s.logger.Error("Failed to decode binary data: %v", err)
-		s.SendBinaryAck("error", "", "")
+		s.SendBinaryAck(handler.Path(), "error", "", "", "")
 		return
 	}
+	decodeDuration := time.Since(received)
 
 	s.logger.Info("Decoded binary: %d bytes", len(decoded))
 
 	// Handle the binary update
-	result := BinaryHandlerInstance.HandleUpdate(decoded)
+	result := handler.HandleUpdate(decoded, binaryPayload.BuildTimestamp, binaryPayload.Force)
+	handledMs := time.Since(received).Milliseconds()
 
 	// Send acknowledgment to server
-	s.SendBinaryAck(result.Status, result.CurrentChecksum, result.ReceivedChecksum)
+	s.SendBinaryAck(handler.Path(), result.Status, result.CurrentChecksum, result.ReceivedChecksum, result.Reason)
+
+	timing := BinaryTimingPayload{
+		Status:   result.Status,
+		Bytes:    len(decoded),
+		DecodeMs: decodeDuration.Milliseconds(),
+		WriteMs:  result.WriteDuration.Milliseconds(),
+		VerifyMs: result.VerifyDuration.Milliseconds(),
+		TotalMs:  time.Since(received).Milliseconds(),
+	}
+	if result.RebootScheduled {
+		timing.RebootScheduledMs = &handledMs
+	}
+	s.SendBinaryTiming(timing)
 
 	if result.Status == "error" {
 		s.logger.Error("Binary update failed: %s", result.Message)
+	} else if result.Status == "busy" || result.Reason == "DOWNGRADE" {
+		s.logger.Warn("Binary update skipped: %s", result.Message)
+	}
+}
+
+// OnResubscribe registers a hook that runs after every reconnection, to
+// re-issue state the server lost when the previous connection dropped
+func (s *SocketClient) OnResubscribe(hook func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resubscribeHooks = append(s.resubscribeHooks, hook)
+}
+
+// autoLogSubscriptions returns the log streams started automatically on connect
+func (s *SocketClient) autoLogSubscriptions() []logSubscription {
+	subs := []logSubscription{
+		{logType: "journalctl", start: s.logStreams.StartJournalctlStream},
+		{logType: "app", start: s.logStreams.StartAppLogStream},
+		{logType: "cage", start: s.logStreams.StartCageLogStream},
+		{logType: "early", start: s.logStreams.StartEarlyLogStream},
+	}
+	for i := range subs {
+		subs[i].streamID = fmt.Sprintf("auto-%s-%d", subs[i].logType, time.Now().UnixMilli())
 	}
+	return subs
 }
 
 // startAutoLogStreams starts all log streams automatically on connect
 func (s *SocketClient) startAutoLogStreams() {
 	s.logger.Info("Auto-starting log streams...")
 
-	logTypes := []struct {
-		logType string
-		starter func(string, LogCallback) error
-	}{
-		{"journalctl", s.logStreams.StartJournalctlStream},
-		{"app", s.logStreams.StartAppLogStream},
-		{"cage", s.logStreams.StartCageLogStream},
-		{"early", s.logStreams.StartEarlyLogStream},
-	}
-
-	for _, lt := range logTypes {
-		streamID := fmt.Sprintf("auto-%s-%d", lt.logType, time.Now().UnixMilli())
-		logType := lt.logType
-		err := lt.starter(streamID, func(line string) {
-			s.SendLogLine(logType, line)
-		})
-		if err != nil {
-			s.logger.Warn("Failed to start %s log stream: %v", lt.logType, err)
+	for _, sub := range s.autoLogSubscriptions() {
+		if err := s.startLogSubscription(sub); err != nil {
+			s.logger.Warn("Failed to start %s log stream: %v", sub.logType, err)
 		}
 	}
 }
 
+// startLogSubscription starts a log stream and remembers how to restart it
+func (s *SocketClient) startLogSubscription(sub logSubscription) error {
+	logType := sub.logType
+	err := sub.start(sub.streamID, func(line string) {
+		s.SendLogLine(logType, line)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.logSubs[sub.streamID] = sub
+	s.mu.Unlock()
+	return nil
+}
+
+// handleStartLogs starts a server-requested log stream. Invalid requests
+// (unknown type, unsupported output format) are reported with log-stream-error.
+func (s *SocketClient) handleStartLogs(payload StartLogsPayload) {
+	format, err := ValidateJournalFormat(payload.OutputFormat)
+	if err != nil {
+		s.SendLogStreamError(payload.StreamID, err.Error())
+		return
+	}
+
+	sub := logSubscription{streamID: payload.StreamID, logType: payload.Type}
+	switch payload.Type {
+	case "journalctl":
+		sub.start = func(streamID string, callback LogCallback) error {
+			return s.logStreams.StartJournalctlStreamWithFormat(streamID, format, callback)
+		}
+	case "service":
+		if payload.Service == "" {
+			s.SendLogStreamError(payload.StreamID, "service name is required for service log streams")
+			return
+		}
+		service := payload.Service
+		sub.start = func(streamID string, callback LogCallback) error {
+			return s.logStreams.StartServiceStreamWithFormat(streamID, service, format, callback)
+		}
+	case "app":
+		sub.start = s.logStreams.StartAppLogStream
+	case "cage":
+		sub.start = s.logStreams.StartCageLogStream
+	case "early":
+		sub.start = s.logStreams.StartEarlyLogStream
+	default:
+		s.SendLogStreamError(payload.StreamID, fmt.Sprintf("unknown log stream type %q", payload.Type))
+		return
+	}
+
+	if err := s.startLogSubscription(sub); err != nil {
+		s.SendLogStreamError(payload.StreamID, err.Error())
+	}
+}
+
+// snapshotLogStreams records which log streams are active so they can be
+// restored once the connection comes back
+func (s *SocketClient) snapshotLogStreams() {
+	active := s.logStreams.GetActiveStreams()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resumeLogSubs = s.resumeLogSubs[:0]
+	for _, streamID := range active {
+		if sub, ok := s.logSubs[streamID]; ok {
+			s.resumeLogSubs = append(s.resumeLogSubs, sub)
+		}
+	}
+	s.logSubs = make(map[string]logSubscription)
+}
+
+// restoreLogStreams restarts the log streams that were active before the
+// connection dropped, plus any auto streams that are not running (e.g. ones
+// that failed to start the first time)
+func (s *SocketClient) restoreLogStreams() {
+	s.mu.Lock()
+	subs := append([]logSubscription(nil), s.resumeLogSubs...)
+	s.resumeLogSubs = nil
+	s.mu.Unlock()
+
+	// Auto streams that kept running through the disconnect (log buffering)
+	// don't need a replacement
+	running := make(map[string]bool)
+	for _, streamID := range s.logStreams.GetActiveStreams() {
+		running[streamID] = true
+	}
+	restored := make(map[string]bool, len(subs))
+	s.mu.Lock()
+	for streamID, sub := range s.logSubs {
+		if running[streamID] && strings.HasPrefix(streamID, "auto-") {
+			restored[sub.logType] = true
+		}
+	}
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		if err := s.startLogSubscription(sub); err != nil {
+			s.logger.Warn("Failed to restore %s log stream: %v", sub.logType, err)
+			continue
+		}
+		if strings.HasPrefix(sub.streamID, "auto-") {
+			restored[sub.logType] = true
+		}
+	}
+	for _, sub := range s.autoLogSubscriptions() {
+		if restored[sub.logType] {
+			continue
+		}
+		if err := s.startLogSubscription(sub); err != nil {
+			s.logger.Warn("Failed to start %s log stream: %v", sub.logType, err)
+		}
+	}
+
+	s.logger.Info("Restored %d log stream(s) after reconnection", len(s.logStreams.GetActiveStreams()))
+	s.SendStreamsResumed()
+}
+
+// resumedStreams returns the log streams that are running, sorted by ID
+func (s *SocketClient) resumedStreams() []ResumedStream {
+	active := s.logStreams.GetActiveStreams()
+	sort.Strings(active)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	streams := make([]ResumedStream, 0, len(active))
+	for _, streamID := range active {
+		streams = append(streams, ResumedStream{StreamID: streamID, Type: s.logSubs[streamID].logType})
+	}
+	return streams
+}
+
+// SendStreamsResumed tells the server which log streams survived or were
+// restored after a reconnect
+func (s *SocketClient) SendStreamsResumed() {
+	if s.ws == nil {
+		return
+	}
+
+	payload := StreamsResumedPayload{Streams: s.resumedStreams()}
+	if err := s.ws.Emit("streams-resumed", payload); err != nil {
+		s.logger.Error("Failed to send resumed streams: %v", err)
+	}
+}
+
 // handleSSHStart starts or attaches to an SSH/PTY session
 func (s *SocketClient) handleSSHStart(payload SSHStartPayload) {
 	s.logger.Info("SSH start requested: %s", payload.SessionID)
@@ -650,6 +1147,12 @@ func (s *SocketClient) handleComponentUpdate(payload ComponentPayload) {
 
 	s.logger.Info("Decoded component: %d bytes -> %s", len(decoded), payload.DestPath)
 
+	if StorageLayoutInstance.IsReadOnlyPath(payload.DestPath) {
+		s.logger.Error("Cannot update %s: %s is read-only on this device", payload.DestPath, struxDir)
+		s.SendComponentAck("error", struxDir+" is read-only on this device", payload.DestPath)
+		return
+	}
+
 	if payload.DestPath == "/strux/frontend" {
 		if err := extractZipToPath(decoded, payload.DestPath); err != nil {
 			s.logger.Error("Failed to extract frontend archive: %v", err)
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResumedStreamsListsRunningStreams(t *testing.T) {
+	client := NewSocketClient("key")
+	client.logStreams.streams["auto-cage-1"] = &LogStream{ID: "auto-cage-1"}
+	client.logStreams.streams["dev-app"] = &LogStream{ID: "dev-app"}
+	client.logSubs["auto-cage-1"] = logSubscription{streamID: "auto-cage-1", logType: "cage"}
+	client.logSubs["dev-app"] = logSubscription{streamID: "dev-app", logType: "app"}
+	client.logSubs["stopped"] = logSubscription{streamID: "stopped", logType: "journalctl"}
+
+	want := []ResumedStream{
+		{StreamID: "auto-cage-1", Type: "cage"},
+		{StreamID: "dev-app", Type: "app"},
+	}
+	if got := client.resumedStreams(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestReloadRequestsAreDebounced(t *testing.T) {
+	client := NewSocketClient("key")
+	client.reloadDelay = 20 * time.Millisecond
+	var reloads atomic.Int32
+	client.onReload = func() { reloads.Add(1) }
+
+	for i := 0; i < 5; i++ {
+		client.requestReload()
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := reloads.Load(); got != 1 {
+		t.Fatalf("expected a burst of requests to reload once, got %d", got)
+	}
+
+	client.requestReload()
+	time.Sleep(100 * time.Millisecond)
+	if got := reloads.Load(); got != 2 {
+		t.Fatalf("expected a later request to reload again, got %d", got)
+	}
+}
@@ -0,0 +1,106 @@
+//
+// Strux Client - Storage Layout
+//
+// Detects at startup whether /strux can be written. On hardened images it is
+// mounted read-only and only the data partition is writable, so files the
+// client writes (binary updates and their temp files) go to a writable
+// directory instead, set by STRUX_WRITABLE_DIR. strux.sh starts binaries
+// installed there in preference to the ones in /strux.
+//
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// struxDir holds the app, the client and the frontend
+const struxDir = "/strux"
+
+// writableDirEnvVar overrides where the client writes when /strux is read-only
+const writableDirEnvVar = "STRUX_WRITABLE_DIR"
+
+// defaultWritableDir is on the data partition, which stays writable on
+// images with a read-only root filesystem
+const defaultWritableDir = struxDataDir + "/writable"
+
+// StorageLayout describes where the client can write
+type StorageLayout struct {
+	ReadOnly    bool   // /strux cannot be written
+	WritableDir string // Where writable artifacts go: /strux itself, or the writable directory when it is read-only
+	Writable    bool   // WritableDir can be written
+}
+
+// StorageLayoutInstance is the layout detected at startup
+var StorageLayoutInstance = StorageLayout{WritableDir: struxDir, Writable: true}
+
+// DetectStorageLayout checks whether dir can be written, falling back to
+// writableDir (created if missing) when it cannot
+func DetectStorageLayout(dir string, writableDir string) StorageLayout {
+	if isWritableDir(dir) {
+		return StorageLayout{WritableDir: dir, Writable: true}
+	}
+
+	layout := StorageLayout{ReadOnly: true, WritableDir: writableDir}
+	if err := os.MkdirAll(writableDir, 0755); err == nil {
+		layout.Writable = isWritableDir(writableDir)
+	}
+	return layout
+}
+
+// isWritableDir reports whether a file can be created in dir. Checking
+// permission bits is not enough: a read-only mount rejects root as well.
+func isWritableDir(dir string) bool {
+	file, err := os.CreateTemp(dir, ".strux-write-check-*")
+	if err != nil {
+		return false
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return true
+}
+
+// IsReadOnlyPath reports whether path lies in a read-only /strux
+func (l StorageLayout) IsReadOnlyPath(path string) bool {
+	if !l.ReadOnly {
+		return false
+	}
+	cleaned := filepath.Clean(path)
+	return cleaned == struxDir || strings.HasPrefix(cleaned, struxDir+"/")
+}
+
+// Describe summarizes the layout for the startup log
+func (l StorageLayout) Describe() string {
+	if !l.ReadOnly {
+		return fmt.Sprintf("%s is writable", struxDir)
+	}
+	if !l.Writable {
+		return fmt.Sprintf("%s is read-only and %s (%s) is not writable either; binary updates will fail", struxDir, l.WritableDir, writableDirEnvVar)
+	}
+	return fmt.Sprintf("%s is read-only; writing binary updates to %s", struxDir, l.WritableDir)
+}
+
+// detectStorageLayout detects the layout, logs it, and points the binary
+// handlers at the writable directory when /strux is read-only
+func detectStorageLayout(logger *Logger) {
+	writableDir := strings.TrimSpace(os.Getenv(writableDirEnvVar))
+	if writableDir == "" {
+		writableDir = defaultWritableDir
+	}
+
+	layout := DetectStorageLayout(struxDir, writableDir)
+	StorageLayoutInstance = layout
+	if layout.ReadOnly && !layout.Writable {
+		logger.Warn("Storage: %s", layout.Describe())
+	} else {
+		logger.Info("Storage: %s", layout.Describe())
+	}
+
+	if layout.ReadOnly {
+		BinaryHandlerInstance.UseWritableDir(layout.WritableDir)
+		ClientBinaryHandlerInstance.UseWritableDir(layout.WritableDir)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectStorageLayoutWritable(t *testing.T) {
+	dir := t.TempDir()
+	writableDir := filepath.Join(t.TempDir(), "writable")
+
+	layout := DetectStorageLayout(dir, writableDir)
+	if layout.ReadOnly || layout.WritableDir != dir || !layout.Writable {
+		t.Fatalf("unexpected layout %+v", layout)
+	}
+	if fileExists(writableDir) {
+		t.Fatal("writable dir must not be created when the dir itself is writable")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected the write check to clean up, found %d entries", len(entries))
+	}
+}
+
+func TestDetectStorageLayoutReadOnly(t *testing.T) {
+	// A missing directory can't be written, like a read-only mount
+	dir := filepath.Join(t.TempDir(), "missing")
+	writableDir := filepath.Join(t.TempDir(), "writable")
+
+	layout := DetectStorageLayout(dir, writableDir)
+	if !layout.ReadOnly || layout.WritableDir != writableDir || !layout.Writable {
+		t.Fatalf("unexpected layout %+v", layout)
+	}
+	if !fileExists(writableDir) {
+		t.Fatal("expected the writable dir to be created")
+	}
+
+	if !layout.IsReadOnlyPath("/strux/frontend") || !layout.IsReadOnlyPath("/strux") {
+		t.Fatal("expected paths in /strux to be read-only")
+	}
+	if layout.IsReadOnlyPath("/struxdata/file") || layout.IsReadOnlyPath("/tmp/file") {
+		t.Fatal("expected paths outside /strux to be writable")
+	}
+}
@@ -24,10 +24,14 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -43,10 +47,52 @@ type Message struct {
 // EventHandler is a function that handles an event with its payload
 type EventHandler func(payload json.RawMessage)
 
+// BinaryMessageHandler is a function that handles a binary WebSocket message
+type BinaryMessageHandler func(data []byte)
+
+// WildcardEvent is the event type that matches every incoming message.
+// Wildcard handlers receive the full message envelope ({"type", "payload"})
+// instead of just the payload, so the event type is available to them.
+const WildcardEvent = "*"
+
+// defaultMaxReconnectDelay caps the reconnect backoff unless SetMaxReconnectDelay
+// configures a different ceiling
+const defaultMaxReconnectDelay = 30 * time.Second
+
+// parseWarningInterval is the minimum time between warnings about messages
+// that could not be parsed. Failures in between are counted and reported with
+// the next warning, so a misbehaving peer can't flood the log.
+const parseWarningInterval = 10 * time.Second
+
+// warningThrottle rate-limits a repeated warning, counting the occurrences it
+// suppresses
+type warningThrottle struct {
+	mu         sync.Mutex
+	interval   time.Duration
+	last       time.Time
+	suppressed int
+}
+
+// allow reports whether a warning may be logged now. When it may, it also
+// returns how many warnings were suppressed since the last one.
+func (t *warningThrottle) allow(now time.Time) (bool, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.last.IsZero() && now.Sub(t.last) < t.interval {
+		t.suppressed++
+		return false, 0
+	}
+	suppressed := t.suppressed
+	t.last = now
+	t.suppressed = 0
+	return true, suppressed
+}
+
 // WSClient is a WebSocket client with event-based message handling
 type WSClient struct {
 	conn     *websocket.Conn
 	handlers map[string][]EventHandler
+	binary   []BinaryMessageHandler
 	mu       sync.RWMutex
 	connMu   sync.Mutex
 	done     chan struct{}
@@ -62,34 +108,55 @@ type WSClient struct {
 	onConnect    func()
 	onDisconnect func()
 	onError      func(error)
+	onRTT        func(time.Duration)
+
+	// Round-trip time of the most recent ping, 0 until the first pong
+	lastRTT time.Duration
+
+	parseWarnings *warningThrottle
 
 	// Configuration
-	pingInterval    time.Duration
-	reconnect       bool
-	reconnectDelay  time.Duration
-	maxReconnectTry int
+	pingInterval      time.Duration
+	reconnect         bool
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+	maxReconnectTry   int
+
+	// URLs of other servers to try in turn when reconnecting to url fails
+	reconnectURLs []string
 }
 
 // NewWSClient creates a new WebSocket client
 func NewWSClient() *WSClient {
 	return &WSClient{
-		handlers:        make(map[string][]EventHandler),
-		logger:          NewLogger("WSClient"),
-		pingInterval:    30 * time.Second,
-		reconnect:       true,
-		reconnectDelay:  2 * time.Second,
-		maxReconnectTry: 5,
+		handlers:          make(map[string][]EventHandler),
+		logger:            NewLogger("WSClient"),
+		pingInterval:      30 * time.Second,
+		reconnect:         true,
+		reconnectDelay:    2 * time.Second,
+		maxReconnectDelay: defaultMaxReconnectDelay,
+		maxReconnectTry:   5,
+		parseWarnings:     &warningThrottle{interval: parseWarningInterval},
 	}
 }
 
 // On registers an event handler for a specific event type
-// Multiple handlers can be registered for the same event
+// Multiple handlers can be registered for the same event.
+// Registering for WildcardEvent ("*") observes every incoming message.
 func (w *WSClient) On(eventType string, handler EventHandler) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	w.handlers[eventType] = append(w.handlers[eventType], handler)
 }
 
+// OnBinary registers a handler for binary messages. Binary messages are not
+// JSON events, so they are only delivered to binary handlers.
+func (w *WSClient) OnBinary(handler BinaryMessageHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.binary = append(w.binary, handler)
+}
+
 // Off removes all handlers for a specific event type
 func (w *WSClient) Off(eventType string) {
 	w.mu.Lock()
@@ -111,6 +178,22 @@ func (w *WSClient) OnDisconnect(handler func()) {
 	w.onDisconnect = handler
 }
 
+// OnRTT sets a callback invoked with the round-trip time of each ping, as a
+// measure of link quality
+func (w *WSClient) OnRTT(handler func(time.Duration)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onRTT = handler
+}
+
+// LastRTT returns the round-trip time of the most recent ping, or 0 if no
+// pong has been received yet
+func (w *WSClient) LastRTT() time.Duration {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.lastRTT
+}
+
 // OnError sets a callback for connection errors
 func (w *WSClient) OnError(handler func(error)) {
 	w.mu.Lock()
@@ -127,6 +210,34 @@ func (w *WSClient) SetReconnect(enabled bool, delay time.Duration, maxRetries in
 	w.maxReconnectTry = maxRetries
 }
 
+// SetMaxReconnectDelay sets the ceiling for the exponential reconnect backoff.
+// A larger ceiling means fewer wakeups while the server is unreachable, at the
+// cost of noticing its return later. Values <= 0 restore the 30 second default.
+func (w *WSClient) SetMaxReconnectDelay(max time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if max <= 0 {
+		max = defaultMaxReconnectDelay
+	}
+	w.maxReconnectDelay = max
+}
+
+// SetReconnectURLs sets other servers to try when reconnecting. Attempts
+// cycle through the current URL and then each of these, so the client
+// follows a server that has moved to another host.
+func (w *WSClient) SetReconnectURLs(urls []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.reconnectURLs = urls
+}
+
+// URL returns the URL of the current (or most recent) connection
+func (w *WSClient) URL() string {
+	w.connMu.Lock()
+	defer w.connMu.Unlock()
+	return w.url
+}
+
 // SetHeader sets a header to be sent during the WebSocket handshake
 func (w *WSClient) SetHeader(key, value string) {
 	w.mu.Lock()
@@ -195,6 +306,7 @@ func (w *WSClient) Connect(wsURL string) error {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 
+	conn.SetPongHandler(w.handlePong)
 	w.conn = conn
 	w.done = make(chan struct{})
 	w.connected = true
@@ -302,6 +414,20 @@ func (w *WSClient) Emit(eventType string, payload interface{}) error {
 // EmitWithAck sends an event and waits for an acknowledgment
 // The ack event type is expected to be eventType + "-ack"
 func (w *WSClient) EmitWithAck(eventType string, payload interface{}, timeout time.Duration) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ack, err := w.EmitWithAckContext(ctx, eventType, payload)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timeout waiting for ack")
+	}
+	return ack, err
+}
+
+// EmitWithAckContext sends an event and waits for its acknowledgment until ctx
+// is done. The pending ack handler is removed however the wait ends, so
+// callers can abandon a request without leaking it.
+func (w *WSClient) EmitWithAckContext(ctx context.Context, eventType string, payload interface{}) (json.RawMessage, error) {
 	ackChan := make(chan json.RawMessage, 1)
 	ackEvent := eventType + "-ack"
 
@@ -319,12 +445,12 @@ func (w *WSClient) EmitWithAck(eventType string, payload interface{}, timeout ti
 		return nil, err
 	}
 
-	// Wait for ack or timeout
+	// Wait for ack or cancellation
 	select {
 	case ack := <-ackChan:
 		return ack, nil
-	case <-time.After(timeout):
-		return nil, fmt.Errorf("timeout waiting for ack")
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for %s: %w", ackEvent, ctx.Err())
 	}
 }
 
@@ -366,8 +492,9 @@ func (w *WSClient) readLoop() {
 		default:
 		}
 
-		// Read message
-		_, data, err := w.conn.ReadMessage()
+		// Read message. Control frames (ping/pong/close) are answered by the
+		// connection's control handlers and never returned here.
+		messageType, data, err := w.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 				w.logger.Info("Connection closed normally")
@@ -385,30 +512,89 @@ func (w *WSClient) readLoop() {
 			return
 		}
 
-		// Parse message
-		var msg Message
-		if err := json.Unmarshal(data, &msg); err != nil {
-			w.logger.Warn("Failed to parse message: %v", err)
-			continue
+		switch messageType {
+		case websocket.BinaryMessage:
+			w.dispatchBinary(data)
+		case websocket.TextMessage:
+			w.handleText(data)
 		}
+	}
+}
 
-		// Dispatch to handlers
-		w.dispatch(msg.Type, msg.Payload)
+// handleText parses a text message as a JSON event and dispatches it. Plain
+// text keepalives ("ping"/"pong") are answered or ignored rather than treated
+// as malformed events.
+func (w *WSClient) handleText(data []byte) {
+	switch string(bytes.TrimSpace(data)) {
+	case "ping":
+		w.connMu.Lock()
+		if w.conn != nil {
+			w.conn.WriteMessage(websocket.TextMessage, []byte("pong"))
+		}
+		w.connMu.Unlock()
+		return
+	case "pong":
+		return
 	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		w.warnParseFailure(err)
+		return
+	}
+
+	// Dispatch to handlers
+	w.dispatch(msg.Type, msg.Payload)
+}
+
+// warnParseFailure logs an unparseable message, throttled to one warning per
+// parseWarningInterval
+func (w *WSClient) warnParseFailure(err error) {
+	ok, suppressed := w.parseWarnings.allow(time.Now())
+	if !ok {
+		return
+	}
+	if suppressed > 0 {
+		w.logger.Warn("Failed to parse message: %v (%d more since last warning)", err, suppressed)
+		return
+	}
+	w.logger.Warn("Failed to parse message: %v", err)
 }
 
-// dispatch calls all registered handlers for an event type
+// dispatchBinary calls all registered binary handlers
+func (w *WSClient) dispatchBinary(data []byte) {
+	w.mu.RLock()
+	handlers := w.binary
+	w.mu.RUnlock()
+
+	for _, handler := range handlers {
+		go handler(data)
+	}
+}
+
+// dispatch calls all registered handlers for an event type, plus any wildcard handlers
 func (w *WSClient) dispatch(eventType string, payload json.RawMessage) {
 	w.mu.RLock()
 	handlers := w.handlers[eventType]
+	wildcardHandlers := w.handlers[WildcardEvent]
 	w.mu.RUnlock()
 
-	if len(handlers) == 0 {
+	for _, handler := range handlers {
+		go handler(payload)
+	}
+
+	if len(wildcardHandlers) == 0 || eventType == WildcardEvent {
 		return
 	}
 
-	for _, handler := range handlers {
-		go handler(payload)
+	envelope, err := json.Marshal(Message{Type: eventType, Payload: payload})
+	if err != nil {
+		w.logger.Warn("Failed to encode %s for wildcard handlers: %v", eventType, err)
+		return
+	}
+
+	for _, handler := range wildcardHandlers {
+		go handler(envelope)
 	}
 }
 
@@ -424,7 +610,9 @@ func (w *WSClient) pingLoop() {
 		case <-ticker.C:
 			w.connMu.Lock()
 			if w.conn != nil {
-				if err := w.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				// The send time travels in the ping and comes back in the pong
+				sentAt := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+				if err := w.conn.WriteMessage(websocket.PingMessage, sentAt); err != nil {
 					w.logger.Warn("Ping failed: %v", err)
 				}
 			}
@@ -433,11 +621,33 @@ func (w *WSClient) pingLoop() {
 	}
 }
 
-// attemptReconnect tries to reconnect to the server indefinitely
+// handlePong measures the round-trip time of a ping from the send time it
+// carries. Pongs without one (unsolicited, or from older servers) are ignored.
+func (w *WSClient) handlePong(appData string) error {
+	sentAt, err := strconv.ParseInt(appData, 10, 64)
+	if err != nil {
+		return nil
+	}
+	rtt := time.Since(time.Unix(0, sentAt))
+
+	w.mu.Lock()
+	w.lastRTT = rtt
+	onRTT := w.onRTT
+	w.mu.Unlock()
+
+	if onRTT != nil {
+		go onRTT(rtt)
+	}
+	return nil
+}
+
+// attemptReconnect tries to reconnect indefinitely, cycling through the
+// current URL and the reconnect URLs
 func (w *WSClient) attemptReconnect() {
 	w.mu.RLock()
 	delay := w.reconnectDelay
-	url := w.url
+	maxDelay := w.maxReconnectDelay
+	candidates := reconnectCandidates(w.url, w.reconnectURLs)
 	w.mu.RUnlock()
 
 	attempt := 0
@@ -447,15 +657,40 @@ func (w *WSClient) attemptReconnect() {
 
 		time.Sleep(delay)
 
-		if err := w.Connect(url); err == nil {
+		target := candidates[(attempt-1)%len(candidates)]
+		if err := w.Connect(target); err == nil {
 			w.logger.Info("Reconnected successfully")
 			return
 		}
 
-		// Exponential backoff, capped at 30 seconds
-		delay = delay * 2
-		if delay > 30*time.Second {
-			delay = 30 * time.Second
+		delay = nextReconnectDelay(delay, maxDelay)
+	}
+}
+
+// reconnectCandidates returns the URLs to cycle through when reconnecting:
+// the current URL first, then the alternatives on other hosts
+func reconnectCandidates(current string, alternatives []string) []string {
+	candidates := []string{current}
+	currentURL, err := url.Parse(current)
+	if err != nil {
+		return candidates
+	}
+	for _, alternative := range alternatives {
+		u, err := url.Parse(alternative)
+		if err != nil || u.Host == currentURL.Host {
+			continue
 		}
+		candidates = append(candidates, alternative)
+	}
+	return candidates
+}
+
+// nextReconnectDelay doubles the reconnect delay, capped at max. Only later
+// attempts are capped: the first waits the configured base delay, even if it
+// is above the ceiling.
+func nextReconnectDelay(delay, max time.Duration) time.Duration {
+	if delay >= max/2 {
+		return max
 	}
+	return delay * 2
 }
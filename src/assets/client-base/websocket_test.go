@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWarningThrottleAggregatesSuppressedWarnings(t *testing.T) {
+	throttle := &warningThrottle{interval: 10 * time.Second}
+	start := time.Now()
+
+	if ok, suppressed := throttle.allow(start); !ok || suppressed != 0 {
+		t.Fatalf("expected the first warning to be allowed, got ok=%v suppressed=%d", ok, suppressed)
+	}
+	for i := 1; i <= 3; i++ {
+		if ok, _ := throttle.allow(start.Add(time.Duration(i) * time.Second)); ok {
+			t.Fatalf("expected warning %d within the interval to be suppressed", i)
+		}
+	}
+
+	ok, suppressed := throttle.allow(start.Add(11 * time.Second))
+	if !ok {
+		t.Fatal("expected a warning after the interval to be allowed")
+	}
+	if suppressed != 3 {
+		t.Fatalf("expected 3 suppressed warnings, got %d", suppressed)
+	}
+}
+
+func TestNextReconnectDelayRespectsCeiling(t *testing.T) {
+	max := 2 * time.Minute
+	delay := 2 * time.Second
+	var delays []time.Duration
+	for i := 0; i < 8; i++ {
+		delay = nextReconnectDelay(delay, max)
+		delays = append(delays, delay)
+	}
+
+	want := []time.Duration{4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second, 64 * time.Second, max, max, max}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("delay %d = %v, want %v", i, delays[i], want[i])
+		}
+	}
+}
+
+func TestReconnectCandidatesStartWithCurrentHost(t *testing.T) {
+	current := "ws://10.0.0.1:8000/client?key=abc&v=0.3.0"
+	got := reconnectCandidates(current, []string{
+		"ws://10.0.0.1:8000/client",
+		"ws://10.0.0.2:8000/client",
+		"ws://10.0.0.3:8000/client",
+	})
+
+	want := []string{current, "ws://10.0.0.2:8000/client", "ws://10.0.0.3:8000/client"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected candidates %v, got %v", want, got)
+	}
+}
+
+func TestHandlePongMeasuresRTT(t *testing.T) {
+	ws := NewWSClient()
+	measured := make(chan time.Duration, 1)
+	ws.OnRTT(func(rtt time.Duration) { measured <- rtt })
+
+	sentAt := time.Now().Add(-50 * time.Millisecond)
+	ws.handlePong(strconv.FormatInt(sentAt.UnixNano(), 10))
+
+	if rtt := ws.LastRTT(); rtt < 50*time.Millisecond || rtt > time.Second {
+		t.Fatalf("unexpected RTT %v", rtt)
+	}
+	select {
+	case <-measured:
+	case <-time.After(time.Second):
+		t.Fatal("OnRTT callback was not called")
+	}
+
+	// Pongs without a send time don't change the measurement
+	last := ws.LastRTT()
+	ws.handlePong("")
+	if ws.LastRTT() != last {
+		t.Fatal("expected an empty pong to be ignored")
+	}
+}
+
+func TestDispatchWildcardHandlers(t *testing.T) {
+	ws := NewWSClient()
+	exact := make(chan string, 4)
+	wildcard := make(chan string, 4)
+	ws.On("status", func(payload json.RawMessage) { exact <- string(payload) })
+	ws.On(WildcardEvent, func(payload json.RawMessage) { wildcard <- string(payload) })
+
+	receive := func(ch chan string, what string) string {
+		t.Helper()
+		select {
+		case got := <-ch:
+			return got
+		case <-time.After(time.Second):
+			t.Fatalf("%s handler was not called", what)
+			return ""
+		}
+	}
+	expectNothing := func(ch chan string, what string) {
+		t.Helper()
+		select {
+		case got := <-ch:
+			t.Fatalf("unexpected call to the %s handler with %s", what, got)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	// A matching event reaches the exact handler with its bare payload, and
+	// the wildcard handler with the envelope; neither replaces the other
+	ws.dispatch("status", json.RawMessage(`{"ok":true}`))
+	if got := receive(exact, "exact"); got != `{"ok":true}` {
+		t.Fatalf("expected the exact handler to get the payload, got %s", got)
+	}
+	if got := receive(wildcard, "wildcard"); got != `{"type":"status","payload":{"ok":true}}` {
+		t.Fatalf("expected the wildcard handler to get the envelope, got %s", got)
+	}
+
+	// Events without an exact handler still reach the wildcard handler
+	ws.dispatch("reload", nil)
+	if got := receive(wildcard, "wildcard"); got != `{"type":"reload"}` {
+		t.Fatalf("expected the wildcard handler to get the envelope, got %s", got)
+	}
+	expectNothing(exact, "exact")
+
+	// An event literally named "*" is delivered once, not twice
+	ws.dispatch(WildcardEvent, json.RawMessage(`1`))
+	if got := receive(wildcard, "wildcard"); got != `1` {
+		t.Fatalf("expected a single delivery of the payload, got %s", got)
+	}
+	expectNothing(wildcard, "wildcard")
+
+	// Removing the exact handlers leaves the wildcard handler registered
+	ws.Off("status")
+	ws.dispatch("status", json.RawMessage(`{"ok":false}`))
+	receive(wildcard, "wildcard")
+	expectNothing(exact, "exact")
+}
+
+func TestEmitWithAckContextCancel(t *testing.T) {
+	// A server that accepts events but never acknowledges them
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(rw, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	ws := NewWSClient()
+	ws.SetReconnect(false, 0, 0)
+	if err := ws.Connect("ws" + strings.TrimPrefix(server.URL, "http")); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer ws.Disconnect()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := ws.EmitWithAckContext(ctx, "binary-request", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	ws.mu.RLock()
+	_, pending := ws.handlers["binary-request-ack"]
+	ws.mu.RUnlock()
+	if pending {
+		t.Fatal("expected the ack handler to be removed after cancellation")
+	}
+}
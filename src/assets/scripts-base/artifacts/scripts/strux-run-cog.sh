@@ -12,6 +12,8 @@
 #
 # Environment:
 #   All Cage environment variables are inherited (WAYLAND_DISPLAY, etc.)
+#   WPE_WEB_EXTENSION_PATH - Directory Cog loads web extensions from
+#                            (set by the Strux client from display.web_extensions_dir)
 #
 
 OUTPUT_NAME="$1"
@@ -37,10 +39,12 @@ if [ -f /tmp/strux-inspector-base-port ]; then
     echo "[strux-run-cog] WebKit Inspector on port $INSPECTOR_PORT for output $OUTPUT_NAME"
 fi
 
+WEB_EXTENSIONS_DIR="${WPE_WEB_EXTENSION_PATH:-/usr/lib/wpe-web-extensions}"
+
 # Launch Cog browser
 # --autoplay-policy=allow: permit unmuted media autoplay without user gesture
 exec cog \
-  --web-extensions-dir=/usr/lib/wpe-web-extensions \
+  --web-extensions-dir="$WEB_EXTENSIONS_DIR" \
   --platform=wl \
   --enable-developer-extras=1 \
   --autoplay-policy=allow \
@@ -151,8 +151,21 @@ if [ -f /strux/.client-update ]; then
     mv /strux/.client-update /strux/client
 fi
 
+# On images with a read-only /strux, the client installs binary updates in
+# STRUX_WRITABLE_DIR instead. Those win while they are newer than the binary
+# in /strux, so a system update that ships a newer one takes over again.
+export STRUX_WRITABLE_DIR="${STRUX_WRITABLE_DIR:-/strux-data/strux/writable}"
+
+# Apps keep their persistent state in /strux-data/app (runtime.AppDataDir),
+# which a factory reset erases
+mkdir -p /strux-data/app
+
 # Use /strux/main for the backend binary
 APP_BINARY="/strux/main"
+if [ -x "$STRUX_WRITABLE_DIR/main" ] && [ "$STRUX_WRITABLE_DIR/main" -nt "$APP_BINARY" ]; then
+    APP_BINARY="$STRUX_WRITABLE_DIR/main"
+    log "Using backend update from $APP_BINARY"
+fi
 
 # Check if binary exists
 if [ ! -x "$APP_BINARY" ]; then
@@ -174,8 +187,11 @@ fi
 # Start the backend app in the background
 # Cog and backend health checks still use localhost:8080.
 # The runtime now serves /strux/frontend directly when it exists.
+# Logs are written in append mode so that strux.system.DiskCleanup can
+# truncate them in place; a plain > redirect keeps writing at the old offset.
 log "Starting backend app..."
-cd / && $APP_BINARY > /tmp/strux-backend.log 2>&1 &
+: > /tmp/strux-backend.log
+cd / && $APP_BINARY >> /tmp/strux-backend.log 2>&1 &
 BACKEND_PID=$!
 log "Backend started with PID: $BACKEND_PID"
 
@@ -218,6 +234,10 @@ fi
 # Launch the client which will handle Cage and Cog launching
 # The client will check for /strux/.dev-env.json to determine mode
 CLIENT_BINARY="/strux/client"
+if [ -x "$STRUX_WRITABLE_DIR/client" ] && [ "$STRUX_WRITABLE_DIR/client" -nt "$CLIENT_BINARY" ]; then
+    CLIENT_BINARY="$STRUX_WRITABLE_DIR/client"
+    log "Using client update from $CLIENT_BINARY"
+fi
 
 if [ ! -x "$CLIENT_BINARY" ]; then
     log "ERROR: Client binary not found at $CLIENT_BINARY!"
@@ -231,7 +251,7 @@ log "Client will determine mode based on /strux/.dev-env.json"
 # This mirrors how the backend log is handled above, ensuring client
 # output (host discovery, WebSocket connection, etc.) is visible on
 # the serial console even after Cage takes over the VT.
-touch /tmp/strux-client.log
+: > /tmp/strux-client.log
 (
     SERIAL_DEV=""
     if [ -e /dev/console ]; then
@@ -249,4 +269,4 @@ touch /tmp/strux-client.log
 # Launch client - it will handle everything from here
 # Redirect output to log file so the tail above can pick it up,
 # while exec preserves proper PID tracking for systemd restart.
-exec "$CLIENT_BINARY" > /tmp/strux-client.log 2>&1
+exec "$CLIENT_BINARY" >> /tmp/strux-client.log 2>&1
@@ -139,6 +139,13 @@ fi
 # Example: GO_PRIVATE_ENV="GOPRIVATE=example.com " (note the trailing space if setting env vars)
 GO_PRIVATE_ENV="${GO_PRIVATE_ENV:-}"
 
+# Build timestamp is stamped into the binary's build info so the device can
+# refuse to replace a newer binary with an older one (downgrade protection)
+BUILD_TIMESTAMP="${STRUX_BUILD_TIMESTAMP:-$(date -u +%s)}"
+
+# Project version is reported to the frontend by the runtime's __appInfo call
+APP_VERSION_FLAG="-X github.com/strux-dev/strux/pkg/runtime.AppVersion=${PROJECT_VERSION:-}"
+
 # Build the Go application with cross-compilation
 GOTOOLCHAIN=local \
 CGO_ENABLED=1 \
@@ -146,7 +153,7 @@ GOOS=linux \
 GOARCH="$GO_ARCH" \
 GOARM="${GOARM:-}" \
 CC="$CROSS_COMPILER" \
-${GO_PRIVATE_ENV}go build -buildvcs=false -o "$CACHE_DIR/app/main" .
+${GO_PRIVATE_ENV}go build -buildvcs=false -ldflags "-X main.BuildTimestamp=$BUILD_TIMESTAMP $APP_VERSION_FLAG" -o "$CACHE_DIR/app/main" .
 
 
 progress "Go application built successfully"
@@ -7,6 +7,8 @@
 #include <json-glib/json-glib.h>
 
 #define SOCKET_PATH "/tmp/strux-ipc.sock"
+// Written by the runtime when it requires connections to authenticate
+#define AUTH_TOKEN_PATH "/tmp/strux-ipc.token"
 
 // Sync socket connection (for fields and initialization)
 static GSocketConnection *sync_connection = NULL;
@@ -47,6 +49,7 @@ typedef struct {
 // Async read context (no buffer needed - GDataInputStream handles it)
 typedef struct {
     gchar *call_id;
+    gchar *method;  // for dispatching values of a streamed (Go channel) result
 } AsyncReadContext;
 
 static GHashTable *pending_promises = NULL;  // Maps call_id -> PendingPromise
@@ -55,6 +58,7 @@ static GMutex promises_mutex;
 typedef struct {
     gchar *message;
     gchar *call_id;
+    gchar *method;
     JSCValue *resolve;
     JSCValue *reject;
     JSCContext *context;
@@ -71,12 +75,27 @@ static void event_read_callback (GObject *source_object, GAsyncResult *res, gpoi
 static void start_event_read_loop (void);
 static void free_event_listeners_array (gpointer data);
 
+// Builds the rejection value for a call that failed in Go: an Error whose
+// message is the Go error string and whose code is the runtime error code
+// (MethodNotFound, InvalidParams, AppError, ...), so pages can branch on it.
+static JSCValue *
+new_call_error (JSCContext *context, const gchar *message, const gchar *code)
+{
+    JSCValue *factory = jsc_context_evaluate(context,
+        "(function(m, c) { var e = new Error(m); if (c) e.code = c; return e; })", -1);
+    JSCValue *error_obj = jsc_value_function_call(factory,
+        G_TYPE_STRING, message, G_TYPE_STRING, code, G_TYPE_NONE);
+    g_object_unref(factory);
+    return error_obj;
+}
+
 static void
 free_async_request (AsyncRequest *req)
 {
     if (!req) return;
     g_free(req->message);
     g_free(req->call_id);
+    g_free(req->method);
     if (req->resolve) g_object_unref(req->resolve);
     if (req->reject) g_object_unref(req->reject);
     if (req->context) g_object_unref(req->context);
@@ -147,6 +166,7 @@ start_next_async_request (void)
 
     AsyncReadContext *ctx = g_new(AsyncReadContext, 1);
     ctx->call_id = g_strdup(req->call_id);
+    ctx->method = g_strdup(req->method);
 
     g_data_input_stream_read_line_async(async_data_input, G_PRIORITY_DEFAULT, NULL,
                                          async_read_callback, ctx);
@@ -154,6 +174,84 @@ start_next_async_request (void)
     free_async_request(req);
 }
 
+// Authenticate a new connection when the runtime requires a token: the
+// runtime writes it to AUTH_TOKEN_PATH, and every connection must open with
+// an __auth call carrying it. Without the file, no auth is needed. The reply
+// is read byte-by-byte so nothing is buffered ahead of later readers.
+static gboolean
+authenticate_connection (GOutputStream *output, GInputStream *input, const gchar *channel)
+{
+    gchar *token = NULL;
+    GError *error = NULL;
+
+    if (!g_file_get_contents(AUTH_TOKEN_PATH, &token, NULL, NULL))
+        return TRUE;
+    g_strstrip(token);
+
+    JsonBuilder *builder = json_builder_new();
+    JsonGenerator *generator = json_generator_new();
+    json_builder_begin_object(builder);
+    json_builder_set_member_name(builder, "id");
+    json_builder_add_string_value(builder, "auth");
+    json_builder_set_member_name(builder, "method");
+    json_builder_add_string_value(builder, "__auth");
+    json_builder_set_member_name(builder, "params");
+    json_builder_begin_array(builder);
+    json_builder_add_string_value(builder, token);
+    json_builder_end_array(builder);
+    json_builder_end_object(builder);
+
+    JsonNode *root = json_builder_get_root(builder);
+    json_generator_set_root(generator, root);
+    gchar *json_str = json_generator_to_data(generator, NULL);
+    gchar *message = g_strdup_printf("%s\n", json_str);
+    gsize bytes_written;
+    gboolean ok = g_output_stream_write_all(output, message, strlen(message),
+                                            &bytes_written, NULL, &error);
+
+    g_free(message);
+    g_free(json_str);
+    json_node_free(root);
+    g_object_unref(generator);
+    g_object_unref(builder);
+    g_free(token);
+
+    if (!ok) {
+        fprintf(stderr, "Strux Extension: Failed to send %s auth: %s\n", channel, error->message);
+        g_error_free(error);
+        return FALSE;
+    }
+
+    GString *response = g_string_new(NULL);
+    gchar byte;
+    while (TRUE) {
+        gssize bytes_read = g_input_stream_read(input, &byte, 1, NULL, &error);
+        if (bytes_read <= 0) {
+            if (error) {
+                fprintf(stderr, "Strux Extension: Failed to read %s auth reply: %s\n", channel, error->message);
+                g_error_free(error);
+            }
+            g_string_free(response, TRUE);
+            return FALSE;
+        }
+        if (byte == '\n')
+            break;
+        g_string_append_c(response, byte);
+    }
+
+    ok = FALSE;
+    JsonParser *parser = json_parser_new();
+    if (json_parser_load_from_data(parser, response->str, -1, NULL)) {
+        JsonObject *response_obj = json_node_get_object(json_parser_get_root(parser));
+        ok = response_obj != NULL && !json_object_has_member(response_obj, "error");
+    }
+    if (!ok)
+        fprintf(stderr, "Strux Extension: %s auth rejected: %s\n", channel, response->str);
+    g_object_unref(parser);
+    g_string_free(response, TRUE);
+    return ok;
+}
+
 // Connect to the sync IPC socket
 static gboolean
 connect_sync_ipc (void)
@@ -182,6 +280,14 @@ connect_sync_ipc (void)
     sync_output = g_io_stream_get_output_stream(G_IO_STREAM(sync_connection));
     sync_input = g_io_stream_get_input_stream(G_IO_STREAM(sync_connection));
 
+    if (!authenticate_connection(sync_output, sync_input, "sync")) {
+        g_object_unref(sync_connection);
+        sync_connection = NULL;
+        sync_output = NULL;
+        sync_input = NULL;
+        return FALSE;
+    }
+
     fprintf(stderr, "Strux Extension: Connected sync IPC socket\n");
     return TRUE;
 }
@@ -214,6 +320,13 @@ connect_async_ipc (void)
     async_output = g_io_stream_get_output_stream(G_IO_STREAM(async_connection));
     GInputStream *async_input = g_io_stream_get_input_stream(G_IO_STREAM(async_connection));
 
+    if (!authenticate_connection(async_output, async_input, "async")) {
+        g_object_unref(async_connection);
+        async_connection = NULL;
+        async_output = NULL;
+        return FALSE;
+    }
+
     // Wrap input stream with GDataInputStream for async line reading
     async_data_input = g_data_input_stream_new(async_input);
 
@@ -283,6 +396,13 @@ connect_event_ipc (void)
     event_output = g_io_stream_get_output_stream(G_IO_STREAM(event_connection));
     GInputStream *event_input = g_io_stream_get_input_stream(G_IO_STREAM(event_connection));
 
+    if (!authenticate_connection(event_output, event_input, "event")) {
+        g_object_unref(event_connection);
+        event_connection = NULL;
+        event_output = NULL;
+        return FALSE;
+    }
+
     // Wrap input stream with GDataInputStream for async line reading
     event_data_input = g_data_input_stream_new(event_input);
 
@@ -518,6 +638,7 @@ async_read_callback (GObject *source_object, GAsyncResult *res, gpointer user_da
         if (error) g_error_free(error);
         if (line) g_free(line);
         g_free(ctx->call_id);
+        g_free(ctx->method);
         g_free(ctx);
         return;
     }
@@ -537,6 +658,7 @@ async_read_callback (GObject *source_object, GAsyncResult *res, gpointer user_da
             g_object_unref(parser);
             g_free(line);
             g_free(ctx->call_id);
+            g_free(ctx->method);
             g_free(ctx);
 
             g_mutex_lock(&async_mutex);
@@ -546,11 +668,54 @@ async_read_callback (GObject *source_object, GAsyncResult *res, gpointer user_da
             return;
         }
 
+        if (promise && json_object_has_member(response_obj, "stream") &&
+            json_object_get_boolean_member(response_obj, "stream")) {
+            // One value of a Go method returning a channel. Hand it to
+            // strux.ipc.on('__callStream') listeners as { method, value } and
+            // keep reading: the promise settles on the response marked done.
+            JsonBuilder *stream_builder = json_builder_new();
+            json_builder_begin_object(stream_builder);
+            json_builder_set_member_name(stream_builder, "method");
+            json_builder_add_string_value(stream_builder, ctx->method);
+            json_builder_set_member_name(stream_builder, "value");
+            if (json_object_has_member(response_obj, "result")) {
+                json_builder_add_value(stream_builder,
+                    json_node_copy(json_object_get_member(response_obj, "result")));
+            } else {
+                json_builder_add_null_value(stream_builder);
+            }
+            json_builder_end_object(stream_builder);
+
+            JsonNode *stream_root = json_builder_get_root(stream_builder);
+            JsonGenerator *stream_gen = json_generator_new();
+            json_generator_set_root(stream_gen, stream_root);
+
+            // Dispatched directly (we are on the main loop) so every value
+            // reaches the page before the promise resolves
+            EventDispatch *dispatch = g_new(EventDispatch, 1);
+            dispatch->event_name = g_strdup("__callStream");
+            dispatch->json_data = json_generator_to_data(stream_gen, NULL);
+            g_mutex_unlock(&promises_mutex);
+            dispatch_event_to_js(dispatch);
+
+            g_object_unref(stream_gen);
+            json_node_free(stream_root);
+            g_object_unref(stream_builder);
+            g_object_unref(parser);
+            g_free(line);
+
+            g_data_input_stream_read_line_async(async_data_input, G_PRIORITY_DEFAULT, NULL,
+                                                 async_read_callback, ctx);
+            return;
+        }
+
         if (promise) {
             if (json_object_has_member(response_obj, "error") &&
                 strlen(json_object_get_string_member(response_obj, "error")) > 0) {
                 const gchar *error_msg = json_object_get_string_member(response_obj, "error");
-                JSCValue *error_obj = jsc_value_new_string(promise->context, error_msg);
+                const gchar *error_code = json_object_has_member(response_obj, "code")
+                    ? json_object_get_string_member(response_obj, "code") : NULL;
+                JSCValue *error_obj = new_call_error(promise->context, error_msg, error_code);
                 (void)jsc_value_function_call(promise->reject, JSC_TYPE_VALUE, error_obj, G_TYPE_NONE);
                 g_object_unref(error_obj);
             } else if (json_object_has_member(response_obj, "result")) {
@@ -633,6 +798,7 @@ async_read_callback (GObject *source_object, GAsyncResult *res, gpointer user_da
     g_object_unref(parser);
     g_free(line);  // Free the dynamically allocated line
     g_free(ctx->call_id);
+    g_free(ctx->method);
     g_free(ctx);
 
     g_mutex_lock(&async_mutex);
@@ -643,11 +809,13 @@ async_read_callback (GObject *source_object, GAsyncResult *res, gpointer user_da
 
 // Send a message asynchronously (uses async connection)
 static void
-send_ipc_message_async (const gchar *message, const gchar *call_id, JSCValue *resolve, JSCValue *reject, JSCContext *context)
+send_ipc_message_async (const gchar *message, const gchar *call_id, const gchar *method,
+                        JSCValue *resolve, JSCValue *reject, JSCContext *context)
 {
     AsyncRequest *req = g_new0(AsyncRequest, 1);
     req->message = g_strdup(message);
     req->call_id = g_strdup(call_id);
+    req->method = g_strdup(method);
     req->resolve = g_object_ref(resolve);
     req->reject = g_object_ref(reject);
     req->context = g_object_ref(context);
@@ -665,6 +833,28 @@ send_ipc_message_async (const gchar *message, const gchar *call_id, JSCValue *re
     }
 }
 
+// Send a fire-and-forget call on the async connection. The runtime never replies
+// to messages without an id, so nothing is queued for the async reader.
+static void
+send_ipc_notification (const gchar *message)
+{
+    if (!connect_async_ipc()) {
+        fprintf(stderr, "Strux Extension: Failed to connect to IPC, dropping call\n");
+        return;
+    }
+
+    gchar *msg_with_newline = g_strdup_printf("%s\n", message);
+    GError *error = NULL;
+    gsize bytes_written;
+
+    if (!g_output_stream_write_all(async_output, msg_with_newline, strlen(msg_with_newline),
+                                    &bytes_written, NULL, &error)) {
+        fprintf(stderr, "Strux Extension: Failed to write: %s\n", error->message);
+        g_error_free(error);
+    }
+    g_free(msg_with_newline);
+}
+
 // Synchronous version for field access and initialization (uses sync connection)
 static gchar*
 send_ipc_message_sync (const gchar *message)
@@ -726,7 +916,8 @@ send_ipc_message_sync (const gchar *message)
 
 // JavaScript function wrapper that calls Go methods asynchronously
 static JSCValue*
-js_call_go_method (const gchar *method_name, GPtrArray *arguments, JSCContext *context)
+js_call_go_method (const gchar *method_name, GPtrArray *arguments, JSCContext *context,
+                   gboolean fire_and_forget)
 {
     JsonBuilder *builder = json_builder_new();
     JsonGenerator *generator = json_generator_new();
@@ -736,10 +927,13 @@ js_call_go_method (const gchar *method_name, GPtrArray *arguments, JSCContext *c
     // Build JSON-RPC message
     json_builder_begin_object(builder);
 
-    // Add ID
-    json_builder_set_member_name(builder, "id");
-    gchar *call_id = g_strdup_printf("%u", g_atomic_int_add(&call_counter, 1));
-    json_builder_add_string_value(builder, call_id);
+    // Add ID (fire-and-forget calls have none, so the runtime sends no response)
+    gchar *call_id = NULL;
+    if (!fire_and_forget) {
+        json_builder_set_member_name(builder, "id");
+        call_id = g_strdup_printf("%u", g_atomic_int_add(&call_counter, 1));
+        json_builder_add_string_value(builder, call_id);
+    }
 
     // Add method name
     json_builder_set_member_name(builder, "method");
@@ -795,6 +989,17 @@ js_call_go_method (const gchar *method_name, GPtrArray *arguments, JSCContext *c
     json_generator_set_root(generator, root);
     json_str = json_generator_to_data(generator, NULL);
 
+    if (fire_and_forget) {
+        send_ipc_notification(json_str);
+
+        g_free(json_str);
+        json_node_free(root);
+        g_object_unref(generator);
+        g_object_unref(builder);
+
+        return jsc_context_evaluate(context, "Promise.resolve()", -1);
+    }
+
     // Create a new Promise using JavaScript
     // We need to extract the resolve/reject callbacks, so we use a wrapper pattern
     gchar *promise_code = g_strdup_printf(
@@ -818,7 +1023,7 @@ js_call_go_method (const gchar *method_name, GPtrArray *arguments, JSCContext *c
     JSCValue *reject = jsc_value_object_get_property(promise_with_callbacks, "__reject");
 
     // Send message asynchronously
-    send_ipc_message_async(json_str, call_id, resolve, reject, context);
+    send_ipc_message_async(json_str, call_id, method_name, resolve, reject, context);
 
     // Clean up (call_id is now owned by async function)
     g_free(call_id);
@@ -832,12 +1037,11 @@ js_call_go_method (const gchar *method_name, GPtrArray *arguments, JSCContext *c
     return promise_with_callbacks;
 }
 
-// Variadic callback wrapper for Go methods
+// Calls a Go method with the JS arguments, using the context of the first
+// argument when available
 static JSCValue*
-go_method_callback_variadic (GPtrArray *args, gpointer user_data)
+call_go_method_with_args (const gchar *method_name, GPtrArray *args, gboolean fire_and_forget)
 {
-    const gchar *method_name = (const gchar*)user_data;
-
     // Get context from first argument if available, or use current context
     JSCContext *context = NULL;
     if (args && args->len > 0) {
@@ -850,11 +1054,37 @@ go_method_callback_variadic (GPtrArray *args, gpointer user_data)
     }
 
     // Call Go backend asynchronously - returns a Promise
-    JSCValue *promise = js_call_go_method(method_name, args, context);
+    JSCValue *promise = js_call_go_method(method_name, args, context, fire_and_forget);
 
     return promise;
 }
 
+// Variadic callback wrapper for Go methods
+static JSCValue*
+go_method_callback_variadic (GPtrArray *args, gpointer user_data)
+{
+    return call_go_method_with_args((const gchar*)user_data, args, FALSE);
+}
+
+// Variadic callback wrapper for fire-and-forget Go methods. The returned
+// Promise resolves as soon as the call is written.
+static JSCValue*
+go_notify_callback_variadic (GPtrArray *args, gpointer user_data)
+{
+    return call_go_method_with_args((const gchar*)user_data, args, TRUE);
+}
+
+// Picks the callback for a bound method from its bindings entry
+static GCallback
+go_method_callback_for (JsonObject *method_info)
+{
+    if (json_object_has_member(method_info, "fireAndForget") &&
+        json_object_get_boolean_member(method_info, "fireAndForget")) {
+        return G_CALLBACK(go_notify_callback_variadic);
+    }
+    return G_CALLBACK(go_method_callback_variadic);
+}
+
 // Get field value from Go backend
 static JSCValue*
 get_field_value (const gchar *field_name, JSCContext *context)
@@ -1431,6 +1661,34 @@ inject_ipc_api (JSCContext *js_context)
 
     fprintf(stderr, "Strux Extension: Injected strux.ipc.on(), strux.ipc.off(), strux.ipc.send()\n");
 
+    // strux.readStream(handle, type?) assembles the __stream frames of a Go
+    // method that returned io.Reader into a Blob. Frames are only sent once
+    // __streamStart is received, so none are missed.
+    JSCValue *read_stream = jsc_context_evaluate(js_context,
+        "(function() {"
+        "  strux.readStream = function(handle, type) {"
+        "    var id = (handle && handle.stream !== undefined) ? handle.stream : handle;"
+        "    return new Promise(function(resolve, reject) {"
+        "      var chunks = [];"
+        "      var off = strux.ipc.on('__stream', function(frame) {"
+        "        if (!frame || frame.id !== id) return;"
+        "        if (frame.data) {"
+        "          var bin = atob(frame.data);"
+        "          var bytes = new Uint8Array(bin.length);"
+        "          for (var i = 0; i < bin.length; i++) bytes[i] = bin.charCodeAt(i);"
+        "          chunks.push(bytes);"
+        "        }"
+        "        if (!frame.done) return;"
+        "        off();"
+        "        if (frame.error) reject(new Error(frame.error));"
+        "        else resolve(new Blob(chunks, type ? { type: type } : undefined));"
+        "      });"
+        "      strux.ipc.send('__streamStart', id);"
+        "    });"
+        "  };"
+        "})()", -1);
+    g_object_unref(read_stream);
+
     g_object_unref(send_func);
     g_object_unref(off_func);
     g_object_unref(on_func);
@@ -1484,7 +1742,7 @@ bind_children (JSCContext *js_context, JSCValue *parent_obj, JsonObject *childre
                 JSCValue *func = jsc_value_new_function_variadic(
                     js_context,
                     method_name,
-                    G_CALLBACK(go_method_callback_variadic),
+                    go_method_callback_for(method_info),
                     full_method_name,  // freed by GDestroyNotify
                     (GDestroyNotify)g_free,
                     JSC_TYPE_VALUE
@@ -1619,7 +1877,7 @@ inject_bindings (JSCContext *js_context)
                                 JSCValue *func = jsc_value_new_function_variadic(
                                     js_context,
                                     method_name,
-                                    G_CALLBACK(go_method_callback_variadic),
+                                    go_method_callback_for(method_info),
                                     g_strdup(method_name),
                                     (GDestroyNotify)g_free,
                                     JSC_TYPE_VALUE
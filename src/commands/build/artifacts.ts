@@ -88,6 +88,8 @@ import clientGoScreen from "../../assets/client-base/screen.go" with { type: "te
 // @ts-ignore
 import clientGoUSBNet from "../../assets/client-base/usbnet.go" with { type: "text" }
 // @ts-ignore
+import clientGoStorage from "../../assets/client-base/storage.go" with { type: "text" }
+// @ts-ignore
 import clientGoMod from "../../assets/client-base/go.mod" with { type: "text" }
 // @ts-ignore
 import clientGoSum from "../../assets/client-base/go.sum" with { type: "text" }
@@ -314,6 +316,7 @@ export async function copyClientBaseFiles(clientSrcPath: string): Promise<void>
         await Bun.write(join(clientSrcPath, "exec.go"), clientGoExec)
         await Bun.write(join(clientSrcPath, "screen.go"), clientGoScreen)
         await Bun.write(join(clientSrcPath, "usbnet.go"), clientGoUSBNet)
+        await Bun.write(join(clientSrcPath, "storage.go"), clientGoStorage)
         await Bun.write(join(clientSrcPath, "websocket.go"), clientGoWebsocket)
         await Bun.write(join(clientSrcPath, "go.mod"), clientGoMod)
         await Bun.write(join(clientSrcPath, "go.sum"), clientGoSum)
@@ -500,6 +503,7 @@ export async function forceRestoreAllArtifacts(): Promise<void> {
     await Bun.write(join(clientSrcPath, "exec.go"), clientGoExec)
     await Bun.write(join(clientSrcPath, "screen.go"), clientGoScreen)
     await Bun.write(join(clientSrcPath, "usbnet.go"), clientGoUSBNet)
+    await Bun.write(join(clientSrcPath, "storage.go"), clientGoStorage)
     await Bun.write(join(clientSrcPath, "websocket.go"), clientGoWebsocket)
     await Bun.write(join(clientSrcPath, "go.mod"), clientGoMod)
     await Bun.write(join(clientSrcPath, "go.sum"), clientGoSum)
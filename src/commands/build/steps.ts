@@ -202,6 +202,21 @@ export async function writeDisplayConfig(bspName: string): Promise<void> {
     const displayConfigPath = join(bspCacheDir, ".display-config.json")
 
     const display = Settings.main?.display
+    const urls = display?.urls && display.urls.length > 0 ? { urls: display.urls } : {}
+    const webExtensionsDir = display?.web_extensions_dir ? { webExtensionsDir: display.web_extensions_dir } : {}
+    const splash = Settings.main?.boot?.splash
+    const splashSettings = {
+        ...(display?.splash_images && display.splash_images.length > 0 ? { splashImages: display.splash_images } : {}),
+        ...(splash?.enabled && splash.color ? { splashColor: splash.color } : {}),
+    }
+    const webkit = display?.webkit && (display.webkit.js_ram_size_mb || display.webkit.env?.length)
+        ? {
+            webkit: {
+                ...(display.webkit.js_ram_size_mb ? { jsRamSizeMB: display.webkit.js_ram_size_mb } : {}),
+                ...(display.webkit.env?.length ? { env: display.webkit.env } : {}),
+            },
+        }
+        : {}
     if (display?.monitors && display.monitors.length > 0) {
         // Use the display config from strux.yaml
         const config = {
@@ -210,7 +225,11 @@ export async function writeDisplayConfig(bspName: string): Promise<void> {
                 ...(m.resolution ? { resolution: m.resolution } : {}),
                 ...(m.transform ? { transform: m.transform } : {}),
                 ...(m.names && m.names.length > 0 ? { names: m.names } : {}),
-            }))
+            })),
+            ...urls,
+            ...webExtensionsDir,
+            ...splashSettings,
+            ...webkit,
         }
         await Bun.write(displayConfigPath, JSON.stringify(config))
         Logger.info(`Display config: ${display.monitors.length} monitor(s)`)
@@ -219,7 +238,11 @@ export async function writeDisplayConfig(bspName: string): Promise<void> {
         const width = Settings.bsp?.display?.width ?? 1920
         const height = Settings.bsp?.display?.height ?? 1080
         const config = {
-            monitors: [{ path: "/", resolution: `${width}x${height}` }]
+            monitors: [{ path: "/", resolution: `${width}x${height}` }],
+            ...urls,
+            ...webExtensionsDir,
+            ...splashSettings,
+            ...webkit,
         }
         await Bun.write(displayConfigPath, JSON.stringify(config))
     }
@@ -252,10 +275,22 @@ export async function updateDevEnvConfig(bspName: string): Promise<void> {
     const bspCacheDir = join(Settings.projectPath, "dist", "cache", bspName)
     const devEnvPath = join(bspCacheDir, ".dev-env.json")
     const usb = Settings.main?.dev?.usb
+    const logs = Settings.main?.dev?.logs
+    const server = Settings.main?.dev?.server
+
+    // Profiles keep the client's JSON key names; only keys set in strux.yaml are written
+    // so unset keys fall back to the top-level values on the device
+    const profiles = Object.fromEntries(Object.entries(server?.profiles ?? {}).map(([name, profile]) => [name, {
+        ...(profile.client_key !== undefined && { clientKey: profile.client_key }),
+        ...(profile.use_mdns_on_client !== undefined && { useMDNS: profile.use_mdns_on_client }),
+        ...(profile.mdns_eager_first !== undefined && { mdnsEagerFirst: profile.mdns_eager_first }),
+        ...(profile.fallback_hosts !== undefined && { fallbackHosts: profile.fallback_hosts }),
+    }]))
 
     const devEnvJSON = {
         clientKey: Settings.main?.dev?.server?.client_key ?? "",
         useMDNS: Settings.main?.dev?.server?.use_mdns_on_client ?? true,
+        mdnsEagerFirst: Settings.main?.dev?.server?.mdns_eager_first ?? false,
         fallbackHosts: Settings.main?.dev?.server?.fallback_hosts ?? [],
         inspector: {
             // Default to disabled - user must explicitly enable in strux.yaml
@@ -266,6 +301,12 @@ export async function updateDevEnvConfig(bspName: string): Promise<void> {
             enabled: usb?.enabled ?? true,
             subnet: usb?.subnet ?? "192.168.7.0/24",
         },
+        // Buffering is off unless enabled, so the client's defaults cover an absent block
+        ...(logs?.buffer_on_disconnect && {
+            logs: { bufferOnDisconnect: true, bufferLines: logs.buffer_lines ?? 1000 },
+        }),
+        ...(Object.keys(profiles).length > 0 && { profiles }),
+        ...(server?.default_profile && { defaultProfile: server.default_profile }),
     }
     await Bun.write(devEnvPath, JSON.stringify(devEnvJSON, null, 2))
 }
@@ -112,6 +112,76 @@ test("writeDisplayConfig writes the BSP display fallback when strux.yaml has no
     expect(inputMapExists).toBe(false)
 })
 
+test("writeDisplayConfig passes the web extensions directory to the client", async () => {
+    const cacheDir = await configureProjectCache()
+    Settings.main = {
+        display: {
+            web_extensions_dir: "/usr/lib/aarch64-linux-gnu/wpe-webkit-2.0/extensions",
+        },
+    } as any
+    Settings.bsp = {} as any
+
+    await writeDisplayConfig("qemu")
+
+    const displayConfig = await Bun.file(join(cacheDir, ".display-config.json")).json()
+
+    expect(displayConfig).toEqual({
+        monitors: [
+            {
+                path: "/",
+                resolution: "1920x1080",
+            },
+        ],
+        webExtensionsDir: "/usr/lib/aarch64-linux-gnu/wpe-webkit-2.0/extensions",
+    })
+})
+
+test("writeDisplayConfig passes splash candidates and the splash color to the client", async () => {
+    const cacheDir = await configureProjectCache()
+    Settings.main = {
+        boot: {
+            splash: {
+                enabled: true,
+                logo: "./assets/logo.png",
+                color: "1a2b3c",
+            },
+        },
+        display: {
+            splash_images: ["/strux/splash-{resolution}.jpg", "/strux/logo.png"],
+        },
+    } as any
+    Settings.bsp = {} as any
+
+    await writeDisplayConfig("qemu")
+
+    const displayConfig = await Bun.file(join(cacheDir, ".display-config.json")).json()
+
+    expect(displayConfig.splashImages).toEqual(["/strux/splash-{resolution}.jpg", "/strux/logo.png"])
+    expect(displayConfig.splashColor).toBe("1a2b3c")
+})
+
+test("writeDisplayConfig passes WebKit memory tuning to the client", async () => {
+    const cacheDir = await configureProjectCache()
+    Settings.main = {
+        display: {
+            webkit: {
+                js_ram_size_mb: 256,
+                env: ["JSC_useJIT=false"],
+            },
+        },
+    } as any
+    Settings.bsp = {} as any
+
+    await writeDisplayConfig("qemu")
+
+    const displayConfig = await Bun.file(join(cacheDir, ".display-config.json")).json()
+
+    expect(displayConfig.webkit).toEqual({
+        jsRamSizeMB: 256,
+        env: ["JSC_useJIT=false"],
+    })
+})
+
 test("updateDevEnvConfig writes the current dev server and inspector settings", async () => {
     const cacheDir = await configureProjectCache()
     Settings.main = {
@@ -0,0 +1,61 @@
+/***
+ *
+ *
+ * Binary Push Payloads
+ *
+ *
+ */
+import { join } from "path"
+import { Settings } from "../../settings"
+
+
+// strux-build-app.sh stamps the build time with -ldflags "-X main.BuildTimestamp=N".
+// Go keeps the build flags as plain text in the binary's build info.
+const BUILD_TIMESTAMP_PATTERN = /-X[= ]main\.BuildTimestamp=["']?(\d+)/
+
+
+export interface BinaryPushPayload {
+    data: string
+    buildTimestamp?: number
+    force?: boolean
+}
+
+
+/** Path of the compiled app binary for the active BSP */
+export function appBinaryPath(): string {
+    return join(Settings.projectPath, "dist", "cache", Settings.bspName!, "app", "main")
+}
+
+
+/** Build timestamp stamped into a Go binary, or undefined if it carries none */
+export function readBuildTimestamp(data: Buffer): number | undefined {
+
+    const match = BUILD_TIMESTAMP_PATTERN.exec(data.toString("latin1"))
+    if (!match) return undefined
+
+    const timestamp = Number(match[1])
+    return Number.isSafeInteger(timestamp) && timestamp > 0 ? timestamp : undefined
+
+}
+
+
+/**
+ * Reads a compiled binary into a push payload. Returns null if the file does
+ * not exist. force asks the device to install it even if it is older than the
+ * binary it is running.
+ */
+export async function readBinaryPayload(binaryPath: string, force = false): Promise<BinaryPushPayload | null> {
+
+    const binaryFile = Bun.file(binaryPath)
+    if (!await binaryFile.exists()) return null
+
+    const binaryData = Buffer.from(await binaryFile.arrayBuffer())
+    const payload: BinaryPushPayload = { data: binaryData.toString("base64") }
+
+    const buildTimestamp = readBuildTimestamp(binaryData)
+    if (buildTimestamp !== undefined) payload.buildTimestamp = buildTimestamp
+    if (force) payload.force = true
+
+    return payload
+
+}
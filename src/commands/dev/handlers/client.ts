@@ -5,11 +5,10 @@
  *
  *
  */
-import { join } from "path"
-import { Settings } from "../../../settings"
 import { Logger } from "../../../utils/log"
 import { DevServer } from "../index"
-import type { ClientMessageSendable, ClientMessageReceivable } from "../types"
+import { appBinaryPath, readBinaryPayload } from "../binary"
+import type { ClientMessageSendable, ClientMessageReceivable, LogStreamType } from "../types"
 import type { Socket } from "../socket-manager"
 import type { ResourceName } from "../ui/App"
 
@@ -71,6 +70,25 @@ export function registerClientHandlers(client: Socket<ClientMessageSendable, Cli
     })
 
 
+    client.on("log-stream-error", (payload, _ws) => {
+        Logger.warning(`Log stream ${payload.streamId} failed: ${payload.error}`)
+    })
+
+
+    // After a reconnect the client lists the log streams it is running;
+    // restart any of the device logs shown in the UI that did not come back
+    const resumedLogTypes: LogStreamType[] = ["journalctl", "app", "cage", "early"]
+
+    client.on("streams-resumed", (payload, ws) => {
+        const running = new Set(payload.streams.map((stream) => stream.type))
+        for (const type of resumedLogTypes) {
+            if (running.has(type)) continue
+            Logger.info(`Restarting ${type} log stream after reconnect`)
+            client.send(ws, { type: "start-logs", payload: { streamId: `resume-${type}-${Date.now()}`, type } })
+        }
+    })
+
+
     // Device info
     client.on("device-info", (payload, _ws) => {
         Logger.info(`Device connected: ${payload.ip}${payload.version ? ` (v${payload.version})` : ""}`)
@@ -83,9 +101,38 @@ export function registerClientHandlers(client: Socket<ClientMessageSendable, Cli
     })
 
 
+    // Startup summary, sent once the device has launched the app
+    client.on("ready", (payload, _ws) => {
+        const details = [
+            `resolution ${payload.resolution}`,
+            payload.splashImage ? `splash ${payload.splashImage}` : "",
+            `loading ${payload.backendUrl}`,
+            payload.inspector ? `inspector on port ${payload.inspectorPort}` : "",
+        ].filter(Boolean).join(", ")
+        Logger.info(`Device ready in ${payload.mode} mode after ${payload.bootMs}ms (${details})`)
+    })
+
+
     // Binary acknowledgments
     client.on("binary-ack", (payload, _ws) => {
-        Logger.info(`Binary ${payload.binary}: ${payload.status}`)
+        const reason = payload.reason ? ` (${payload.reason})` : ""
+        Logger.info(`Binary ${payload.binary}: ${payload.status}${reason}`)
+        if (payload.reason === "DOWNGRADE") {
+            Logger.warning("The device is running a newer binary. Use \"Force Push App Binary\" in the config menu to install this one anyway.")
+        }
+        dev.handleBinaryAck(payload.binary, payload.status, payload.reason)
+    })
+
+
+    // Binary push timing breakdown
+    client.on("binary-timing", (payload, _ws) => {
+        const stages = [
+            `decode ${payload.decodeMs}ms`,
+            `write ${payload.writeMs}ms`,
+            `verify ${payload.verifyMs}ms`,
+            payload.rebootScheduledMs !== undefined ? `reboot scheduled at ${payload.rebootScheduledMs}ms` : "",
+        ].filter(Boolean).join(", ")
+        Logger.info(`Binary push ${payload.status} in ${payload.totalMs}ms (${stages})`)
     })
 
 
@@ -157,14 +204,11 @@ export function registerClientHandlers(client: Socket<ClientMessageSendable, Cli
     // Binary requested — device is asking for the current binary
     client.on("binary-requested", async (_payload, ws) => {
 
-        const bspName = Settings.bspName!
-        const binaryPath = join(Settings.projectPath, "dist", "cache", bspName, "app", "main")
-        const binaryFile = Bun.file(binaryPath)
+        const binaryPath = appBinaryPath()
+        const payload = await readBinaryPayload(binaryPath)
 
-        if (await binaryFile.exists()) {
-            const binaryData = Buffer.from(await binaryFile.arrayBuffer())
-            const base64 = binaryData.toString("base64")
-            client.send(ws, { type: "binary-new", payload: { data: base64 } })
+        if (payload) {
+            client.send(ws, { type: "binary-new", payload })
             Logger.info("Binary sent to device (requested)")
 
         } else {
@@ -5,7 +5,7 @@
  *
  *
  */
-import { join } from "path"
+import { basename, join } from "path"
 import { readdir, rm, stat } from "node:fs/promises"
 import { randomUUID } from "node:crypto"
 import { Settings } from "../../settings"
@@ -23,12 +23,13 @@ import { Socket, SocketManager } from "./socket-manager"
 import { QEMUManager } from "./qemu"
 import { ViteManager } from "./vite"
 import { FileWatcher } from "./watcher"
+import { appBinaryPath, readBinaryPayload } from "./binary"
 import { MDNSPublisher } from "./mdns"
 import { DevUI } from "./ui"
 import { SSHManager } from "./ssh"
 import { registerClientHandlers } from "./handlers/client"
 import { registerScreenHandlers } from "./handlers/screen"
-import type { ClientMessageSendable, ClientMessageReceivable, ScreenMessageSendable, ScreenMessageReceivable } from "./types"
+import type { BinaryAckStatus, ClientMessageSendable, ClientMessageReceivable, ScreenMessageSendable, ScreenMessageReceivable } from "./types"
 
 
 export class DevServer {
@@ -50,6 +51,11 @@ export class DevServer {
         reject: (error: Error) => void
         timeout: ReturnType<typeof setTimeout>
     }>()
+    private binaryAckWaiters = new Map<string, {
+        resolve: () => void
+        reject: (error: Error) => void
+        timeout: ReturnType<typeof setTimeout>
+    }>()
     private systemUpdateAckWaiter: {
         resolve: () => void
         reject: (error: Error) => void
@@ -217,6 +223,12 @@ export class DevServer {
         this.vite.onOutput = (line) => {
             this.ui.store.appendLog("vite", { level: "info", message: line, timestamp: Date.now() })
         }
+        // Full page reloads from Vite reload Cog on the device; the client
+        // coalesces a burst of them into one reload
+        this.vite.onReload = () => {
+            const client = this.sockets.get("client")
+            if (client.hasClients()) client.broadcast({ type: "reload" })
+        }
         this.qemu.onOutput = (line) => {
             this.ui.store.appendLog("qemu", { level: "info", message: line, timestamp: Date.now() })
         }
@@ -423,7 +435,6 @@ export class DevServer {
                     await sendComponent(cagePath, "/usr/bin/cage")
                     await sendComponent(wpePath, "/usr/lib/wpe-web-extensions/libstrux-extension.so")
                     await sendComponent(join(Settings.projectPath, "dist", "cache", bspName, "app", "main"), "/strux/.main-update")
-                    await sendComponent(clientPath, "/strux/.client-update")
                     await sendComponent(cogPath, "/usr/bin/cog")
                     await sendComponent(screenPath, "/usr/bin/strux-screen")
                     await sendComponent(frontendArchivePath, "/strux/frontend")
@@ -440,6 +451,15 @@ export class DevServer {
                     const cageEnvPath = join(Settings.projectPath, "dist", "cache", bspName, ".cage-env")
                     await sendComponent(cageEnvPath, "/strux/.cage-env")
 
+                    // The client goes last: once it is installed the device schedules
+                    // its own reboot, which must not cut off the pushes above
+                    const clientPayload = await readBinaryPayload(clientPath)
+                    if (clientPayload) {
+                        const ack = this.waitForBinaryAck("client")
+                        client.broadcast({ type: "new-client-binary", payload: clientPayload })
+                        await ack
+                    }
+
                     Logger.info("Sending reboot command to device...")
                     client.broadcast({ type: "system-restart" })
                     Logger.success("Components and frontend transferred to device, rebooting...")
@@ -451,6 +471,24 @@ export class DevServer {
                     }
                 }
 
+            } else if (action === "force-push-binary") {
+
+                const client = this.sockets.get("client")
+                if (!client.hasClients()) {
+                    Logger.error("Cannot push binary: No device connected")
+                    this.ui.store.setConfigBusy(false)
+                    return
+                }
+
+                const binaryPath = appBinaryPath()
+                if (!await Bun.file(binaryPath).exists()) {
+                    throw new Error(`Compiled binary not found at ${binaryPath}`)
+                }
+
+                await this.watcher.sendBinaryToDevice(true)
+                Logger.success("App binary force-pushed to device")
+                this.ui.store.flashConfigSuccess("App binary force-pushed; device will install it even if older")
+
             } else if (action === "rebuild-builder") {
 
                 Logger.info("Rebuilding Docker builder image...")
@@ -486,6 +524,19 @@ export class DevServer {
                 Logger.success("Restart command sent to device")
                 this.ui.store.flashConfigSuccess("Strux service restart command sent")
 
+            } else if (action === "reload") {
+
+                const client = this.sockets.get("client")
+                if (!client.hasClients()) {
+                    Logger.error("Cannot reload display: No device connected")
+                    this.ui.store.setConfigBusy(false)
+                    return
+                }
+
+                client.broadcast({ type: "reload" })
+                Logger.success("Reload command sent to device")
+                this.ui.store.flashConfigSuccess("Reload command sent to device")
+
             } else if (action === "reboot") {
 
                 const client = this.sockets.get("client")
@@ -753,6 +804,56 @@ export class DevServer {
     }
 
 
+    waitForBinaryAck(binaryName: string, timeoutMs = 30000): Promise<void> {
+
+        const existing = this.binaryAckWaiters.get(binaryName)
+        if (existing) {
+            clearTimeout(existing.timeout)
+            existing.reject(new Error(`Binary ack waiter replaced for ${binaryName}`))
+            this.binaryAckWaiters.delete(binaryName)
+        }
+
+        return new Promise((resolve, reject) => {
+            const timeout = setTimeout(() => {
+                this.binaryAckWaiters.delete(binaryName)
+                reject(new Error(`Timed out waiting for binary ack: ${binaryName}`))
+            }, timeoutMs)
+
+            this.binaryAckWaiters.set(binaryName, {
+                resolve: () => {
+                    clearTimeout(timeout)
+                    this.binaryAckWaiters.delete(binaryName)
+                    resolve()
+                },
+                reject: (error) => {
+                    clearTimeout(timeout)
+                    this.binaryAckWaiters.delete(binaryName)
+                    reject(error)
+                },
+                timeout,
+            })
+        })
+
+    }
+
+
+    // Binary acks name the installed path, which moves to the writable
+    // directory on read-only images, so waiters are keyed by file name
+    handleBinaryAck(binaryPath: string, status: BinaryAckStatus, reason?: string): void {
+
+        const waiter = this.binaryAckWaiters.get(basename(binaryPath))
+        if (!waiter) return
+
+        if (status === "error" || status === "busy") {
+            waiter.reject(new Error(`Binary transfer failed: ${binaryPath} (${reason || status})`))
+            return
+        }
+
+        waiter.resolve()
+
+    }
+
+
     waitForSystemUpdateAck(timeoutMs = 30000): Promise<void> {
 
         if (this.systemUpdateAckWaiter) {
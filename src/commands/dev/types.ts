@@ -12,16 +12,29 @@
 
 // Logging Messages
 type LogLineType = "journalctl" | "service" | "app" | "cage" | "screen" | "early" | "client"
-interface ClientMessageReceiveLog {type: "log-line", payload: { type: LogLineType, line: string, timestamp: string }}
+interface ClientMessageReceiveLog {type: "log-line", payload: { type: LogLineType, line: string, timestamp: string, machineId?: string, hostname?: string }}
+export type LogStreamType = "journalctl" | "service" | "app" | "cage" | "early"
+type JournalOutputFormat = "short" | "short-precise" | "short-iso" | "short-iso-precise" | "short-monotonic" | "short-unix" | "with-unit" | "verbose" | "json" | "json-pretty" | "cat"
+interface ClientMessageStartLogs {type: "start-logs", payload: { streamId: string, type: LogStreamType, service?: string, outputFormat?: JournalOutputFormat }}
+interface ClientMessageStopLogs {type: "stop-logs", payload: { streamId: string }}
+interface ClientMessageLogStreamError {type: "log-stream-error", payload: { streamId: string, error: string }}
+interface ClientMessageStreamsResumed {type: "streams-resumed", payload: { streams: { streamId: string, type: LogStreamType }[] }}
 
 
 // Binary Push
-interface ClientMessageBinaryNew {type: "binary-new", payload: { data: string }}
+interface ClientMessageBinaryNew {type: "binary-new", payload: { data: string, buildTimestamp?: number, force?: boolean }}
+// Strux client binary push; acknowledged with binary-ack for /strux/client
+interface ClientMessageNewClientBinary {type: "new-client-binary", payload: { data: string, buildTimestamp?: number, force?: boolean }}
 
 // Sending Binary Acknowledgments
-type BinaryAckStatus = "skipped" | "updated" | "error"
-interface ClientMessageBinaryAck {type: "binary-ack", payload: { status: BinaryAckStatus, binary: string, currentChecksum?: string, receivedChecksum?: string}}
+export type BinaryAckStatus = "skipped" | "updated" | "busy" | "error"
+interface ClientMessageBinaryAck {type: "binary-ack", payload: { status: BinaryAckStatus, binary: string, currentChecksum?: string, receivedChecksum?: string, reason?: string}}
+interface ClientMessageBinaryTiming {type: "binary-timing", payload: { status: BinaryAckStatus, bytes: number, decodeMs: number, writeMs: number, verifyMs: number, totalMs: number, rebootScheduledMs?: number }}
 interface ClientMessageBinaryRequested {type: "binary-requested"}
+interface ClientMessageBinaryRebootCancel {type: "binary-reboot-cancel"}
+
+// Asks Cog to reload; the client coalesces bursts within 200ms into one reload
+interface ClientMessageReload {type: "reload"}
 
 // Components
 interface ClientMessageComponent { type: "component", payload: { data: string, destPath: string }}
@@ -34,6 +47,7 @@ interface DeviceInfoInspectorPort { path: string, port: number }
 interface DeviceInfoOutputInfo { name: string, label?: string }
 interface ClientMessageDeviceInfo { type: "device-info", payload: { ip: string, inspectorPorts: DeviceInfoInspectorPort[], outputs?: DeviceInfoOutputInfo[], version?: string }}
 interface ClientMessageDeviceInfoRequested { type: "device-info-requested" }
+interface ClientMessageReady { type: "ready", payload: { mode: "dev" | "production", host?: string, resolution: string, splashImage?: string, splashColor?: string, backendUrl: string, inspector: boolean, inspectorPort?: number, bootMs: number }}
 
 // Screen
 interface ClientMessageScreenRequest { type: "screen-request", payload: { outputName: string, serverHostURL: string }}
@@ -66,7 +80,12 @@ interface ClientMessageSystemRestartStrux { type: "system-restart-strux" }
 
 export type ClientMessageSendable = |
     ClientMessageBinaryNew |
+    ClientMessageNewClientBinary |
     ClientMessageBinaryAck |
+    ClientMessageBinaryRebootCancel |
+    ClientMessageReload |
+    ClientMessageStartLogs |
+    ClientMessageStopLogs |
     ClientMessageComponent |
     ClientMessageComponentArchive |
     ClientMessageDeviceInfoRequested |
@@ -87,11 +106,15 @@ export type ClientMessageSendable = |
 
 export type ClientMessageReceivable = |
     ClientMessageReceiveLog |
+    ClientMessageLogStreamError |
+    ClientMessageStreamsResumed |
     ClientMessageBinaryRequested |
     ClientMessageBinaryAck |
+    ClientMessageBinaryTiming |
     ClientMessageComponentAck |
     ClientMessageComponentArchiveAck |
     ClientMessageDeviceInfo |
+    ClientMessageReady |
     ClientMessageSystemUpdateAck |
     ClientMessageUpdateProgress |
     ClientMessageUpdateCheckRequest |
@@ -11,7 +11,7 @@ import { Box, useInput, useStdout } from "ink"
 import { theme } from "./theme"
 
 
-export type ConfigAction = "restore" | "rebuild-transfer" | "rebuild-builder" | "install-update" | "restart-service" | "reboot" | "flash"
+export type ConfigAction = "restore" | "rebuild-transfer" | "force-push-binary" | "rebuild-builder" | "install-update" | "restart-service" | "reload" | "reboot" | "flash"
 
 
 interface ConfigSection {
@@ -26,6 +26,7 @@ const CONFIG_SECTIONS: ConfigSection[] = [
         items: [
             { label: "Restore Strux Artifacts to Built-in Version", action: "restore" },
             { label: "Rebuild Strux Components and Transfer To Device", action: "rebuild-transfer" },
+            { label: "Force Push App Binary (Allow Downgrade)", action: "force-push-binary" },
             { label: "Rebuild Strux-Builder Docker Image", action: "rebuild-builder" },
             { label: "Install Latest System Update Bundle", action: "install-update" },
         ],
@@ -35,6 +36,7 @@ const CONFIG_SECTIONS: ConfigSection[] = [
         items: [
             { label: "Flash Device", action: "flash" },
             { label: "Restart Strux Service", action: "restart-service" },
+            { label: "Reload Display", action: "reload" },
             { label: "Reboot System", action: "reboot" },
         ],
     },
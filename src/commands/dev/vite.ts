@@ -20,12 +20,19 @@ function viteChildEnv(): NodeJS.ProcessEnv {
 }
 
 
+// Vite logs "page reload" when a change can't be hot-updated and "server
+// restarted" after a config change; both mean the device page must reload
+const FULL_RELOAD_PATTERN = /\bpage reload\b|\bserver restarted\b/
+const ANSI_PATTERN = /\x1b\[[0-9;]*m/g
+
+
 export class ViteManager {
 
     private process: Subprocess | null = null
     private containerName = "strux-vite-dev"
 
     onOutput: ((line: string) => void) | null = null
+    onReload: (() => void) | null = null
 
 
     async start(): Promise<void> {
@@ -86,6 +93,10 @@ export class ViteManager {
             Logger.info(`[vite] ${line}`)
         }
 
+        if (this.onReload && FULL_RELOAD_PATTERN.test(line.replace(ANSI_PATTERN, ""))) {
+            this.onReload()
+        }
+
     }
 
 
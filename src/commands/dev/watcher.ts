@@ -5,7 +5,6 @@
  *
  *
  */
-import { join } from "path"
 import { Settings } from "../../settings"
 import { Logger } from "../../utils/log"
 import { Runner } from "../../utils/run"
@@ -13,6 +12,7 @@ import { MainYAMLValidator } from "../../types/main-yaml"
 import { compileApplication } from "../build/steps"
 import { build as buildCommand } from "../build"
 import { DevServer } from "./index"
+import { appBinaryPath, readBinaryPayload } from "./binary"
 
 
 const DEBOUNCE_MS = 300
@@ -244,7 +244,11 @@ export class FileWatcher {
     }
 
 
-    private async sendBinaryToDevice(): Promise<void> {
+    /**
+     * Pushes the compiled app binary to the device. With force the device
+     * installs it even if it is older than the binary it is running.
+     */
+    async sendBinaryToDevice(force = false): Promise<void> {
 
         const dev = DevServer.getInstance()
         const client = dev.sockets.get("client")
@@ -256,23 +260,19 @@ export class FileWatcher {
 
         }
 
-        const bspName = Settings.bspName!
-        const binaryPath = join(Settings.projectPath, "dist", "cache", bspName, "app", "main")
-        const binaryFile = Bun.file(binaryPath)
+        const binaryPath = appBinaryPath()
+        const payload = await readBinaryPayload(binaryPath, force)
 
-        if (!await binaryFile.exists()) {
+        if (!payload) {
 
             this.emit(`Compiled binary not found at ${binaryPath}`)
             return
 
         }
 
-        const binaryData = Buffer.from(await binaryFile.arrayBuffer())
-        const base64 = binaryData.toString("base64")
+        client.broadcast({ type: "binary-new", payload })
 
-        client.broadcast({ type: "binary-new", payload: { data: base64 } })
-
-        this.emit("Binary sent to device")
+        this.emit(force ? "Binary force-pushed to device" : "Binary sent to device")
 
     }
 
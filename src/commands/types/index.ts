@@ -143,6 +143,25 @@ async function runDTSGeneration(
     }
 }
 
+/**
+ * Compare two introspection JSON outputs and return the human-readable list of
+ * added (+), removed (-) and changed (~) methods, fields and structs
+ */
+export async function diffIntrospection(
+    oldPath: string,
+    newPath: string,
+    binaryPath?: string
+): Promise<string> {
+    const binary = binaryPath ?? await getIntrospectBinaryPath()
+
+    const result = await $`${binary} diff ${oldPath} ${newPath}`.quiet()
+    if (result.exitCode !== 0) {
+        const stderr = result.stderr.toString().trim()
+        throw new Error(`strux-introspect diff failed with exit code ${result.exitCode}${stderr ? `: ${stderr}` : ""}`)
+    }
+    return result.stdout.toString()
+}
+
 /**
  * Generate .d.ts file from a Go main.go file
  */
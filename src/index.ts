@@ -139,6 +139,21 @@ program.command("types")
     })
 
 
+program.command("diff")
+    .argument("<old>", "Introspection JSON from before the change")
+    .argument("<new>", "Introspection JSON from after the change")
+    .description("Show methods, fields and structs that changed between two introspection outputs")
+    .action(async (oldPath: string, newPath: string) => {
+        const { diffIntrospection } = await import("./commands/types")
+
+        try {
+            process.stdout.write(await diffIntrospection(oldPath, newPath))
+        } catch (err) {
+            Logger.errorWithExit(`Diff failed: ${err instanceof Error ? err.message : String(err)}`)
+        }
+    })
+
+
 program.command("build")
     .description("Build a complete OS image for a BSP")
     .argument("<bsp>", "The board support package to build for")
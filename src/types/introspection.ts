@@ -9,6 +9,7 @@ import { z } from "zod"
 
 // Type definition for a single type (Go and TypeScript representations)
 export const TypeDefSchema = z.object({
+    name: z.string().optional(),
     goType: z.string(),
     tsType: z.string(),
 })
@@ -17,8 +18,12 @@ export type TypeDef = z.infer<typeof TypeDefSchema>;
 // Field definition for struct fields
 export const FieldDefSchema = z.object({
     name: z.string(),
+    jsonName: z.string().optional(),
+    optional: z.boolean().optional(),
     goType: z.string(),
     tsType: z.string(),
+    group: z.string().optional(),
+    default: z.string().optional(),
 })
 export type FieldDef = z.infer<typeof FieldDefSchema>;
 
@@ -27,6 +32,7 @@ export const ParamDefSchema = z.object({
     name: z.string().optional(),
     goType: z.string(),
     tsType: z.string(),
+    optional: z.boolean().optional(),
 })
 export type ParamDef = z.infer<typeof ParamDefSchema>;
 
@@ -36,6 +42,8 @@ export const MethodDefSchema = z.object({
     params: z.array(ParamDefSchema),
     returnTypes: z.array(TypeDefSchema),
     hasError: z.boolean(),
+    fireAndForget: z.boolean().optional(),
+    namedResults: z.boolean().optional(),
 })
 export type MethodDef = z.infer<typeof MethodDefSchema>;
 
@@ -46,20 +54,47 @@ export const StructDefSchema = z.object({
 })
 export type StructDef = z.infer<typeof StructDefSchema>;
 
+// Interface definition for named interfaces declared in the app package
+export const InterfaceDefSchema = z.object({
+    embeds: z.array(z.string()).optional(),
+    methods: z.array(MethodDefSchema),
+})
+export type InterfaceDef = z.infer<typeof InterfaceDefSchema>;
+
 // App info - the main application struct
 export const AppInfoSchema = z.object({
     name: z.string(),
     packageName: z.string(),
     fields: z.array(FieldDefSchema),
     methods: z.array(MethodDefSchema),
+    version: z.string().optional(),
+    buildMeta: z.record(z.string(), z.string()).optional(),
 })
 export type AppInfo = z.infer<typeof AppInfoSchema>;
 
+// Event registered with rt.RegisterEvent
+export const EventDefSchema = z.object({
+    name: z.string(),
+    goType: z.string().optional(),
+    tsType: z.string(),
+})
+export type EventDef = z.infer<typeof EventDefSchema>;
+
+// Exported package-level constant with a literal value
+export const ConstantDefSchema = z.object({
+    goType: z.string(),
+    tsType: z.string(),
+    value: z.union([z.string(), z.number(), z.boolean()]),
+})
+export type ConstantDef = z.infer<typeof ConstantDefSchema>;
+
 // Extension method info
 export const ExtensionMethodSchema = z.object({
     name: z.string(),
     paramCount: z.number(),
     paramTypes: z.array(z.string()),
+    paramTypeNames: z.array(z.string()).optional(),
+    returnTypeNames: z.array(z.string()).optional(),
 })
 export type ExtensionMethod = z.infer<typeof ExtensionMethodSchema>;
 
@@ -73,10 +108,13 @@ export type ExtensionSubNamespace = z.infer<typeof ExtensionSubNamespaceSchema>;
 export const IntrospectionOutputSchema = z.object({
     app: AppInfoSchema,
     structs: z.record(z.string(), StructDefSchema),
+    interfaces: z.record(z.string(), InterfaceDefSchema).optional(),
     extensions: z.record(
         z.string(),
         z.record(z.string(), ExtensionSubNamespaceSchema)
     ).optional(),
+    events: z.array(EventDefSchema).optional(),
+    constants: z.record(z.string(), ConstantDefSchema).optional(),
 })
 export type IntrospectionOutput = z.infer<typeof IntrospectionOutputSchema>;
 
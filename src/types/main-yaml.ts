@@ -128,11 +128,23 @@ const DevFallbackHostSchema = z.object({
     port: z.number().int().positive(),
 })
 
+// Dev server profile schema - overrides the top-level server settings when selected
+// on the device with STRUX_PROFILE (or default_profile)
+const DevServerProfileSchema = z.object({
+    fallback_hosts: z.array(DevFallbackHostSchema).optional(),
+    use_mdns_on_client: z.boolean().optional(),
+    mdns_eager_first: z.boolean().optional(),
+    client_key: z.string().optional(),
+})
+
 // Dev server configuration schema
 const DevServerSchema = z.object({
     fallback_hosts: z.array(DevFallbackHostSchema).optional(),
     use_mdns_on_client: z.boolean(),
+    mdns_eager_first: z.boolean().optional(),
     client_key: z.string(),
+    profiles: z.record(z.string(), DevServerProfileSchema).optional(),
+    default_profile: z.string().optional(),
 })
 
 // WebKit Inspector configuration schema
@@ -174,11 +186,18 @@ const DevUSBSchema = z.object({
         .default("192.168.7.0/24"),
 })
 
+// Dev log streaming schema
+const DevLogsSchema = z.object({
+    buffer_on_disconnect: z.boolean().default(false),
+    buffer_lines: z.number().int().positive().default(1000),
+})
+
 // Dev configuration schema
 const DevSchema = z.object({
     server: DevServerSchema.optional(),
     inspector: DevInspectorSchema.optional(),
     usb: DevUSBSchema.optional(),
+    logs: DevLogsSchema.optional(),
 })
 
 const OutputTransformSchema = z.union([
@@ -198,9 +217,24 @@ const DisplayMonitorSchema = z.object({
     input_devices: z.array(z.string()).optional(),
 })
 
+// WebKit memory tuning schema
+const DisplayWebKitSchema = z.object({
+    // JavaScriptCore sizes its heap as if the device had this much RAM
+    js_ram_size_mb: z.number().int().positive().optional(),
+    // Extra environment variables for Cog and WebKit
+    env: z.array(z.string().regex(/^[A-Za-z_][A-Za-z0-9_]*=/, "env entries must be KEY=VALUE")).optional(),
+})
+
 // Display configuration schema
 const DisplaySchema = z.object({
-    monitors: z.array(DisplayMonitorSchema).min(1),
+    monitors: z.array(DisplayMonitorSchema).min(1).optional(),
+    // Production URLs tried in order before falling back to the bundled frontend
+    urls: z.array(z.string().url()).optional(),
+    // Directory Cog loads WPE web extensions from, for images with a different WebKit layout
+    web_extensions_dir: z.string().startsWith("/", "web_extensions_dir must be an absolute path").optional(),
+    // Splash images tried in order on the device; {resolution} is replaced with the display resolution
+    splash_images: z.array(z.string().startsWith("/", "splash_images entries must be absolute paths")).optional(),
+    webkit: DisplayWebKitSchema.optional(),
 })
 
 // Main strux.yaml schema
@@ -24,6 +24,34 @@ export const STRUX_RUNTIME_TYPES = {
             "params": [],
             "returnTypes": [],
             "hasError": true
+          },
+          {
+            "name": "RequestFactoryReset",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "string",
+                "tsType": "string"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "FactoryReset",
+            "params": [
+              {
+                "name": "token",
+                "goType": "string",
+                "tsType": "string"
+              },
+              {
+                "name": "includeDevConfig",
+                "goType": "bool",
+                "tsType": "boolean"
+              }
+            ],
+            "returnTypes": [],
+            "hasError": true
           }
         ]
       },
@@ -185,6 +213,34 @@ export const STRUX_RUNTIME_TYPES = {
             "returnTypes": [],
             "hasError": true
           },
+          {
+            "name": "Outputs",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "[]OutputInfo",
+                "tsType": "StruxRuntime.OutputInfo[]"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "SetMode",
+            "params": [
+              {
+                "name": "output",
+                "goType": "string",
+                "tsType": "string"
+              },
+              {
+                "name": "mode",
+                "goType": "string",
+                "tsType": "string"
+              }
+            ],
+            "returnTypes": [],
+            "hasError": true
+          },
           {
             "name": "SetListedMode",
             "params": [
@@ -328,7 +384,7 @@ export const STRUX_RUNTIME_TYPES = {
               {
                 "name": "transform",
                 "goType": "OutputTransform",
-                "tsType": "string"
+                "tsType": "\"normal\" | \"90\" | \"180\" | \"270\" | \"flipped\" | \"flipped-90\" | \"flipped-180\" | \"flipped-270\""
               },
               {
                 "name": "opts",
@@ -372,6 +428,29 @@ export const STRUX_RUNTIME_TYPES = {
             ],
             "returnTypes": [],
             "hasError": true
+          },
+          {
+            "name": "GetBrightness",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "int",
+                "tsType": "number"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "SetBrightness",
+            "params": [
+              {
+                "name": "percent",
+                "goType": "int",
+                "tsType": "number"
+              }
+            ],
+            "returnTypes": [],
+            "hasError": true
           }
         ]
       },
@@ -462,6 +541,45 @@ export const STRUX_RUNTIME_TYPES = {
             ],
             "returnTypes": [],
             "hasError": true
+          },
+          {
+            "name": "ScanWiFi",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "[]WiFiNetwork",
+                "tsType": "StruxRuntime.WiFiNetwork[]"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "ConnectWiFi",
+            "params": [
+              {
+                "name": "ssid",
+                "goType": "string",
+                "tsType": "string"
+              },
+              {
+                "name": "psk",
+                "goType": "string",
+                "tsType": "string"
+              }
+            ],
+            "returnTypes": [],
+            "hasError": true
+          },
+          {
+            "name": "WiFiStatus",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "string",
+                "tsType": "string"
+              }
+            ],
+            "hasError": true
           }
         ]
       },
@@ -525,6 +643,149 @@ export const STRUX_RUNTIME_TYPES = {
               }
             ],
             "hasError": true
+          },
+          {
+            "name": "GetVolume",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "int",
+                "tsType": "number"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "SetVolume",
+            "params": [
+              {
+                "name": "percent",
+                "goType": "int",
+                "tsType": "number"
+              }
+            ],
+            "returnTypes": [],
+            "hasError": true
+          },
+          {
+            "name": "SetMuted",
+            "params": [
+              {
+                "name": "muted",
+                "goType": "bool",
+                "tsType": "boolean"
+              }
+            ],
+            "returnTypes": [],
+            "hasError": true
+          },
+          {
+            "name": "LastCogExit",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "CogExitInfo",
+                "tsType": "StruxRuntime.CogExitInfo"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "StreamCogLog",
+            "params": [],
+            "returnTypes": [],
+            "hasError": true
+          },
+          {
+            "name": "StopCogLog",
+            "params": [],
+            "returnTypes": [],
+            "hasError": true
+          },
+          {
+            "name": "DiskUsage",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "DiskUsage",
+                "tsType": "StruxRuntime.DiskUsage"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "DiskCleanup",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "int64",
+                "tsType": "number"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "Health",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "HealthReport",
+                "tsType": "StruxRuntime.HealthReport"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "RecentLogs",
+            "params": [
+              {
+                "name": "n",
+                "goType": "int",
+                "tsType": "number"
+              }
+            ],
+            "returnTypes": [
+              {
+                "goType": "[]string",
+                "tsType": "string[]"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "GetTimezone",
+            "params": [],
+            "returnTypes": [
+              {
+                "goType": "string",
+                "tsType": "string"
+              }
+            ],
+            "hasError": true
+          },
+          {
+            "name": "SetTimezone",
+            "params": [
+              {
+                "name": "tz",
+                "goType": "string",
+                "tsType": "string"
+              }
+            ],
+            "returnTypes": [],
+            "hasError": true
+          },
+          {
+            "name": "SetTime",
+            "params": [
+              {
+                "name": "rfc3339",
+                "goType": "string",
+                "tsType": "string"
+              }
+            ],
+            "returnTypes": [],
+            "hasError": true
           }
         ]
       },
@@ -739,6 +1000,59 @@ export const STRUX_RUNTIME_TYPES = {
         }
       ]
     },
+    "CogExitInfo": {
+      "fields": [
+        {
+          "name": "exited",
+          "goType": "bool",
+          "tsType": "boolean"
+        },
+        {
+          "name": "output",
+          "goType": "string",
+          "tsType": "string"
+        },
+        {
+          "name": "pid",
+          "goType": "int",
+          "tsType": "number"
+        },
+        {
+          "name": "exitCode",
+          "goType": "int",
+          "tsType": "number"
+        },
+        {
+          "name": "signal",
+          "goType": "int",
+          "tsType": "number"
+        },
+        {
+          "name": "exitedAt",
+          "goType": "string",
+          "tsType": "string"
+        },
+        {
+          "name": "logLines",
+          "goType": "[]string",
+          "tsType": "string[]"
+        }
+      ]
+    },
+    "CogHealth": {
+      "fields": [
+        {
+          "name": "running",
+          "goType": "bool",
+          "tsType": "boolean"
+        },
+        {
+          "name": "pid",
+          "goType": "int",
+          "tsType": "number"
+        }
+      ]
+    },
     "CustomModeSelection": {
       "fields": [
         {
@@ -815,6 +1129,30 @@ export const STRUX_RUNTIME_TYPES = {
         }
       ]
     },
+    "DevServerHealth": {
+      "fields": [
+        {
+          "name": "connected",
+          "goType": "bool",
+          "tsType": "boolean"
+        },
+        {
+          "name": "host",
+          "goType": "string",
+          "tsType": "string"
+        },
+        {
+          "name": "port",
+          "goType": "int",
+          "tsType": "number"
+        },
+        {
+          "name": "rttMs",
+          "goType": "int64",
+          "tsType": "number"
+        }
+      ]
+    },
     "DevState": {
       "fields": [
         {
@@ -843,6 +1181,20 @@ export const STRUX_RUNTIME_TYPES = {
         }
       ]
     },
+    "DiskUsage": {
+      "fields": [
+        {
+          "name": "filesystems",
+          "goType": "[]FilesystemUsage",
+          "tsType": "FilesystemUsage[]"
+        },
+        {
+          "name": "reclaimableBytes",
+          "goType": "int64",
+          "tsType": "number"
+        }
+      ]
+    },
     "DisplayApplyOptions": {
       "fields": [
         {
@@ -931,7 +1283,7 @@ export const STRUX_RUNTIME_TYPES = {
         {
           "name": "transform",
           "goType": "OutputTransform",
-          "tsType": "string"
+          "tsType": "\"normal\" | \"90\" | \"180\" | \"270\" | \"flipped\" | \"flipped-90\" | \"flipped-180\" | \"flipped-270\""
         },
         {
           "name": "scale",
@@ -985,6 +1337,84 @@ export const STRUX_RUNTIME_TYPES = {
         {
           "name": "transform",
           "goType": "*OutputTransform",
+          "tsType": "\"normal\" | \"90\" | \"180\" | \"270\" | \"flipped\" | \"flipped-90\" | \"flipped-180\" | \"flipped-270\""
+        }
+      ]
+    },
+    "FilesystemUsage": {
+      "fields": [
+        {
+          "name": "path",
+          "goType": "string",
+          "tsType": "string"
+        },
+        {
+          "name": "totalBytes",
+          "goType": "int64",
+          "tsType": "number"
+        },
+        {
+          "name": "usedBytes",
+          "goType": "int64",
+          "tsType": "number"
+        },
+        {
+          "name": "freeBytes",
+          "goType": "int64",
+          "tsType": "number"
+        }
+      ]
+    },
+    "HealthReport": {
+      "fields": [
+        {
+          "name": "backend",
+          "goType": "bool",
+          "tsType": "boolean"
+        },
+        {
+          "name": "clientReported",
+          "goType": "bool",
+          "tsType": "boolean"
+        },
+        {
+          "name": "stale",
+          "goType": "bool",
+          "tsType": "boolean"
+        },
+        {
+          "name": "mode",
+          "goType": "string",
+          "tsType": "string"
+        },
+        {
+          "name": "backendReady",
+          "goType": "bool",
+          "tsType": "boolean"
+        },
+        {
+          "name": "networkReady",
+          "goType": "bool",
+          "tsType": "boolean"
+        },
+        {
+          "name": "cog",
+          "goType": "CogHealth",
+          "tsType": "CogHealth"
+        },
+        {
+          "name": "devServer",
+          "goType": "*DevServerHealth",
+          "tsType": "DevServerHealth"
+        },
+        {
+          "name": "logStreams",
+          "goType": "[]string",
+          "tsType": "string[]"
+        },
+        {
+          "name": "updatedAt",
+          "goType": "string",
           "tsType": "string"
         }
       ]
@@ -1187,6 +1617,25 @@ export const STRUX_RUNTIME_TYPES = {
         }
       ]
     },
+    "OutputInfo": {
+      "fields": [
+        {
+          "name": "name",
+          "goType": "string",
+          "tsType": "string"
+        },
+        {
+          "name": "currentMode",
+          "goType": "string",
+          "tsType": "string"
+        },
+        {
+          "name": "modes",
+          "goType": "[]string",
+          "tsType": "string[]"
+        }
+      ]
+    },
     "ProjectInfo": {
       "fields": [
         {